@@ -0,0 +1,38 @@
+package cache
+
+import (
+	"fmt"
+
+	"github.com/NethServer/gh-ns8/cmd"
+	"github.com/NethServer/gh-ns8/internal/github"
+	"github.com/spf13/cobra"
+)
+
+// cacheCmd represents the cache command
+var cacheCmd = &cobra.Command{
+	Use:   "cache",
+	Short: "Manage the GitHub API response cache",
+	Long: `Responses of GitHub API reads are cached on disk and revalidated with
+conditional requests, so unchanged issues and pull requests do not consume
+the API rate limit.`,
+}
+
+// cacheClearCmd represents the cache clear command
+var cacheClearCmd = &cobra.Command{
+	Use:   "clear",
+	Short: "Remove all cached GitHub API responses",
+	Args:  cobra.NoArgs,
+	RunE: func(c *cobra.Command, args []string) error {
+		if err := github.ClearCache(); err != nil {
+			return err
+		}
+		fmt.Fprintf(c.OutOrStdout(), "Cache cleared: %s\n", github.CacheDir())
+		return nil
+	},
+}
+
+func init() {
+	cmd.AddCacheCommand(cacheCmd)
+
+	cacheCmd.AddCommand(cacheClearCmd)
+}
@@ -0,0 +1,65 @@
+package config
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/NethServer/gh-ns8/cmd"
+	internalconfig "github.com/NethServer/gh-ns8/internal/config"
+	"github.com/spf13/cobra"
+)
+
+var repoFlag string
+
+// configCmd represents the config command
+var configCmd = &cobra.Command{
+	Use:   "config",
+	Short: "Inspect the release configuration",
+	Long: `The release policy of a module is read from ` + internalconfig.FileName + ` in
+the repository, overridden by the user file, by the same file in the working
+directory and by command line flags.`,
+}
+
+// configShowCmd represents the config show command
+var configShowCmd = &cobra.Command{
+	Use:   "show",
+	Short: "Print the effective configuration and its sources",
+	Args:  cobra.NoArgs,
+	RunE: func(c *cobra.Command, args []string) error {
+		cfg, err := internalconfig.FromContext(c.Context())
+		if err != nil {
+			return err
+		}
+		return writeConfig(c.OutOrStdout(), cfg)
+	},
+}
+
+func init() {
+	cmd.AddConfigCommand(configCmd)
+
+	configShowCmd.Flags().StringVar(&repoFlag, "repo", "", "The GitHub repository to read the configuration from (default: the current repository)")
+	configCmd.AddCommand(configShowCmd)
+}
+
+// writeConfig prints the configuration sources, from the lowest to the
+// highest precedence, followed by the values annotated with their source
+func writeConfig(out io.Writer, cfg *internalconfig.Config) error {
+	values, err := cfg.Describe()
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintln(out, "# Sources, from the lowest to the highest precedence:")
+	for _, layer := range cfg.Layers() {
+		path, status := layer.Path, ""
+		if path == "" {
+			path = "-"
+		}
+		if !layer.Found {
+			status = " (not found)"
+		}
+		fmt.Fprintf(out, "#   %-10s %s%s\n", layer.Source, path, status)
+	}
+	fmt.Fprint(out, values)
+	return nil
+}
@@ -0,0 +1,35 @@
+package config
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	internalconfig "github.com/NethServer/gh-ns8/internal/config"
+)
+
+func TestWriteConfigListsSourcesAndValues(t *testing.T) {
+	repo, err := internalconfig.ParseLayer(internalconfig.SourceRepository, "NethServer/ns8-mail:.github/ns8-release.yml", []byte("branch: stable-1.4\n"))
+	if err != nil {
+		t.Fatalf("ParseLayer() returned error: %v", err)
+	}
+	cfg, err := internalconfig.Merge(repo, internalconfig.MissingLayer(internalconfig.SourceLocal, internalconfig.FileName))
+	if err != nil {
+		t.Fatalf("Merge() returned error: %v", err)
+	}
+
+	var out bytes.Buffer
+	if err := writeConfig(&out, cfg); err != nil {
+		t.Fatalf("writeConfig() returned error: %v", err)
+	}
+
+	for _, want := range []string{
+		"#   repository NethServer/ns8-mail:.github/ns8-release.yml\n",
+		"#   local      .github/ns8-release.yml (not found)\n",
+		"branch: stable-1.4 # repository\n",
+	} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("output = %q, want it to contain %q", out.String(), want)
+		}
+	}
+}
@@ -0,0 +1,195 @@
+package module_release
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/NethServer/gh-ns8/internal/config"
+	"github.com/NethServer/gh-ns8/internal/github"
+	"github.com/NethServer/gh-ns8/internal/module_release"
+	"github.com/spf13/cobra"
+)
+
+var (
+	changelogLimitFlag  int
+	changelogCommitFlag bool
+)
+
+// changelogCmd represents the changelog command
+var changelogCmd = &cobra.Command{
+	Use:   "changelog",
+	Short: "Update the CHANGELOG.md of the release branch",
+	Long: `Add the stable releases missing from the CHANGELOG.md of the release branch, in the Keep a Changelog format, with the issues and PRs of each release.
+The updated changelog is printed, or committed to the branch with --commit.`,
+	Args: cobra.NoArgs,
+	RunE: withErrorHints(writePermission, runChangelog),
+}
+
+func init() {
+	changelogCmd.Flags().IntVar(&changelogLimitFlag, "limit", 10, "Maximum number of missing releases to add, newest first")
+	changelogCmd.Flags().BoolVar(&changelogCommitFlag, "commit", false, "Commit the updated changelog to the branch")
+}
+
+type changelogFileClient interface {
+	GetFile(repo, path, ref string) (*github.File, error)
+	CommitFile(repo string, commit github.FileCommit) (string, error)
+}
+
+type changelogClient interface {
+	releaseNotesClient
+	changelogFileClient
+	ListReleases(repo string, limit int, excludePreReleases bool) ([]github.Release, error)
+	GetCompareStatus(repo, base, head string) (string, error)
+}
+
+func runChangelog(cmd *cobra.Command, args []string) error {
+	// Create GitHub client
+	client, err := newCommandClient()
+	if err != nil {
+		return err
+	}
+
+	// Get and validate repository
+	repo, err := module_release.GetOrValidateRepo(client, repoFlag)
+	if err != nil {
+		return err
+	}
+
+	cfg, err := config.FromContext(cmd.Context())
+	if err != nil {
+		return err
+	}
+
+	return updateChangelog(cmd.OutOrStdout(), cmd.ErrOrStderr(), client, cfg, repo, changelogLimitFlag, changelogCommitFlag)
+}
+
+func updateChangelog(out, errWriter io.Writer, client changelogClient, cfg *config.Config, repo string, limit int, commit bool) error {
+	changelog, blobSHA, err := readChangelog(client, repo, cfg.Branch)
+	if err != nil {
+		return err
+	}
+
+	// Releases of other maintenance branches belong to their own changelog
+	releases, err := module_release.ListBranchReleases(client, repo, cfg.Branch, 0, true)
+	if err != nil {
+		return err
+	}
+	added := addMissingChangelogReleases(errWriter, client, cfg, repo, changelog, releases, limit)
+
+	if !commit {
+		fmt.Fprint(out, changelog.String())
+		return nil
+	}
+
+	if len(added) == 0 {
+		fmt.Fprintf(out, "✅ %s is up to date\n", module_release.ChangelogFile)
+		return nil
+	}
+
+	sha, err := commitChangelog(client, repo, cfg.Branch, "", changelog, blobSHA, added)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(out, "✅ %s updated with %s in commit %s\n", module_release.ChangelogFile, strings.Join(added, ", "), sha)
+	return nil
+}
+
+// readChangelog reads the changelog at ref and its blob SHA, a new changelog
+// when the file is missing
+func readChangelog(client changelogFileClient, repo, ref string) (*module_release.Changelog, string, error) {
+	file, err := client.GetFile(repo, module_release.ChangelogFile, ref)
+	if errors.Is(err, github.ErrNotFound) {
+		return module_release.ParseChangelog(""), "", nil
+	}
+	if err != nil {
+		return nil, "", err
+	}
+	return module_release.ParseChangelog(string(file.Content)), file.SHA, nil
+}
+
+// addMissingChangelogReleases adds up to limit stable releases, listed
+// newest first, missing from the changelog and returns their versions
+func addMissingChangelogReleases(errWriter io.Writer, client releaseNotesClient, cfg *config.Config, repo string, changelog *module_release.Changelog, releases []github.Release, limit int) []string {
+	var added []string
+	for i, release := range releases {
+		if changelog.Has(release.TagName) {
+			continue
+		}
+		if len(added) == limit {
+			break
+		}
+
+		previous := ""
+		if i+1 < len(releases) {
+			previous = releases[i+1].TagName
+		}
+		changelog.Add(repo, changelogRelease(errWriter, client, cfg, repo, release.TagName, previous, release.TagName, releaseDate(&release)))
+		added = append(added, release.TagName)
+	}
+	return added
+}
+
+// changelogRelease returns the changelog section of the previous...head
+// range. A range that cannot be scanned, e.g. without PRs, gets a section
+// without entries and a warning.
+func changelogRelease(errWriter io.Writer, client releaseNotesClient, cfg *config.Config, repo, version, previous, head, date string) module_release.ChangelogRelease {
+	if previous == "" {
+		return module_release.NewChangelogRelease(version, previous, date, nil)
+	}
+
+	prNumbers, err := module_release.ScanForPRs(client, repo, previous, head)
+	if err != nil {
+		fmt.Fprintf(errWriter, "⚠️  No changelog entries for %s: %v\n", version, err)
+		return module_release.NewChangelogRelease(version, previous, date, nil)
+	}
+
+	classifier, err := module_release.NewPRClassifier(cfg.Categories)
+	if err != nil {
+		fmt.Fprintf(errWriter, "⚠️  No changelog entries for %s: %v\n", version, err)
+		return module_release.NewChangelogRelease(version, previous, date, nil)
+	}
+
+	notes := module_release.BuildReleaseNotes(client, classifier, repo, cfg.IssuesRepo, prNumbers)
+	return module_release.NewChangelogRelease(version, previous, date, notes)
+}
+
+// commitChangelog commits the changelog to the branch and returns the SHA of
+// the new commit. With a parent the commit is made on it, and fails if the
+// branch moved past it.
+func commitChangelog(client changelogFileClient, repo, branch, parent string, changelog *module_release.Changelog, blobSHA string, versions []string) (string, error) {
+	return client.CommitFile(repo, github.FileCommit{
+		Path:    module_release.ChangelogFile,
+		Branch:  branch,
+		Message: fmt.Sprintf("Update %s for %s", module_release.ChangelogFile, strings.Join(versions, ", ")),
+		Content: []byte(changelog.String()),
+		SHA:     blobSHA,
+		Parent:  parent,
+	})
+}
+
+// updateReleaseChangelog adds a new release to the changelog of the branch,
+// head being the latest commit, and returns the commit to tag: the commit of
+// the changelog, or head when the release is already in the changelog, and
+// whether the changelog was committed. The changelog is committed on head,
+// so that the release never includes commits pushed after head was scanned.
+func updateReleaseChangelog(errWriter io.Writer, client changelogClient, cfg *config.Config, repo, version, previous, head, date string) (string, bool, error) {
+	changelog, blobSHA, err := readChangelog(client, repo, head)
+	if err != nil {
+		return "", false, err
+	}
+	if changelog.Has(version) {
+		return head, false, nil
+	}
+
+	changelog.Add(repo, changelogRelease(errWriter, client, cfg, repo, version, previous, head, date))
+	sha, err := commitChangelog(client, repo, cfg.Branch, head, changelog, blobSHA, []string{version})
+	if errors.Is(err, github.ErrConflict) {
+		return "", false, fmt.Errorf("%w: branch %s moved since %s was validated, create the release again", err, cfg.Branch, head)
+	}
+	if err != nil {
+		return "", false, err
+	}
+	return sha, true, nil
+}
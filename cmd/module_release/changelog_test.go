@@ -0,0 +1,206 @@
+package module_release
+
+import (
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+
+	internalconfig "github.com/NethServer/gh-ns8/internal/config"
+	ghgithub "github.com/NethServer/gh-ns8/internal/github"
+	internalmodule "github.com/NethServer/gh-ns8/internal/module_release"
+)
+
+type fakeChangelogClient struct {
+	fakeLinkedIssuesNotesClient
+	releases []ghgithub.Release
+	// branchTags are the releases on the branch, all of them when nil
+	branchTags []string
+	file       *ghgithub.File
+	fileErr    error
+	commits    *[]ghgithub.FileCommit
+	commitErr  error
+}
+
+func (f fakeChangelogClient) ListReleases(_ string, _ int, _ bool) ([]ghgithub.Release, error) {
+	return f.releases, nil
+}
+
+func (f fakeChangelogClient) GetCompareStatus(_, _, head string) (string, error) {
+	if f.branchTags == nil || slices.Contains(f.branchTags, head) {
+		return "behind", nil
+	}
+	return "diverged", nil
+}
+
+func (f fakeChangelogClient) GetFile(_, _, _ string) (*ghgithub.File, error) {
+	if f.fileErr != nil {
+		return nil, f.fileErr
+	}
+	return f.file, nil
+}
+
+func (f fakeChangelogClient) CommitFile(_ string, commit ghgithub.FileCommit) (string, error) {
+	if f.commitErr != nil {
+		return "", f.commitErr
+	}
+	*f.commits = append(*f.commits, commit)
+	return "changelog-sha", nil
+}
+
+func newChangelogTestClient(file *ghgithub.File) fakeChangelogClient {
+	client := newRangeNotesTestClient()
+	client.comparisons["1.2.3...1.3.0"] = makeCommandCompareResult("commit-a", "commit-b")
+	client.comparisons["1.3.0...commit-c"] = makeCommandCompareResult("commit-c")
+	fileErr := error(nil)
+	if file == nil {
+		fileErr = ghgithub.ErrNotFound
+	}
+	return fakeChangelogClient{
+		fakeLinkedIssuesNotesClient: client,
+		releases: []ghgithub.Release{
+			{TagName: "1.3.0", CreatedAt: "2026-10-01T10:00:00Z"},
+			{TagName: "1.2.3", CreatedAt: "2026-09-01T10:00:00Z"},
+		},
+		file:    file,
+		fileErr: fileErr,
+		commits: &[]ghgithub.FileCommit{},
+	}
+}
+
+func TestUpdateChangelogPrintsNewChangelog(t *testing.T) {
+	var out, errOut strings.Builder
+	if err := updateChangelog(&out, &errOut, newChangelogTestClient(nil), internalconfig.Default(), "NethServer/ns8-mail", 10, false); err != nil {
+		t.Fatalf("updateChangelog() returned error: %v", err)
+	}
+
+	for _, want := range []string{
+		"# Changelog\n",
+		"## [1.3.0] - 2026-10-01\n\n### Changed\n\n- Phone support ([NethServer/dev#11](https://github.com/NethServer/dev/issues/11))\n\n### Fixed\n\n- Fix login loop ([NethServer/dev#10](https://github.com/NethServer/dev/issues/10))\n",
+		"## [1.2.3] - 2026-09-01\n",
+		"[1.3.0]: https://github.com/NethServer/ns8-mail/compare/1.2.3...1.3.0\n[1.2.3]: https://github.com/NethServer/ns8-mail/releases/tag/1.2.3\n",
+	} {
+		if !strings.Contains(out.String(), want) {
+			t.Fatalf("updateChangelog() output =\n%s\nwant it to contain %q", out.String(), want)
+		}
+	}
+}
+
+func TestUpdateChangelogCommitsMissingReleases(t *testing.T) {
+	existing := "# Changelog\n\n## [1.2.3] - 2026-09-01\n\n[1.2.3]: https://github.com/NethServer/ns8-mail/releases/tag/1.2.3\n"
+	client := newChangelogTestClient(&ghgithub.File{SHA: "blob1", Content: []byte(existing)})
+
+	var out, errOut strings.Builder
+	if err := updateChangelog(&out, &errOut, client, internalconfig.Default(), "NethServer/ns8-mail", 10, true); err != nil {
+		t.Fatalf("updateChangelog() returned error: %v", err)
+	}
+
+	if len(*client.commits) != 1 {
+		t.Fatalf("commits = %v, want one", *client.commits)
+	}
+	commit := (*client.commits)[0]
+	if commit.SHA != "blob1" || commit.Branch != "main" || commit.Message != "Update CHANGELOG.md for 1.3.0" {
+		t.Fatalf("commit = %+v, want the 1.3.0 update of blob1 on main", commit)
+	}
+	if !strings.HasPrefix(string(commit.Content), "# Changelog\n\n## [1.3.0] - 2026-10-01\n") {
+		t.Fatalf("commit content =\n%s\nwant 1.3.0 before 1.2.3", commit.Content)
+	}
+	if out.String() != "✅ CHANGELOG.md updated with 1.3.0 in commit changelog-sha\n" {
+		t.Fatalf("updateChangelog() output = %q", out.String())
+	}
+}
+
+func TestUpdateChangelogSkipsUpToDateChangelog(t *testing.T) {
+	existing := "# Changelog\n\n## [1.3.0] - 2026-10-01\n\n## [1.2.3] - 2026-09-01\n"
+	client := newChangelogTestClient(&ghgithub.File{SHA: "blob1", Content: []byte(existing)})
+
+	var out, errOut strings.Builder
+	if err := updateChangelog(&out, &errOut, client, internalconfig.Default(), "NethServer/ns8-mail", 10, true); err != nil {
+		t.Fatalf("updateChangelog() returned error: %v", err)
+	}
+	if len(*client.commits) != 0 || out.String() != "✅ CHANGELOG.md is up to date\n" {
+		t.Fatalf("updateChangelog() = %q, commits %v, want nothing to commit", out.String(), *client.commits)
+	}
+}
+
+func TestUpdateChangelogSkipsReleasesOfOtherBranches(t *testing.T) {
+	client := newChangelogTestClient(nil)
+	client.releases = append([]ghgithub.Release{{TagName: "1.4.0", CreatedAt: "2026-10-10T10:00:00Z"}}, client.releases...)
+	client.branchTags = []string{"1.3.0", "1.2.3"}
+	cfg := internalconfig.Default()
+	cfg.Branch = "stable-1.3"
+
+	var out, errOut strings.Builder
+	if err := updateChangelog(&out, &errOut, client, cfg, "NethServer/ns8-mail", 10, false); err != nil {
+		t.Fatalf("updateChangelog() returned error: %v", err)
+	}
+	if strings.Contains(out.String(), "1.4.0") || !strings.Contains(out.String(), "compare/1.2.3...1.3.0") {
+		t.Fatalf("updateChangelog() output =\n%s\nwant only the releases of stable-1.3", out.String())
+	}
+}
+
+func TestUpdateChangelogReturnsReadError(t *testing.T) {
+	client := newChangelogTestClient(nil)
+	client.fileErr = ghgithub.ErrForbidden
+
+	var out, errOut strings.Builder
+	err := updateChangelog(&out, &errOut, client, internalconfig.Default(), "NethServer/ns8-mail", 10, false)
+	if !errors.Is(err, ghgithub.ErrForbidden) {
+		t.Fatalf("updateChangelog() error = %v, want ErrForbidden", err)
+	}
+}
+
+func TestAddMissingChangelogReleasesHonorsLimit(t *testing.T) {
+	client := newChangelogTestClient(nil)
+	changelog := internalmodule.ParseChangelog("")
+
+	var errOut strings.Builder
+	added := addMissingChangelogReleases(&errOut, client, internalconfig.Default(), "NethServer/ns8-mail", changelog, client.releases, 1)
+	if len(added) != 1 || added[0] != "1.3.0" || changelog.Has("1.2.3") {
+		t.Fatalf("addMissingChangelogReleases() = %v, want only the newest release", added)
+	}
+}
+
+func TestUpdateReleaseChangelogCommitsNewRelease(t *testing.T) {
+	client := newChangelogTestClient(nil)
+	client.prs[3] = makeTestPullRequest(3, "", "carol", "closed", true)
+	client.prs[3].Title = "Fix typo"
+	client.commitPRs["commit-c"] = []int{3}
+
+	var errOut strings.Builder
+	sha, committed, err := updateReleaseChangelog(&errOut, client, internalconfig.Default(), "NethServer/ns8-mail", "1.3.1", "1.3.0", "commit-c", "2026-10-18")
+	if err != nil {
+		t.Fatalf("updateReleaseChangelog() returned error: %v", err)
+	}
+	if sha != "changelog-sha" || !committed {
+		t.Fatalf("updateReleaseChangelog() = %q, %v, want the changelog commit", sha, committed)
+	}
+	if parent := (*client.commits)[0].Parent; parent != "commit-c" {
+		t.Fatalf("commit parent = %q, want the scanned head commit-c", parent)
+	}
+	content := string((*client.commits)[0].Content)
+	if !strings.Contains(content, "## [1.3.1] - 2026-10-18\n\n### Fixed\n\n- Fix typo ([#3](https://github.com/NethServer/ns8-mail/pull/3))\n") {
+		t.Fatalf("changelog =\n%s\nwant the 1.3.1 section", content)
+	}
+}
+
+func TestUpdateReleaseChangelogKeepsExistingRelease(t *testing.T) {
+	client := newChangelogTestClient(&ghgithub.File{SHA: "blob1", Content: []byte("# Changelog\n\n## [1.3.1] - 2026-10-18\n")})
+
+	var errOut strings.Builder
+	sha, committed, err := updateReleaseChangelog(&errOut, client, internalconfig.Default(), "NethServer/ns8-mail", "1.3.1", "1.3.0", "commit-c", "2026-10-18")
+	if err != nil || sha != "commit-c" || committed || len(*client.commits) != 0 {
+		t.Fatalf("updateReleaseChangelog() = %q, %v, %v, want the head without a commit", sha, committed, err)
+	}
+}
+
+func TestUpdateReleaseChangelogFailsWhenBranchMoved(t *testing.T) {
+	client := newChangelogTestClient(nil)
+	client.commitErr = ghgithub.ErrConflict
+
+	var errOut strings.Builder
+	_, _, err := updateReleaseChangelog(&errOut, client, internalconfig.Default(), "NethServer/ns8-mail", "1.3.1", "1.3.0", "commit-c", "2026-10-18")
+	if !errors.Is(err, ghgithub.ErrConflict) || !strings.Contains(err.Error(), "branch main moved since commit-c") {
+		t.Fatalf("updateReleaseChangelog() error = %v, want the moved branch conflict", err)
+	}
+}
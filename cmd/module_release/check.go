@@ -4,8 +4,8 @@ import (
 	"fmt"
 	"io"
 	"sort"
-	"strings"
 
+	"github.com/NethServer/gh-ns8/internal/config"
 	"github.com/NethServer/gh-ns8/internal/github"
 	"github.com/NethServer/gh-ns8/internal/module_release"
 	"github.com/spf13/cobra"
@@ -14,9 +14,9 @@ import (
 // checkCmd represents the check command
 var checkCmd = &cobra.Command{
 	Use:   "check",
-	Short: "Check the status of the main branch",
-	Long:  `Check for PRs and issues since the latest release and verify readiness for a new release.`,
-	RunE:  runCheck,
+	Short: "Check the status of the release branch",
+	Long:  `Check for PRs and issues since the latest release of the configured branch (main unless --branch is set) and verify readiness for a new release.`,
+	RunE:  withErrorHints(readPermission, runCheck),
 }
 
 type checkSummaryClient interface {
@@ -24,14 +24,15 @@ type checkSummaryClient interface {
 	GetPullRequest(repo string, number int) (*github.PullRequest, error)
 	GetIssue(repo string, number int) (*github.Issue, error)
 	GetParentIssueNumber(repo string, issueNumber int) (int, error)
+	GetPullRequestReviewDecision(repo string, number int) (string, error)
 	ListOpenPullRequests(repo string) ([]github.OpenPullRequest, error)
 }
 
 func runCheck(cmd *cobra.Command, args []string) error {
 	// Create GitHub client
-	client, err := github.NewClient()
+	client, err := newCommandClient()
 	if err != nil {
-		return fmt.Errorf("failed to create GitHub client: %w", err)
+		return err
 	}
 
 	// Get and validate repository
@@ -40,10 +41,16 @@ func runCheck(cmd *cobra.Command, args []string) error {
 		return err
 	}
 
+	cfg, err := config.FromContext(cmd.Context())
+	if err != nil {
+		return err
+	}
+	branch := cfg.Branch
+
 	// Get latest stable release
-	latestRelease, err := module_release.GetLatestRelease(client, repo, true)
+	latestRelease, err := module_release.GetLatestBranchRelease(client, repo, branch, true)
 	if err != nil {
-		return fmt.Errorf("no releases found")
+		return err
 	}
 
 	fmt.Printf("Checking PRs and issues since %s...\n\n", latestRelease.TagName)
@@ -54,17 +61,29 @@ func runCheck(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("failed to get release commit SHA: %w", err)
 	}
 
-	mainSHA, err := module_release.GetMainBranchSHA(client, repo)
+	branchSHA, err := module_release.GetBranchSHA(client, repo, branch)
+	if err != nil {
+		return err
+	}
+
+	classifier, err := module_release.NewPRClassifier(cfg.Categories)
 	if err != nil {
-		return fmt.Errorf("failed to get main branch SHA: %w", err)
+		return err
 	}
 
-	summary := module_release.NewCheckSummary(issuesRepoFlag)
+	summary := module_release.NewCheckSummary(cfg.IssuesRepo)
+	summary.Categories = classifier.Categories()
+	summary.TestingLabel = cfg.Labels.Testing
+	summary.VerifiedLabel = cfg.Labels.Verified
+	summary.Policy = module_release.NewReadinessPolicy(cfg.Readiness)
+	if cfg.Progress == config.ProgressSourceProject {
+		summary.ProgressSource = module_release.NewProjectProgress(client, cfg.Project)
+	}
 
-	if latestSHA == mainSHA {
-		fmt.Println("The latest release tag is the HEAD of the main branch, there is nothing ready to release")
-		populateOpenPullRequests(cmd.ErrOrStderr(), client, summary, repo, map[int]bool{})
-		if len(summary.Issues) > 0 || len(summary.OpenWeblatePRs) > 0 {
+	if latestSHA == branchSHA {
+		fmt.Printf("The latest release tag is the HEAD of the %s branch, there is nothing ready to release\n", branch)
+		populateOpenPullRequests(cmd.ErrOrStderr(), client, summary, classifier, repo, map[int]bool{})
+		if len(summary.Issues) > 0 || summary.HasOpenWarnings() {
 			fmt.Println()
 			summary.Display()
 		}
@@ -72,7 +91,7 @@ func runCheck(cmd *cobra.Command, args []string) error {
 	}
 
 	// Get all commits in range
-	comparison, err := client.CompareCommits(repo, latestRelease.TagName, "main")
+	comparison, err := client.CompareCommits(repo, latestRelease.TagName, branch)
 	if err != nil {
 		return fmt.Errorf("failed to compare commits: %w", err)
 	}
@@ -83,13 +102,13 @@ func runCheck(cmd *cobra.Command, args []string) error {
 	}
 
 	// Scan for PRs
-	prNumbers, err := module_release.ScanForPRs(client, repo, latestRelease.TagName, "main")
+	prNumbers, err := module_release.ScanForPRs(client, repo, latestRelease.TagName, branch)
 	if err != nil {
 		return fmt.Errorf("error processing PRs: %w", err)
 	}
 
-	seenPRs := populateCheckSummary(cmd.ErrOrStderr(), client, summary, repo, comparison, prNumbers)
-	populateOpenPullRequests(cmd.ErrOrStderr(), client, summary, repo, seenPRs)
+	seenPRs := populateCheckSummary(cmd.ErrOrStderr(), client, summary, classifier, repo, comparison, prNumbers)
+	populateOpenPullRequests(cmd.ErrOrStderr(), client, summary, classifier, repo, seenPRs)
 
 	// Display summary
 	summary.Display()
@@ -97,7 +116,7 @@ func runCheck(cmd *cobra.Command, args []string) error {
 	return nil
 }
 
-func populateCheckSummary(errWriter io.Writer, client checkSummaryClient, summary *module_release.CheckSummary, repo string, comparison *github.CompareResult, prNumbers []int) map[int]bool {
+func populateCheckSummary(errWriter io.Writer, client checkSummaryClient, summary *module_release.CheckSummary, classifier *module_release.PRClassifier, repo string, comparison *github.CompareResult, prNumbers []int) map[int]bool {
 	commitsInPRs := make(map[string]bool)
 	for _, commit := range comparison.Commits {
 		prs, err := client.GetPullRequestsForCommit(repo, commit.SHA)
@@ -110,16 +129,17 @@ func populateCheckSummary(errWriter io.Writer, client checkSummaryClient, summar
 	for _, prNum := range prNumbers {
 		pr, err := client.GetPullRequest(repo, prNum)
 		if err != nil {
+			fmt.Fprintf(errWriter, "Warning: failed to get PR %d, it is missing from the summary: %v\n", prNum, err)
 			continue
 		}
 		seenPRs[prNum] = true
 
-		processPullRequest(errWriter, client, summary, repo, pr)
+		processPullRequest(errWriter, client, summary, classifier, repo, pr)
 	}
 
 	for _, commit := range comparison.Commits {
 		if !commitsInPRs[commit.SHA] {
-			commitURL := fmt.Sprintf("https://github.com/%s/commit/%s", repo, commit.SHA)
+			commitURL := github.WebURL(fmt.Sprintf("%s/commit/%s", repo, commit.SHA))
 			summary.OrphanCommits = append(summary.OrphanCommits, commitURL)
 		}
 	}
@@ -127,7 +147,7 @@ func populateCheckSummary(errWriter io.Writer, client checkSummaryClient, summar
 	return seenPRs
 }
 
-func populateOpenPullRequests(errWriter io.Writer, client checkSummaryClient, summary *module_release.CheckSummary, repo string, seenPRs map[int]bool) {
+func populateOpenPullRequests(errWriter io.Writer, client checkSummaryClient, summary *module_release.CheckSummary, classifier *module_release.PRClassifier, repo string, seenPRs map[int]bool) {
 	openPRs, err := client.ListOpenPullRequests(repo)
 	if err != nil {
 		fmt.Fprintf(errWriter, "Warning: failed to check open PRs: %v\n", err)
@@ -139,9 +159,7 @@ func populateOpenPullRequests(errWriter io.Writer, client checkSummaryClient, su
 	})
 
 	for _, openPR := range openPRs {
-		if openPR.Author.Login == "weblate" {
-			summary.OpenWeblatePRs = append(summary.OpenWeblatePRs, openPullRequestURL(repo, openPR))
-		}
+		summary.AddOpenWarning(classifier.CategorizeOpen(openPR), openPullRequestURL(repo, openPR))
 		if seenPRs[openPR.Number] {
 			continue
 		}
@@ -155,7 +173,7 @@ func populateOpenPullRequests(errWriter io.Writer, client checkSummaryClient, su
 			continue
 		}
 
-		processPullRequest(errWriter, client, summary, repo, pr)
+		processPullRequest(errWriter, client, summary, classifier, repo, pr)
 		seenPRs[openPR.Number] = true
 	}
 }
@@ -164,11 +182,11 @@ func openPullRequestURL(repo string, pr github.OpenPullRequest) string {
 	if pr.URL != "" {
 		return pr.URL
 	}
-	return fmt.Sprintf("https://github.com/%s/pull/%d", repo, pr.Number)
+	return github.WebURL(fmt.Sprintf("%s/pull/%d", repo, pr.Number))
 }
 
-func processPullRequest(errWriter io.Writer, client checkSummaryClient, summary *module_release.CheckSummary, repo string, pr *github.PullRequest) {
-	category := categorizePullRequest(pr)
+func processPullRequest(errWriter io.Writer, client checkSummaryClient, summary *module_release.CheckSummary, classifier *module_release.PRClassifier, repo string, pr *github.PullRequest) {
+	category := classifier.Categorize(pr)
 
 	linkedIssues := module_release.GetLinkedIssues(pr.Body, summary.IssuesRepo)
 	if len(linkedIssues) == 0 {
@@ -176,6 +194,14 @@ func processPullRequest(errWriter io.Writer, client checkSummaryClient, summary
 		return
 	}
 
+	if summary.Policy.NeedsReviews() {
+		decision, err := client.GetPullRequestReviewDecision(repo, pr.Number)
+		if err != nil {
+			fmt.Fprintf(errWriter, "Warning: failed to get the review decision of PR %d: %v\n", pr.Number, err)
+		}
+		pr.ReviewDecision = decision
+	}
+
 	for _, issueNum := range linkedIssues {
 		if err := summary.ProcessIssue(client, issueNum); err != nil {
 			fmt.Fprintf(errWriter, "Warning: failed to process issue %d: %v\n", issueNum, err)
@@ -184,16 +210,3 @@ func processPullRequest(errWriter io.Writer, client checkSummaryClient, summary
 		summary.AddIssuePullRequest(repo, issueNum, pr, category)
 	}
 }
-
-func categorizePullRequest(pr *github.PullRequest) module_release.PRCategory {
-	switch {
-	case pr.User.Login == "weblate":
-		return module_release.PRCategoryTranslation
-	case pr.User.Login == "renovate[bot]" && pr.Merged:
-		return module_release.PRCategoryRenovate
-	case strings.EqualFold(pr.State, "open"):
-		return module_release.PRCategoryGeneric
-	default:
-		return module_release.PRCategoryMerged
-	}
-}
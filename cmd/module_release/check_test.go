@@ -6,6 +6,7 @@ import (
 	"strconv"
 	"testing"
 
+	internalconfig "github.com/NethServer/gh-ns8/internal/config"
 	ghgithub "github.com/NethServer/gh-ns8/internal/github"
 	internalmodule "github.com/NethServer/gh-ns8/internal/module_release"
 )
@@ -21,6 +22,8 @@ type fakeCheckSummaryClient struct {
 	parentErrs   map[int]error
 	openPRs      []ghgithub.OpenPullRequest
 	openPRsErr   error
+	reviews      map[int]string
+	reviewCalls  *int
 }
 
 func (f fakeCheckSummaryClient) GetPullRequestsForCommit(_ string, sha string) ([]int, error) {
@@ -51,6 +54,13 @@ func (f fakeCheckSummaryClient) GetParentIssueNumber(_ string, issueNumber int)
 	return f.parentIssues[issueNumber], nil
 }
 
+func (f fakeCheckSummaryClient) GetPullRequestReviewDecision(_ string, number int) (string, error) {
+	if f.reviewCalls != nil {
+		*f.reviewCalls++
+	}
+	return f.reviews[number], nil
+}
+
 func (f fakeCheckSummaryClient) ListOpenPullRequests(_ string) ([]ghgithub.OpenPullRequest, error) {
 	if f.openPRsErr != nil {
 		return nil, f.openPRsErr
@@ -101,19 +111,22 @@ func TestPopulateCheckSummaryCategorizesPRsAndOrphans(t *testing.T) {
 	}
 
 	summary := internalmodule.NewCheckSummary("NethServer/dev")
-	seenPRs := populateCheckSummary(&errBuf, client, summary, "NethServer/ns8-mail", makeCommandCompareResult("commit-a", "commit-b", "commit-c"), []int{1, 2, 3, 4, 5})
+	seenPRs := populateCheckSummary(&errBuf, client, summary, newTestClassifier(t), "NethServer/ns8-mail", makeCommandCompareResult("commit-a", "commit-b", "commit-c"), []int{1, 2, 3, 4, 5})
 
 	if len(seenPRs) != 4 || !seenPRs[1] || !seenPRs[2] || !seenPRs[3] || !seenPRs[5] {
 		t.Fatalf("seenPRs = %v, want successfully loaded PRs", seenPRs)
 	}
-	if len(summary.TranslationPRs) != 1 || summary.TranslationPRs[0].URL != "https://github.com/NethServer/ns8-mail/pull/2" {
-		t.Fatalf("TranslationPRs = %v, want weblate PR URL", summary.TranslationPRs)
+	translationPRs := summary.PullRequests[internalmodule.PRCategoryTranslation]
+	if len(translationPRs) != 1 || translationPRs[0].URL != "https://github.com/NethServer/ns8-mail/pull/2" {
+		t.Fatalf("translation PRs = %v, want weblate PR URL", translationPRs)
 	}
-	if len(summary.RenovatePRs) != 1 || summary.RenovatePRs[0].URL != "https://github.com/NethServer/ns8-mail/pull/5" {
-		t.Fatalf("RenovatePRs = %v, want renovate PR URL", summary.RenovatePRs)
+	renovatePRs := summary.PullRequests[internalmodule.PRCategoryRenovate]
+	if len(renovatePRs) != 1 || renovatePRs[0].URL != "https://github.com/NethServer/ns8-mail/pull/5" {
+		t.Fatalf("renovate PRs = %v, want renovate PR URL", renovatePRs)
 	}
-	if len(summary.MergedPRs) != 1 || summary.MergedPRs[0].Number != 3 {
-		t.Fatalf("MergedPRs = %v, want only unlinked merged PR 3", summary.MergedPRs)
+	mergedPRs := summary.PullRequests[internalmodule.PRCategoryMerged]
+	if len(mergedPRs) != 1 || mergedPRs[0].Number != 3 {
+		t.Fatalf("merged PRs = %v, want only unlinked merged PR 3", mergedPRs)
 	}
 	if len(summary.OrphanCommits) != 1 || summary.OrphanCommits[0] != "https://github.com/NethServer/ns8-mail/commit/commit-b" {
 		t.Fatalf("OrphanCommits = %v, want orphan commit URL", summary.OrphanCommits)
@@ -144,7 +157,8 @@ func TestPopulateCheckSummaryCategorizesPRsAndOrphans(t *testing.T) {
 		t.Fatalf("summary.Issues[100].Progress = %q, want %q", parent.Progress, internalmodule.EmojiVerified)
 	}
 
-	wantWarning := "Warning: failed to process issue 20: failed to get issue 20: missing issue\n"
+	wantWarning := "Warning: failed to process issue 20: failed to get issue 20: missing issue\n" +
+		"Warning: failed to get PR 4, it is missing from the summary: missing PR\n"
 	if errBuf.String() != wantWarning {
 		t.Fatalf("warnings = %q, want %q", errBuf.String(), wantWarning)
 	}
@@ -190,22 +204,23 @@ func TestPopulateOpenPullRequestsAddsRelevantOpenPRs(t *testing.T) {
 	client.prs[9].MergeableState = "clean"
 
 	summary := internalmodule.NewCheckSummary("NethServer/dev")
-	populateOpenPullRequests(&errBuf, client, summary, "NethServer/ns8-mail", map[int]bool{10: true})
+	populateOpenPullRequests(&errBuf, client, summary, newTestClassifier(t), "NethServer/ns8-mail", map[int]bool{10: true})
 
 	if errBuf.Len() != 0 {
 		t.Fatalf("warnings = %q, want none", errBuf.String())
 	}
-	if len(summary.OpenWeblatePRs) != 1 || summary.OpenWeblatePRs[0] != "https://github.com/NethServer/ns8-mail/pull/6" {
-		t.Fatalf("OpenWeblatePRs = %v, want Weblate warning URL", summary.OpenWeblatePRs)
+	warnings := summary.OpenWarnings[internalmodule.PRCategoryTranslation]
+	if len(warnings) != 1 || warnings[0] != "https://github.com/NethServer/ns8-mail/pull/6" {
+		t.Fatalf("translation warnings = %v, want Weblate warning URL", warnings)
 	}
-	if len(summary.TranslationPRs) != 0 {
-		t.Fatalf("TranslationPRs = %v, want unlinked Weblate PR only in warning", summary.TranslationPRs)
+	if prs := summary.PullRequests[internalmodule.PRCategoryTranslation]; len(prs) != 0 {
+		t.Fatalf("translation PRs = %v, want unlinked Weblate PR only in warning", prs)
 	}
-	if len(summary.RenovatePRs) != 0 {
-		t.Fatalf("RenovatePRs = %v, want no open renovate PRs in renovate bucket", summary.RenovatePRs)
+	if prs := summary.PullRequests[internalmodule.PRCategoryRenovate]; len(prs) != 0 {
+		t.Fatalf("renovate PRs = %v, want no open renovate PRs in renovate bucket", prs)
 	}
-	if len(summary.MergedPRs) != 0 {
-		t.Fatalf("MergedPRs = %v, want no unlinked open PRs", summary.MergedPRs)
+	if prs := summary.PullRequests[internalmodule.PRCategoryMerged]; len(prs) != 0 {
+		t.Fatalf("merged PRs = %v, want no unlinked open PRs", prs)
 	}
 	if summary.Issues[30] == nil || summary.Issues[30].Progress != internalmodule.EmojiVerified {
 		t.Fatalf("Issues[30] = %v, want processed linked issue", summary.Issues[30])
@@ -224,6 +239,37 @@ func TestPopulateOpenPullRequestsAddsRelevantOpenPRs(t *testing.T) {
 	}
 }
 
+func TestProcessPullRequestFetchesReviewsForThePolicy(t *testing.T) {
+	var errBuf bytes.Buffer
+	calls := 0
+	client := fakeCheckSummaryClient{
+		issues: map[int]*ghgithub.Issue{
+			10: {State: "CLOSED"},
+		},
+		reviews:     map[int]string{1: ghgithub.ReviewApproved},
+		reviewCalls: &calls,
+	}
+
+	summary := internalmodule.NewCheckSummary("NethServer/dev")
+	processPullRequest(&errBuf, client, summary, newTestClassifier(t), "NethServer/ns8-mail", makeTestPullRequest(1, "Refs NethServer/dev#10", "", "closed", true))
+	if calls != 0 {
+		t.Fatalf("review decision requested %d times, want none with the default policy", calls)
+	}
+
+	summary = internalmodule.NewCheckSummary("NethServer/dev")
+	summary.Policy = internalmodule.NewReadinessPolicy(internalconfig.Readiness{
+		Accept:  []internalconfig.ReadinessRule{{State: internalconfig.StateClosed, Review: internalconfig.ReviewApproved}},
+		Parents: internalconfig.ParentsChildren,
+	})
+	processPullRequest(&errBuf, client, summary, newTestClassifier(t), "NethServer/ns8-mail", makeTestPullRequest(1, "Refs NethServer/dev#10", "", "closed", true))
+	if calls != 1 {
+		t.Fatalf("review decision requested %d times, want once", calls)
+	}
+	if prs := summary.Issues[10].LinkedPRs; len(prs) != 1 || prs[0].ReviewDecision != ghgithub.ReviewApproved {
+		t.Fatalf("Issues[10].LinkedPRs = %v, want the approved PR 1", prs)
+	}
+}
+
 func TestCategorizePullRequestPrecedence(t *testing.T) {
 	tests := []struct {
 		name string
@@ -259,13 +305,22 @@ func TestCategorizePullRequestPrecedence(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			if got := categorizePullRequest(tt.pr); got != tt.want {
-				t.Fatalf("categorizePullRequest() = %v, want %v", got, tt.want)
+			if got := newTestClassifier(t).Categorize(tt.pr); got != tt.want {
+				t.Fatalf("Categorize() = %v, want %v", got, tt.want)
 			}
 		})
 	}
 }
 
+func newTestClassifier(t *testing.T) *internalmodule.PRClassifier {
+	t.Helper()
+	classifier, err := internalmodule.NewPRClassifier(internalconfig.Default().Categories)
+	if err != nil {
+		t.Fatalf("NewPRClassifier() returned error: %v", err)
+	}
+	return classifier
+}
+
 func makeTestPullRequest(number int, body, author, state string, merged bool, labels ...string) *ghgithub.PullRequest {
 	pr := &ghgithub.PullRequest{
 		Number:  number,
@@ -1,36 +1,58 @@
 package module_release
 
 import (
+	"errors"
 	"fmt"
 	"io"
+	"time"
 
+	"github.com/NethServer/gh-ns8/internal/config"
 	"github.com/NethServer/gh-ns8/internal/github"
 	"github.com/NethServer/gh-ns8/internal/module_release"
 	"github.com/spf13/cobra"
 )
 
+var cleanDraftsFlag bool
+
 // cleanCmd represents the clean command
 var cleanCmd = &cobra.Command{
 	Use:   "clean [VERSION]",
 	Short: "Remove pre-releases between stable releases",
-	Long:  `Delete all pre-release versions between two stable releases.`,
-	Args:  cobra.MaximumNArgs(1),
-	RunE:  runClean,
+	Long: `Delete all pre-release versions between two stable releases.
+With --drafts the stale draft releases are deleted instead: the drafts created before the latest release of the branch.`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: withErrorHints(writePermission, runClean),
+}
+
+func init() {
+	cleanCmd.Flags().BoolVar(&cleanDraftsFlag, "drafts", false, "Delete the draft releases created before the latest release of the branch")
 }
 
 type cleanReleaseLookupClient interface {
 	ListReleases(repo string, limit int, excludePreReleases bool) ([]github.Release, error)
+	GetCompareStatus(repo, base, head string) (string, error)
+}
+
+type cleanRangeClient interface {
+	ListReleases(repo string, limit int, excludePreReleases bool) ([]github.Release, error)
+	ViewRelease(repo, tag string) (*github.Release, error)
 }
 
 type releaseDeleter interface {
 	DeleteRelease(repo, tag string) error
 }
 
+type cleanDraftsClient interface {
+	cleanReleaseLookupClient
+	draftReleaseClient
+	DeleteReleaseByID(repo string, id int64) error
+}
+
 func runClean(cmd *cobra.Command, args []string) error {
 	// Create GitHub client
-	client, err := github.NewClient()
+	client, err := newCommandClient()
 	if err != nil {
-		return fmt.Errorf("failed to create GitHub client: %w", err)
+		return err
 	}
 
 	// Get and validate repository
@@ -39,28 +61,85 @@ func runClean(cmd *cobra.Command, args []string) error {
 		return err
 	}
 
-	stableRelease, err := resolveStableRelease(client, repo, args)
+	cfg, err := config.FromContext(cmd.Context())
 	if err != nil {
 		return err
 	}
 
-	// Find previous stable release
-	previousRelease, err := module_release.FindPreviousRelease(client, repo, stableRelease)
+	if cleanDraftsFlag {
+		if len(args) > 0 {
+			return fmt.Errorf("--drafts does not take a version")
+		}
+		_, err := cleanStaleDrafts(cmd.OutOrStdout(), client, repo, cfg.Branch, client.line)
+		return err
+	}
+
+	stableRelease, err := resolveStableRelease(client, repo, cfg.Branch, args)
 	if err != nil {
-		return fmt.Errorf("failed to find previous release: %w", err)
+		return err
 	}
 
-	// Get pre-releases between the two stable releases
-	preReleases, err := module_release.GetPreReleasesBetween(client, repo, previousRelease, stableRelease)
+	previousRelease, preReleases, err := preReleasesToClean(client, repo, stableRelease, client.line)
 	if err != nil {
-		return fmt.Errorf("failed to get pre-releases: %w", err)
+		return err
 	}
 
 	deletePreReleases(cmd.OutOrStdout(), client, repo, previousRelease, stableRelease, preReleases)
 	return nil
 }
 
-func resolveStableRelease(client cleanReleaseLookupClient, repo string, args []string) (string, error) {
+// lineReleaseClient restricts the release listings of a client to a
+// version line
+type lineReleaseClient struct {
+	cleanRangeClient
+	line *module_release.VersionLine
+}
+
+// ListReleases lists the releases of the version line
+func (c lineReleaseClient) ListReleases(repo string, limit int, excludePreReleases bool) ([]github.Release, error) {
+	return module_release.ListLineReleases(c.cleanRangeClient, repo, c.line, limit, excludePreReleases)
+}
+
+// preReleasesToClean returns the previous stable release of stableRelease
+// and the pre-releases in between of a version line. Without line the line
+// of stableRelease is used, so that cleaning a hotfix never reaches the
+// pre-releases of a newer line. The previous stable release is searched in
+// the line first; the first release of a line, e.g. 1.5.0, follows the
+// previous stable release of any line.
+func preReleasesToClean(client cleanRangeClient, repo, stableRelease string, line *module_release.VersionLine) (string, []string, error) {
+	if line == nil {
+		var err error
+		if line, err = module_release.VersionLineOf(stableRelease); err != nil {
+			return "", nil, err
+		}
+	} else if !line.Contains(stableRelease) {
+		return "", nil, fmt.Errorf("release %s is not in the %s version line", stableRelease, line)
+	}
+
+	// Find previous stable release
+	previousRelease, err := module_release.FindPreviousRelease(lineReleaseClient{cleanRangeClient: client, line: line}, repo, stableRelease)
+	if errors.Is(err, module_release.ErrNoPreviousRelease) {
+		previousRelease, err = module_release.FindPreviousRelease(client, repo, stableRelease)
+	}
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to find previous release: %w", err)
+	}
+
+	// Get pre-releases of the line between the two stable releases
+	preReleases, err := module_release.GetPreReleasesBetween(client, repo, previousRelease, stableRelease)
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to get pre-releases: %w", err)
+	}
+	var linePreReleases []string
+	for _, tag := range preReleases {
+		if line.Contains(tag) {
+			linePreReleases = append(linePreReleases, tag)
+		}
+	}
+	return previousRelease, linePreReleases, nil
+}
+
+func resolveStableRelease(client cleanReleaseLookupClient, repo, branch string, args []string) (string, error) {
 	stableRelease := ""
 	if len(args) > 0 {
 		stableRelease = args[0]
@@ -70,10 +149,13 @@ func resolveStableRelease(client cleanReleaseLookupClient, repo string, args []s
 		return stableRelease, nil
 	}
 
-	release, err := module_release.GetLatestRelease(client, repo, true)
-	if err != nil {
+	release, err := module_release.GetLatestBranchRelease(client, repo, branch, true)
+	if errors.Is(err, module_release.ErrNoReleases) {
 		return "", fmt.Errorf("no stable release found in the repository")
 	}
+	if err != nil {
+		return "", err
+	}
 
 	return release.TagName, nil
 }
@@ -104,3 +186,68 @@ func deletePreReleases(out io.Writer, client releaseDeleter, repo, previousRelea
 	fmt.Fprintf(out, "\n✅ Deleted %d pre-release(s) successfully\n", deletedCount)
 	return deletedCount
 }
+
+// cleanStaleDrafts deletes the drafts of the version line created before the
+// latest release of the branch and returns the number of deleted drafts
+func cleanStaleDrafts(out io.Writer, client cleanDraftsClient, repo, branch string, line *module_release.VersionLine) (int, error) {
+	latest, err := module_release.GetLatestBranchRelease(client, repo, branch, false)
+	if errors.Is(err, module_release.ErrNoReleases) {
+		fmt.Fprintln(out, "No release found, drafts are not stale")
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	drafts, err := client.ListDraftReleases(repo)
+	if err != nil {
+		return 0, err
+	}
+	stale := staleDraftReleases(drafts, latest, line)
+	if len(stale) == 0 {
+		fmt.Fprintf(out, "No draft releases older than %s\n", latest.TagName)
+		return 0, nil
+	}
+
+	fmt.Fprintf(out, "Found %d draft release(s) older than %s:\n", len(stale), latest.TagName)
+	for _, draft := range stale {
+		fmt.Fprintf(out, "  - %s\n", draft.TagName)
+	}
+	fmt.Fprintln(out)
+
+	deletedCount := 0
+	for _, draft := range stale {
+		fmt.Fprintf(out, "Deleting draft %s... ", draft.TagName)
+		if err := client.DeleteReleaseByID(repo, draft.DatabaseID); err != nil {
+			fmt.Fprintf(out, "❌ Failed: %v\n", err)
+			continue
+		}
+		fmt.Fprintln(out, "✅")
+		deletedCount++
+	}
+
+	fmt.Fprintf(out, "\n✅ Deleted %d draft release(s) successfully\n", deletedCount)
+	return deletedCount, nil
+}
+
+// staleDraftReleases returns the drafts of the version line created before
+// latest. Drafts with an unknown creation date are kept.
+func staleDraftReleases(drafts []github.Release, latest *github.Release, line *module_release.VersionLine) []github.Release {
+	latestCreated, err := time.Parse(time.RFC3339, latest.CreatedAt)
+	if err != nil {
+		return nil
+	}
+
+	var stale []github.Release
+	for _, draft := range drafts {
+		if line != nil && !line.Contains(draft.TagName) {
+			continue
+		}
+		created, err := time.Parse(time.RFC3339, draft.CreatedAt)
+		if err != nil || !created.Before(latestCreated) {
+			continue
+		}
+		stale = append(stale, draft)
+	}
+	return stale
+}
@@ -3,9 +3,11 @@ package module_release
 import (
 	"bytes"
 	"errors"
+	"reflect"
 	"testing"
 
 	ghgithub "github.com/NethServer/gh-ns8/internal/github"
+	internalmodule "github.com/NethServer/gh-ns8/internal/module_release"
 )
 
 type fakeCleanClient struct {
@@ -14,6 +16,17 @@ type fakeCleanClient struct {
 	listCalls         []bool
 	deleteErrs        map[string]error
 	deleted           []string
+	drafts            []ghgithub.Release
+	deletedIDs        []int64
+}
+
+func (f *fakeCleanClient) ListDraftReleases(_ string) ([]ghgithub.Release, error) {
+	return f.drafts, nil
+}
+
+func (f *fakeCleanClient) DeleteReleaseByID(_ string, id int64) error {
+	f.deletedIDs = append(f.deletedIDs, id)
+	return nil
 }
 
 func (f *fakeCleanClient) ListReleases(_ string, _ int, excludePreReleases bool) ([]ghgithub.Release, error) {
@@ -24,6 +37,10 @@ func (f *fakeCleanClient) ListReleases(_ string, _ int, excludePreReleases bool)
 	return f.releasesByExclude[excludePreReleases], nil
 }
 
+func (f *fakeCleanClient) GetCompareStatus(_, _, _ string) (string, error) {
+	return "behind", nil
+}
+
 func (f *fakeCleanClient) DeleteRelease(_ string, tag string) error {
 	f.deleted = append(f.deleted, tag)
 	if err, ok := f.deleteErrs[tag]; ok {
@@ -36,7 +53,7 @@ func TestResolveStableRelease(t *testing.T) {
 	t.Run("uses explicit argument", func(t *testing.T) {
 		client := &fakeCleanClient{}
 
-		got, err := resolveStableRelease(client, "NethServer/ns8-mail", []string{"1.2.3"})
+		got, err := resolveStableRelease(client, "NethServer/ns8-mail", "main", []string{"1.2.3"})
 		if err != nil {
 			t.Fatalf("resolveStableRelease() returned error: %v", err)
 		}
@@ -57,7 +74,7 @@ func TestResolveStableRelease(t *testing.T) {
 			},
 		}
 
-		got, err := resolveStableRelease(client, "NethServer/ns8-mail", nil)
+		got, err := resolveStableRelease(client, "NethServer/ns8-mail", "main", nil)
 		if err != nil {
 			t.Fatalf("resolveStableRelease() returned error: %v", err)
 		}
@@ -70,11 +87,18 @@ func TestResolveStableRelease(t *testing.T) {
 	})
 
 	t.Run("returns stable release error", func(t *testing.T) {
-		_, err := resolveStableRelease(&fakeCleanClient{}, "NethServer/ns8-mail", nil)
+		_, err := resolveStableRelease(&fakeCleanClient{}, "NethServer/ns8-mail", "main", nil)
 		if err == nil || err.Error() != "no stable release found in the repository" {
 			t.Fatalf("resolveStableRelease() error = %v, want stable release error", err)
 		}
 	})
+
+	t.Run("keeps API errors", func(t *testing.T) {
+		_, err := resolveStableRelease(&fakeCleanClient{listErr: ghgithub.ErrUnauthorized}, "NethServer/ns8-mail", "main", nil)
+		if !errors.Is(err, ghgithub.ErrUnauthorized) {
+			t.Fatalf("resolveStableRelease() error = %v, want ErrUnauthorized", err)
+		}
+	})
 }
 
 func TestDeletePreReleasesReportsProgressAndContinues(t *testing.T) {
@@ -118,3 +142,142 @@ func TestDeletePreReleasesReportsWhenNoneAreFound(t *testing.T) {
 		t.Fatalf("deletePreReleases() output = %q, want %q", out.String(), want)
 	}
 }
+
+func TestCleanStaleDraftsDeletesDraftsOlderThanLatestRelease(t *testing.T) {
+	client := &fakeCleanClient{
+		releasesByExclude: map[bool][]ghgithub.Release{
+			false: {
+				{TagName: "1.2.4", CreatedAt: "2026-03-10T10:00:00Z"},
+			},
+		},
+		drafts: []ghgithub.Release{
+			{DatabaseID: 3, TagName: "1.2.5", CreatedAt: "2026-03-11T10:00:00Z"},
+			{DatabaseID: 2, TagName: "1.2.4-rc.1", CreatedAt: "2026-03-09T10:00:00Z"},
+			{DatabaseID: 1, TagName: "1.1.0", CreatedAt: "2026-01-09T10:00:00Z"},
+		},
+	}
+
+	var out bytes.Buffer
+	deletedCount, err := cleanStaleDrafts(&out, client, "NethServer/ns8-mail", "main", nil)
+	if err != nil {
+		t.Fatalf("cleanStaleDrafts() returned error: %v", err)
+	}
+	if deletedCount != 2 {
+		t.Fatalf("cleanStaleDrafts() = %d, want %d", deletedCount, 2)
+	}
+	if len(client.deletedIDs) != 2 || client.deletedIDs[0] != 2 || client.deletedIDs[1] != 1 {
+		t.Fatalf("cleanStaleDrafts() deletedIDs = %v, want [2 1]", client.deletedIDs)
+	}
+
+	want := "Found 2 draft release(s) older than 1.2.4:\n" +
+		"  - 1.2.4-rc.1\n" +
+		"  - 1.1.0\n\n" +
+		"Deleting draft 1.2.4-rc.1... ✅\n" +
+		"Deleting draft 1.1.0... ✅\n" +
+		"\n✅ Deleted 2 draft release(s) successfully\n"
+	if out.String() != want {
+		t.Fatalf("cleanStaleDrafts() output = %q, want %q", out.String(), want)
+	}
+}
+
+func TestCleanStaleDraftsKeepsDraftsOutsideTheLine(t *testing.T) {
+	line, err := internalmodule.ParseVersionLine("1.2")
+	if err != nil {
+		t.Fatalf("ParseVersionLine() returned error: %v", err)
+	}
+	client := &fakeCleanClient{
+		releasesByExclude: map[bool][]ghgithub.Release{
+			false: {
+				{TagName: "1.2.4", CreatedAt: "2026-03-10T10:00:00Z"},
+			},
+		},
+		drafts: []ghgithub.Release{
+			{DatabaseID: 1, TagName: "1.1.0", CreatedAt: "2026-01-09T10:00:00Z"},
+		},
+	}
+
+	var out bytes.Buffer
+	deletedCount, err := cleanStaleDrafts(&out, client, "NethServer/ns8-mail", "main", line)
+	if err != nil {
+		t.Fatalf("cleanStaleDrafts() returned error: %v", err)
+	}
+	if deletedCount != 0 || len(client.deletedIDs) != 0 {
+		t.Fatalf("cleanStaleDrafts() deleted %v, want none", client.deletedIDs)
+	}
+	if want := "No draft releases older than 1.2.4\n"; out.String() != want {
+		t.Fatalf("cleanStaleDrafts() output = %q, want %q", out.String(), want)
+	}
+}
+
+type fakeReleaseHistoryClient struct {
+	releases []ghgithub.Release
+}
+
+func (f fakeReleaseHistoryClient) ListReleases(_ string, limit int, excludePreReleases bool) ([]ghgithub.Release, error) {
+	var releases []ghgithub.Release
+	for _, release := range f.releases {
+		if excludePreReleases && release.IsPrerelease {
+			continue
+		}
+		releases = append(releases, release)
+		if limit > 0 && len(releases) == limit {
+			break
+		}
+	}
+	return releases, nil
+}
+
+func (f fakeReleaseHistoryClient) ViewRelease(_ string, tag string) (*ghgithub.Release, error) {
+	for i := range f.releases {
+		if f.releases[i].TagName == tag {
+			return &f.releases[i], nil
+		}
+	}
+	return nil, ghgithub.ErrNotFound
+}
+
+func TestPreReleasesToCleanStaysInTheVersionLine(t *testing.T) {
+	// The 1.4.3 hotfix shipped after 1.5.0, while 1.5.1 is in testing
+	client := fakeReleaseHistoryClient{releases: []ghgithub.Release{
+		{TagName: "1.4.3", CreatedAt: "2026-05-10T10:00:00Z"},
+		{TagName: "1.5.1-testing.1", CreatedAt: "2026-05-09T10:00:00Z", IsPrerelease: true},
+		{TagName: "1.4.3-testing.1", CreatedAt: "2026-05-08T10:00:00Z", IsPrerelease: true},
+		{TagName: "1.5.0", CreatedAt: "2026-05-05T10:00:00Z"},
+		{TagName: "1.5.0-testing.2", CreatedAt: "2026-05-03T10:00:00Z", IsPrerelease: true},
+		{TagName: "1.5.0-testing.1", CreatedAt: "2026-04-25T10:00:00Z", IsPrerelease: true},
+		{TagName: "1.4.2", CreatedAt: "2026-04-20T10:00:00Z"},
+	}}
+
+	previous, preReleases, err := preReleasesToClean(client, "NethServer/ns8-mail", "1.4.3", nil)
+	if err != nil {
+		t.Fatalf("preReleasesToClean() returned error: %v", err)
+	}
+	if previous != "1.4.2" || !reflect.DeepEqual(preReleases, []string{"1.4.3-testing.1"}) {
+		t.Fatalf("preReleasesToClean() = %s, %v, want 1.4.2 and [1.4.3-testing.1]", previous, preReleases)
+	}
+
+	_, _, err = preReleasesToClean(client, "NethServer/ns8-mail", "1.4.3", &internalmodule.VersionLine{Major: 1, Minor: 5})
+	if err == nil || err.Error() != "release 1.4.3 is not in the 1.5 version line" {
+		t.Fatalf("preReleasesToClean() error = %v, want version line error", err)
+	}
+}
+
+func TestPreReleasesToCleanOfFirstReleaseOfLine(t *testing.T) {
+	client := fakeReleaseHistoryClient{releases: []ghgithub.Release{
+		{TagName: "1.5.0", CreatedAt: "2026-05-05T10:00:00Z"},
+		{TagName: "1.5.0-testing.2", CreatedAt: "2026-05-03T10:00:00Z", IsPrerelease: true},
+		{TagName: "1.4.2-testing.1", CreatedAt: "2026-04-28T10:00:00Z", IsPrerelease: true},
+		{TagName: "1.5.0-testing.1", CreatedAt: "2026-04-25T10:00:00Z", IsPrerelease: true},
+		{TagName: "1.4.2", CreatedAt: "2026-04-20T10:00:00Z"},
+	}}
+
+	for _, line := range []*internalmodule.VersionLine{nil, {Major: 1, Minor: 5}} {
+		previous, preReleases, err := preReleasesToClean(client, "NethServer/ns8-mail", "1.5.0", line)
+		if err != nil {
+			t.Fatalf("preReleasesToClean() returned error: %v", err)
+		}
+		if previous != "1.4.2" || !reflect.DeepEqual(preReleases, []string{"1.5.0-testing.2", "1.5.0-testing.1"}) {
+			t.Fatalf("preReleasesToClean() = %s, %v, want 1.4.2 and [1.5.0-testing.2 1.5.0-testing.1]", previous, preReleases)
+		}
+	}
+}
@@ -1,22 +1,39 @@
 package module_release
 
 import (
+	"errors"
 	"fmt"
 	"io"
 	"sort"
+	"time"
 
+	"github.com/NethServer/gh-ns8/internal/config"
 	"github.com/NethServer/gh-ns8/internal/github"
 	"github.com/NethServer/gh-ns8/internal/module_release"
 	"github.com/spf13/cobra"
 )
 
+var (
+	updateProjectFlag bool
+	dryRunFlag        bool
+)
+
 // commentCmd represents the comment command
 var commentCmd = &cobra.Command{
 	Use:   "comment [VERSION]",
 	Short: "Add comments to release issues",
-	Long:  `Post release notifications on open linked issues and their parent issues.`,
-	Args:  cobra.MaximumNArgs(1),
-	RunE:  runComment,
+	Long: `Post release notifications on open linked issues and their parent issues.
+
+With --update-project the project items of the linked issues are also updated
+as configured in project.updates: the status, the release version and the
+release date.`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: withErrorHints(writePermission, runComment),
+}
+
+func init() {
+	commentCmd.Flags().BoolVar(&updateProjectFlag, "update-project", false, "Update the project fields of the linked issues (see project.updates in the configuration)")
+	commentCmd.Flags().BoolVar(&dryRunFlag, "dry-run", false, "Show the comments and project changes without applying them")
 }
 
 type linkedIssueCollector interface {
@@ -29,11 +46,15 @@ type issueCommentClient interface {
 	GetParentIssueNumber(repo string, issueNumber int) (int, error)
 }
 
+type projectIssueUpdater interface {
+	UpdateIssue(repo string, issueNumber int, dryRun bool) ([]module_release.ProjectFieldChange, error)
+}
+
 func runComment(cmd *cobra.Command, args []string) error {
 	// Create GitHub client
-	client, err := github.NewClient()
+	client, err := newCommandClient()
 	if err != nil {
-		return fmt.Errorf("failed to create GitHub client: %w", err)
+		return err
 	}
 
 	// Get and validate repository
@@ -42,6 +63,11 @@ func runComment(cmd *cobra.Command, args []string) error {
 		return err
 	}
 
+	cfg, err := config.FromContext(cmd.Context())
+	if err != nil {
+		return err
+	}
+
 	// Get release name from argument or use latest
 	releaseName := ""
 	if len(args) > 0 {
@@ -49,13 +75,19 @@ func runComment(cmd *cobra.Command, args []string) error {
 	}
 
 	if releaseName == "" {
-		release, err := module_release.GetLatestRelease(client, repo, false)
+		release, err := module_release.GetLatestBranchRelease(client, repo, cfg.Branch, false)
 		if err != nil {
 			return fmt.Errorf("failed to get latest release: %w", err)
 		}
 		releaseName = release.TagName
 	}
 
+	return commentRelease(cmd.OutOrStdout(), cmd.ErrOrStderr(), client, cfg, repo, releaseName, updateProjectFlag, dryRunFlag)
+}
+
+// commentRelease comments on the open issues linked to the PRs of a release
+// and optionally updates their project items
+func commentRelease(out, errWriter io.Writer, client *commandClient, cfg *config.Config, repo, releaseName string, updateProject, dryRun bool) error {
 	// Get release details
 	release, err := client.ViewRelease(repo, releaseName)
 	if err != nil {
@@ -74,21 +106,57 @@ func runComment(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("failed to scan PRs: %w", err)
 	}
 
-	issueMap := collectLinkedIssues(client, repo, issuesRepoFlag, prNumbers)
+	issueMap := collectLinkedIssues(client, repo, cfg.IssuesRepo, prNumbers)
 
 	if len(issueMap) == 0 {
-		fmt.Println("No linked issues found for this release.")
+		fmt.Fprintln(out, "No linked issues found for this release.")
 		return nil
 	}
 
 	// Create comment based on release type
-	commentBody := releaseCommentBody(repo, releaseName, release.IsPrerelease)
+	commentBody, err := releaseCommentBody(cfg, repo, releaseName, release.IsPrerelease)
+	if err != nil {
+		return err
+	}
+
+	var updater *module_release.ProjectUpdater
+	if updateProject {
+		if cfg.Project.Number <= 0 {
+			return fmt.Errorf("--update-project needs project.number in the configuration")
+		}
+		values := module_release.ReleaseProjectValues(cfg.Project, releaseName, releaseDate(release), release.IsPrerelease)
+		if len(values) == 0 {
+			return fmt.Errorf("--update-project needs project.updates in the configuration")
+		}
+		updater, err = module_release.NewProjectUpdater(client, cfg.Project, values)
+		if err != nil {
+			return err
+		}
+	}
 
-	postReleaseComments(cmd.OutOrStdout(), cmd.ErrOrStderr(), client, issuesRepoFlag, commentBody, issueMap)
+	if dryRun {
+		fmt.Fprintf(out, "Dry run, nothing will be changed. Comment:\n%s\n\n", commentBody)
+	}
+	postReleaseComments(out, errWriter, client, cfg.IssuesRepo, commentBody, issueMap, dryRun)
+
+	if updater != nil {
+		fmt.Fprintln(out)
+		updateProjectItems(out, errWriter, updater, cfg.IssuesRepo, issueMap, dryRun)
+	}
 
 	return nil
 }
 
+// releaseDate returns the creation date of a release as YYYY-MM-DD, today
+// when it is unknown
+func releaseDate(release *github.Release) string {
+	created, err := time.Parse(time.RFC3339, release.CreatedAt)
+	if err != nil {
+		created = time.Now()
+	}
+	return created.Format(time.DateOnly)
+}
+
 func collectLinkedIssues(client linkedIssueCollector, repo, issuesRepo string, prNumbers []int) map[int]bool {
 	issueMap := make(map[int]bool)
 	for _, prNum := range prNumbers {
@@ -106,15 +174,54 @@ func collectLinkedIssues(client linkedIssueCollector, repo, issuesRepo string, p
 	return issueMap
 }
 
-func postReleaseComments(out, errWriter io.Writer, client issueCommentClient, issuesRepo, commentBody string, issueMap map[int]bool) int {
+// collectAllLinkedIssues is collectLinkedIssues for callers that must not
+// miss an issue, such as a signed provenance: a PR that cannot be read fails
+// the collection
+func collectAllLinkedIssues(client linkedIssueCollector, repo, issuesRepo string, prNumbers []int) (map[int]bool, error) {
+	issueMap := make(map[int]bool)
+	for _, prNum := range prNumbers {
+		pr, err := client.GetPullRequest(repo, prNum)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get pull request #%d: %w", prNum, err)
+		}
+
+		for _, issueNum := range module_release.GetLinkedIssues(pr.Body, issuesRepo) {
+			issueMap[issueNum] = true
+		}
+	}
+
+	return issueMap, nil
+}
+
+func sortedIssueNumbers(issueMap map[int]bool) []int {
 	issueNumbers := make([]int, 0, len(issueMap))
 	for issueNum := range issueMap {
 		issueNumbers = append(issueNumbers, issueNum)
 	}
 	sort.Ints(issueNumbers)
+	return issueNumbers
+}
+
+func postReleaseComments(out, errWriter io.Writer, client issueCommentClient, issuesRepo, commentBody string, issueMap map[int]bool, dryRun bool) int {
+	// post comments on an issue, kind being "issue" or "parent issue"
+	post := func(issueNum int, kind string) bool {
+		if dryRun {
+			fmt.Fprintf(out, "💬 Would comment on %s %s#%d\n", kind, issuesRepo, issueNum)
+			return true
+		}
+
+		commentURL, err := client.CreateIssueComment(issuesRepo, issueNum, commentBody)
+		if err != nil {
+			fmt.Fprintf(errWriter, "Warning: failed to comment on %s %d: %v\n", kind, issueNum, err)
+			return false
+		}
+
+		fmt.Fprintf(out, "✅ Commented on %s %s#%d\n   %s\n", kind, issuesRepo, issueNum, commentURL)
+		return true
+	}
 
 	commentedCount := 0
-	for _, issueNum := range issueNumbers {
+	for _, issueNum := range sortedIssueNumbers(issueMap) {
 		issue, err := client.GetIssue(issuesRepo, issueNum)
 		if err != nil {
 			fmt.Fprintf(errWriter, "Warning: failed to get issue %d: %v\n", issueNum, err)
@@ -125,13 +232,9 @@ func postReleaseComments(out, errWriter io.Writer, client issueCommentClient, is
 			continue
 		}
 
-		commentURL, err := client.CreateIssueComment(issuesRepo, issueNum, commentBody)
-		if err != nil {
-			fmt.Fprintf(errWriter, "Warning: failed to comment on issue %d: %v\n", issueNum, err)
+		if !post(issueNum, "issue") {
 			continue
 		}
-
-		fmt.Fprintf(out, "✅ Commented on issue %s#%d\n   %s\n", issuesRepo, issueNum, commentURL)
 		commentedCount++
 
 		parentNum, err := client.GetParentIssueNumber(issuesRepo, issueNum)
@@ -144,31 +247,73 @@ func postReleaseComments(out, errWriter io.Writer, client issueCommentClient, is
 			continue
 		}
 
-		parentCommentURL, err := client.CreateIssueComment(issuesRepo, parentNum, commentBody)
-		if err != nil {
-			fmt.Fprintf(errWriter, "Warning: failed to comment on parent issue %d: %v\n", parentNum, err)
-			continue
+		if post(parentNum, "parent issue") {
+			commentedCount++
 		}
-
-		fmt.Fprintf(out, "✅ Commented on parent issue %s#%d\n   %s\n", issuesRepo, parentNum, parentCommentURL)
-		commentedCount++
 	}
 
-	if commentedCount == 0 {
+	switch {
+	case commentedCount == 0:
 		fmt.Fprintln(out, "No open issues to comment on.")
-	} else {
+	case dryRun:
+		fmt.Fprintf(out, "\nDry run: %d comment(s) would be posted\n", commentedCount)
+	default:
 		fmt.Fprintf(out, "\n✅ Posted %d comment(s) successfully\n", commentedCount)
 	}
 
 	return commentedCount
 }
 
-func releaseCommentBody(repo, releaseName string, prerelease bool) string {
-	if prerelease {
-		return fmt.Sprintf("Testing release `%s` [%s](https://github.com/%s/releases/tag/%s)",
-			repo, releaseName, repo, releaseName)
+// updateProjectItems updates the project items of the linked issues and
+// reports the changed fields
+func updateProjectItems(out, errWriter io.Writer, updater projectIssueUpdater, issuesRepo string, issueMap map[int]bool, dryRun bool) int {
+	verb := "Updated"
+	if dryRun {
+		verb = "Would update"
+	}
+
+	updatedCount := 0
+	for _, issueNum := range sortedIssueNumbers(issueMap) {
+		changes, err := updater.UpdateIssue(issuesRepo, issueNum, dryRun)
+		if errors.Is(err, github.ErrNotFound) {
+			fmt.Fprintf(out, "ℹ️  Issue %s#%d is not in the project\n", issuesRepo, issueNum)
+			continue
+		}
+		if err != nil {
+			fmt.Fprintf(errWriter, "Warning: failed to update the project item of issue %d: %v\n", issueNum, err)
+		}
+		if len(changes) == 0 {
+			continue
+		}
+
+		fmt.Fprintf(out, "📋 %s the project item of issue %s#%d\n", verb, issuesRepo, issueNum)
+		for _, change := range changes {
+			from := change.From
+			if from == "" {
+				from = "(empty)"
+			}
+			fmt.Fprintf(out, "   %s: %s → %s\n", change.Field, from, change.To)
+		}
+		updatedCount++
 	}
 
-	return fmt.Sprintf("Release `%s` [%s](https://github.com/%s/releases/tag/%s)",
-		repo, releaseName, repo, releaseName)
+	switch {
+	case updatedCount == 0:
+		fmt.Fprintln(out, "No project items to update.")
+	case dryRun:
+		fmt.Fprintf(out, "\nDry run: %d project item(s) would be updated\n", updatedCount)
+	default:
+		fmt.Fprintf(out, "\n✅ Updated %d project item(s)\n", updatedCount)
+	}
+
+	return updatedCount
+}
+
+// releaseCommentBody renders the configured comment of a release
+func releaseCommentBody(cfg *config.Config, repo, releaseName string, prerelease bool) (string, error) {
+	return cfg.CommentBody(config.CommentData{
+		Repo:    repo,
+		Release: releaseName,
+		URL:     github.WebURL(fmt.Sprintf("%s/releases/tag/%s", repo, releaseName)),
+	}, prerelease)
 }
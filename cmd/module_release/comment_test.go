@@ -3,10 +3,13 @@ package module_release
 import (
 	"bytes"
 	"errors"
+	"fmt"
 	"reflect"
 	"testing"
 
+	internalconfig "github.com/NethServer/gh-ns8/internal/config"
 	ghgithub "github.com/NethServer/gh-ns8/internal/github"
+	internalmodule "github.com/NethServer/gh-ns8/internal/module_release"
 )
 
 func TestReleaseCommentBody(t *testing.T) {
@@ -34,7 +37,10 @@ func TestReleaseCommentBody(t *testing.T) {
 				releaseName = "1.2.3-testing.1"
 			}
 
-			got := releaseCommentBody("NethServer/ns8-mail", releaseName, testCase.prerelease)
+			got, err := releaseCommentBody(internalconfig.Default(), "NethServer/ns8-mail", releaseName, testCase.prerelease)
+			if err != nil {
+				t.Fatalf("releaseCommentBody() returned error: %v", err)
+			}
 			if got != testCase.want {
 				t.Fatalf("releaseCommentBody() = %q, want %q", got, testCase.want)
 			}
@@ -42,6 +48,33 @@ func TestReleaseCommentBody(t *testing.T) {
 	}
 }
 
+func TestReleaseCommentBodyUsesEnterpriseHost(t *testing.T) {
+	t.Setenv("GH_HOST", "git.example.com")
+
+	got, err := releaseCommentBody(internalconfig.Default(), "NethServer/ns8-mail", "1.2.3", false)
+	if err != nil {
+		t.Fatalf("releaseCommentBody() returned error: %v", err)
+	}
+	want := "Release `NethServer/ns8-mail` [1.2.3](https://git.example.com/NethServer/ns8-mail/releases/tag/1.2.3)"
+	if got != want {
+		t.Fatalf("releaseCommentBody() = %q, want %q", got, want)
+	}
+}
+
+func TestReleaseCommentBodyUsesConfiguredTemplate(t *testing.T) {
+	cfg := internalconfig.Default()
+	cfg.Comments.Prerelease = "{{.Release}} of {{.Repo}} is ready for QA: {{.URL}}"
+
+	got, err := releaseCommentBody(cfg, "NethServer/ns8-mail", "1.2.3-rc.1", true)
+	if err != nil {
+		t.Fatalf("releaseCommentBody() returned error: %v", err)
+	}
+	want := "1.2.3-rc.1 of NethServer/ns8-mail is ready for QA: https://github.com/NethServer/ns8-mail/releases/tag/1.2.3-rc.1"
+	if got != want {
+		t.Fatalf("releaseCommentBody() = %q, want %q", got, want)
+	}
+}
+
 type fakeCommentClient struct {
 	prs          map[int]*ghgithub.PullRequest
 	prErrs       map[int]error
@@ -133,7 +166,7 @@ func TestPostReleaseCommentsHandlesOpenClosedAndParentIssues(t *testing.T) {
 		11: true,
 		12: true,
 		13: true,
-	})
+	}, false)
 
 	if commentedCount != 2 {
 		t.Fatalf("postReleaseComments() = %d, want %d", commentedCount, 2)
@@ -175,7 +208,7 @@ func TestPostReleaseCommentsReportsWhenNoOpenIssuesRemain(t *testing.T) {
 	commentedCount := postReleaseComments(&out, &errBuf, client, "NethServer/dev", "body", map[int]bool{
 		10: true,
 		11: true,
-	})
+	}, false)
 
 	if commentedCount != 0 {
 		t.Fatalf("postReleaseComments() = %d, want %d", commentedCount, 0)
@@ -209,7 +242,7 @@ func TestPostReleaseCommentsWarnsWhenParentCommentFails(t *testing.T) {
 	var errBuf bytes.Buffer
 	commentedCount := postReleaseComments(&out, &errBuf, client, "NethServer/dev", "body", map[int]bool{
 		10: true,
-	})
+	}, false)
 
 	if commentedCount != 1 {
 		t.Fatalf("postReleaseComments() = %d, want %d", commentedCount, 1)
@@ -227,3 +260,111 @@ func TestPostReleaseCommentsWarnsWhenParentCommentFails(t *testing.T) {
 		t.Fatalf("postReleaseComments() stderr = %q, want %q", errBuf.String(), wantErr)
 	}
 }
+
+func TestPostReleaseCommentsDryRunPostsNothing(t *testing.T) {
+	client := &fakeCommentClient{
+		issues: map[int]*ghgithub.Issue{
+			10: {State: "OPEN"},
+			11: {State: "CLOSED"},
+			20: {State: "OPEN"},
+		},
+		parentIssues: map[int]int{
+			10: 20,
+		},
+	}
+
+	var out bytes.Buffer
+	var errBuf bytes.Buffer
+	commentedCount := postReleaseComments(&out, &errBuf, client, "NethServer/dev", "body", map[int]bool{
+		10: true,
+		11: true,
+	}, true)
+
+	if commentedCount != 2 {
+		t.Fatalf("postReleaseComments() = %d, want %d", commentedCount, 2)
+	}
+	if len(client.commented) != 0 {
+		t.Fatalf("postReleaseComments() commented = %v, want none in a dry run", client.commented)
+	}
+
+	wantOut := "💬 Would comment on issue NethServer/dev#10\n" +
+		"💬 Would comment on parent issue NethServer/dev#20\n" +
+		"\nDry run: 2 comment(s) would be posted\n"
+	if out.String() != wantOut {
+		t.Fatalf("postReleaseComments() stdout = %q, want %q", out.String(), wantOut)
+	}
+}
+
+type fakeProjectUpdater struct {
+	changes map[int][]internalmodule.ProjectFieldChange
+	errs    map[int]error
+	dryRuns []bool
+}
+
+func (f *fakeProjectUpdater) UpdateIssue(_ string, issueNumber int, dryRun bool) ([]internalmodule.ProjectFieldChange, error) {
+	f.dryRuns = append(f.dryRuns, dryRun)
+	return f.changes[issueNumber], f.errs[issueNumber]
+}
+
+func TestUpdateProjectItemsReportsChanges(t *testing.T) {
+	updater := &fakeProjectUpdater{
+		changes: map[int][]internalmodule.ProjectFieldChange{
+			10: {
+				{Field: "Status", From: "In progress", To: "Testing"},
+				{Field: "Released in", To: "1.2.0-testing.1"},
+			},
+		},
+		errs: map[int]error{
+			11: fmt.Errorf("issue 11 is not in the project: %w", ghgithub.ErrNotFound),
+			13: errors.New("missing project scope"),
+		},
+	}
+
+	var out bytes.Buffer
+	var errBuf bytes.Buffer
+	updatedCount := updateProjectItems(&out, &errBuf, updater, "NethServer/dev", map[int]bool{10: true, 11: true, 12: true, 13: true}, false)
+
+	if updatedCount != 1 {
+		t.Fatalf("updateProjectItems() = %d, want 1", updatedCount)
+	}
+	wantOut := "📋 Updated the project item of issue NethServer/dev#10\n" +
+		"   Status: In progress → Testing\n" +
+		"   Released in: (empty) → 1.2.0-testing.1\n" +
+		"ℹ️  Issue NethServer/dev#11 is not in the project\n" +
+		"\n✅ Updated 1 project item(s)\n"
+	if out.String() != wantOut {
+		t.Fatalf("updateProjectItems() stdout = %q, want %q", out.String(), wantOut)
+	}
+	wantErr := "Warning: failed to update the project item of issue 13: missing project scope\n"
+	if errBuf.String() != wantErr {
+		t.Fatalf("updateProjectItems() stderr = %q, want %q", errBuf.String(), wantErr)
+	}
+}
+
+func TestUpdateProjectItemsDryRun(t *testing.T) {
+	updater := &fakeProjectUpdater{
+		changes: map[int][]internalmodule.ProjectFieldChange{
+			10: {{Field: "Status", From: "In progress", To: "Testing"}},
+		},
+	}
+
+	var out bytes.Buffer
+	var errBuf bytes.Buffer
+	updateProjectItems(&out, &errBuf, updater, "NethServer/dev", map[int]bool{10: true}, true)
+
+	if !reflect.DeepEqual(updater.dryRuns, []bool{true}) {
+		t.Fatalf("UpdateIssue() dry runs = %v, want [true]", updater.dryRuns)
+	}
+	wantOut := "📋 Would update the project item of issue NethServer/dev#10\n" +
+		"   Status: In progress → Testing\n" +
+		"\nDry run: 1 project item(s) would be updated\n"
+	if out.String() != wantOut {
+		t.Fatalf("updateProjectItems() stdout = %q, want %q", out.String(), wantOut)
+	}
+}
+
+func TestReleaseDate(t *testing.T) {
+	if got := releaseDate(&ghgithub.Release{CreatedAt: "2026-10-18T09:30:00Z"}); got != "2026-10-18" {
+		t.Fatalf("releaseDate() = %q, want 2026-10-18", got)
+	}
+}
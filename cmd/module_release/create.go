@@ -1,12 +1,15 @@
 package module_release
 
 import (
-	"bytes"
+	"crypto/ed25519"
 	"fmt"
 	"io"
+	"os"
 	"sort"
 	"strings"
+	"time"
 
+	"github.com/NethServer/gh-ns8/internal/config"
 	"github.com/NethServer/gh-ns8/internal/github"
 	"github.com/NethServer/gh-ns8/internal/module_release"
 	"github.com/spf13/cobra"
@@ -15,8 +18,17 @@ import (
 var (
 	releaseRefsFlag      string
 	testingFlag          bool
+	stageFlag            string
+	stagesFlag           string
 	draftFlag            bool
 	withLinkedIssuesFlag bool
+	notesFlag            string
+	cumulativeNotesFlag  bool
+	changelogFlag        bool
+	assetFlag            []string
+	sbomFlag             string
+	provenanceKeyFlag    string
+	skipValidationFlag   bool
 )
 
 type linkedIssuesNotesClient interface {
@@ -28,7 +40,9 @@ type linkedIssuesNotesClient interface {
 
 type createReleaseFlowClient interface {
 	ListReleases(repo string, limit int, excludePreReleases bool) ([]github.Release, error)
+	ListDraftReleases(repo string) ([]github.Release, error)
 	GetCommitSHA(repo, ref string) (string, error)
+	GetCompareStatus(repo, base, head string) (string, error)
 }
 
 // createCmd represents the create command
@@ -37,21 +51,43 @@ var createCmd = &cobra.Command{
 	Short: "Create a new release",
 	Long:  `Create a new release for a NethServer 8 module with automatic version generation and release notes.`,
 	Args:  cobra.MaximumNArgs(1),
-	RunE:  runCreate,
+	RunE:  withErrorHints(writePermission, runCreate),
 }
 
 func init() {
 	createCmd.Flags().StringVar(&releaseRefsFlag, "release-refs", "", "Commit SHA to associate with the release")
-	createCmd.Flags().BoolVar(&testingFlag, "testing", false, "Create a testing release")
+	createCmd.Flags().BoolVar(&testingFlag, "testing", false, "Create a testing release (a pre-release of the first stage)")
+	createCmd.Flags().StringVar(&stageFlag, "stage", "", "Create the next pre-release of a stage, e.g. rc")
+	createCmd.Flags().StringVar(&stagesFlag, "stages", strings.Join(module_release.DefaultPrereleaseStages, ","), "Ordered prerelease stages, e.g. dev,testing,rc (overrides prerelease.stages)")
 	createCmd.Flags().BoolVar(&draftFlag, "draft", false, "Create a draft release")
 	createCmd.Flags().BoolVar(&withLinkedIssuesFlag, "with-linked-issues", false, "Include linked issues from PRs in release notes")
+	createCmd.Flags().StringVar(&notesFlag, "notes", config.NotesGitHub, "Release notes: github, native or both (overrides notes.source)")
+	createCmd.Flags().BoolVar(&cumulativeNotesFlag, "cumulative-notes", false, "Aggregate the notes of the pre-releases since the previous stable release (stable releases only)")
+	createCmd.Flags().BoolVar(&changelogFlag, "changelog", false, "Commit the release to the CHANGELOG.md of the branch before tagging it (stable releases only)")
+	createCmd.Flags().StringArrayVar(&assetFlag, "asset", nil, "Attach the files matching a glob pattern to the release, with their SHA256SUMS (repeatable)")
+	createCmd.Flags().StringVar(&sbomFlag, "sbom", "", "Attach an SBOM of the target commit to the release: spdx or cyclonedx")
+	createCmd.Flags().StringVar(&provenanceKeyFlag, "provenance-key", "", "Attach a provenance statement signed with this ed25519 private key, e.g. ~/.ssh/id_ed25519")
+	createCmd.Flags().BoolVar(&skipValidationFlag, "skip-validation", false, "Skip the metadata checks of validation.checks")
+}
+
+type sbomClient interface {
+	ListFiles(repo, ref string) ([]string, error)
+	GetFile(repo, path, ref string) (*github.File, error)
+}
+
+type metadataClient interface {
+	GetFile(repo, path, ref string) (*github.File, error)
+}
+
+type assetUploader interface {
+	UploadReleaseAsset(repo string, releaseID int64, name string, content []byte) (*github.ReleaseAsset, error)
 }
 
 func runCreate(cmd *cobra.Command, args []string) error {
 	// Create GitHub client
-	client, err := github.NewClient()
+	client, err := newCommandClient()
 	if err != nil {
-		return fmt.Errorf("failed to create GitHub client: %w", err)
+		return err
 	}
 
 	// Get and validate repository
@@ -60,47 +96,299 @@ func runCreate(cmd *cobra.Command, args []string) error {
 		return err
 	}
 
+	cfg, err := config.FromContext(cmd.Context())
+	if err != nil {
+		return err
+	}
+	branch := cfg.Branch
+
 	// Get or validate commit
-	commitInfo, err := module_release.GetOrValidateCommit(client, repo, releaseRefsFlag)
+	commitInfo, err := module_release.GetOrValidateCommit(client, repo, branch, releaseRefsFlag)
+	if err != nil {
+		return err
+	}
+
+	stages, err := module_release.NewPrereleaseStages(cfg.Prerelease.Stages)
 	if err != nil {
 		return err
 	}
+	stage := stageFlag
+	if testingFlag && stage == "" {
+		stage = stages[0]
+	}
 
-	releaseName, isPrerelease, err := resolveCreateReleaseName(client, repo, args, testingFlag)
+	releaseName, isPrerelease, err := resolveCreateReleaseName(client, repo, branch, args, stages, stage)
 	if err != nil {
 		return err
 	}
+	if client.line != nil && !client.line.Contains(releaseName) {
+		return fmt.Errorf("release %s is not in the %s version line", releaseName, client.line)
+	}
+
+	if cumulativeNotesFlag && isPrerelease {
+		return fmt.Errorf("--cumulative-notes only applies to stable releases")
+	}
+	if changelogFlag && isPrerelease {
+		return fmt.Errorf("--changelog only applies to stable releases")
+	}
+	if changelogFlag && releaseRefsFlag != "" {
+		return fmt.Errorf("--changelog commits to the head of the branch and cannot be used with --release-refs")
+	}
+
+	// Read the assets before changing anything
+	assets, err := module_release.ReadAssets(assetFlag)
+	if err != nil {
+		return err
+	}
+	if sbomFlag != "" {
+		if err := module_release.ValidateSBOMFormat(sbomFlag); err != nil {
+			return err
+		}
+	}
+	var provenanceKey ed25519.PrivateKey
+	if provenanceKeyFlag != "" {
+		if provenanceKey, err = readPrivateKey(provenanceKeyFlag); err != nil {
+			return err
+		}
+	}
+
+	if !skipValidationFlag {
+		release := module_release.MetadataRelease{Name: releaseName, Prerelease: isPrerelease, Stages: stages}
+		if err := validateReleaseMetadata(os.Stdout, client, cfg, repo, commitInfo.SHA, release); err != nil {
+			return err
+		}
+	}
 
-	previousRelease := previousReleaseForCreate(client, repo, isPrerelease)
-	notesReader := linkedIssuesNotesReader(client, repo, previousRelease, issuesRepoFlag, withLinkedIssuesFlag)
+	previousRelease := previousReleaseForCreate(client, repo, branch, isPrerelease)
+	// The notes and the provenance cover the commits of the release, up to
+	// its target
+	prNumbers, err := scanReleasePRs(client, cfg, repo, previousRelease, commitInfo.SHA, cumulativeNotesFlag, withLinkedIssuesFlag, provenanceKey != nil)
+	if err != nil {
+		return err
+	}
+	var notes string
+	var generateNotes bool
+	if cumulativeNotesFlag {
+		var preReleases []string
+		if preReleases, err = cumulativePreReleases(client, repo, releaseName, previousRelease, ""); err == nil {
+			notes, generateNotes, err = cumulativeReleaseNotes(client, cfg, repo, previousRelease, preReleases, prNumbers)
+		}
+	} else {
+		notes, generateNotes, err = createReleaseNotes(client, cfg, repo, previousRelease, prNumbers, withLinkedIssuesFlag)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to generate release notes: %w", err)
+	}
 
-	// Create the release
+	// The changelog commit is tagged, so that the release includes it
 	target := commitInfo.Target
-	if err := client.CreateRelease(repo, releaseName, releaseName, draftFlag, isPrerelease, target, notesReader); err != nil {
+	if changelogFlag {
+		var committed bool
+		target, committed, err = updateReleaseChangelog(os.Stderr, client, cfg, repo, releaseName, previousRelease, commitInfo.SHA, time.Now().Format(time.DateOnly))
+		if err != nil {
+			return fmt.Errorf("failed to update %s: %w", module_release.ChangelogFile, err)
+		}
+		if committed {
+			fmt.Printf("📝 %s updated in commit %s\n", module_release.ChangelogFile, target)
+		}
+	}
+
+	if sbomFlag != "" {
+		asset, err := sbomAsset(client, repo, releaseName, target, sbomFlag)
+		if err != nil {
+			return fmt.Errorf("failed to generate the SBOM: %w", err)
+		}
+		assets = append(assets, asset)
+	}
+
+	if provenanceKey != nil {
+		asset, err := provenanceAsset(client, cfg, repo, releaseName, previousRelease, target, prNumbers, provenanceKey)
+		if err != nil {
+			return fmt.Errorf("failed to generate the provenance: %w", err)
+		}
+		assets = append(assets, asset)
+	}
+
+	// Create the release. With assets it is a draft until they are all
+	// uploaded, so that it is never published without them.
+	release, err := client.CreateRelease(repo, github.NewRelease{
+		Tag:           releaseName,
+		Title:         releaseName,
+		Target:        target,
+		Draft:         draftFlag || len(assets) > 0,
+		Prerelease:    isPrerelease,
+		Notes:         module_release.ManagedNotes(notes),
+		GenerateNotes: generateNotes,
+	})
+	if err != nil {
 		return fmt.Errorf("failed to create release: %w", err)
 	}
 
+	if len(assets) > 0 {
+		if err := uploadReleaseAssets(os.Stdout, client, repo, release.DatabaseID, assets); err != nil {
+			return fmt.Errorf("%w: release %s is left as a draft", err, releaseName)
+		}
+		if !draftFlag {
+			isDraft := false
+			if err := client.UpdateReleaseByID(repo, release.DatabaseID, github.ReleaseUpdate{Draft: &isDraft}); err != nil {
+				return fmt.Errorf("failed to publish release %s: %w", releaseName, err)
+			}
+		}
+	}
+
 	fmt.Printf("✅ Release %s created successfully\n", releaseName)
 	return nil
 }
 
-func resolveCreateReleaseName(client createReleaseFlowClient, repo string, args []string, testing bool) (string, bool, error) {
+// scanReleasePRs returns the PRs of the previousRelease...head range, scanned
+// once for the release notes and the provenance. The range is not scanned
+// when neither needs it: without a previous release, or with the GitHub notes
+// alone and no provenance. The signed provenance must list every PR, so with
+// it a commit whose PRs cannot be listed fails the scan, while a range
+// without PRs does not. The GitHub notes only miss their linked issues when
+// the scan fails.
+func scanReleasePRs(client linkedIssuesNotesClient, cfg *config.Config, repo, previousRelease, head string, cumulative, withLinkedIssues, provenance bool) ([]int, error) {
+	if previousRelease == "" {
+		return nil, nil
+	}
+
+	if provenance {
+		prNumbers, err := module_release.ScanForAllPRs(client, repo, previousRelease, head)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan the pull requests of the provenance: %w", err)
+		}
+		return prNumbers, nil
+	}
+
+	nativeNotes := cumulative || cfg.Notes.Source != config.NotesGitHub
+	if !nativeNotes && !withLinkedIssues {
+		return nil, nil
+	}
+	prNumbers, err := module_release.ScanForPRs(client, repo, previousRelease, head)
+	if err != nil && nativeNotes {
+		return nil, fmt.Errorf("failed to generate release notes: %w", err)
+	}
+	return prNumbers, nil
+}
+
+// validateReleaseMetadata runs the configured metadata checks on the files
+// of commit and reports their outcome; any failed check aborts the release
+func validateReleaseMetadata(out io.Writer, client metadataClient, cfg *config.Config, repo, commit string, release module_release.MetadataRelease) error {
+	results, err := module_release.ValidateMetadata(client, repo, commit, cfg.Validation, release)
+	if err != nil {
+		return fmt.Errorf("failed to validate the module metadata: %w", err)
+	}
+	if len(results) == 0 {
+		return nil
+	}
+
+	failed := 0
+	fmt.Fprintf(out, "🔎 Module metadata of %s at commit %s\n", release.Name, commit)
+	for _, result := range results {
+		if result.Passed() {
+			fmt.Fprintf(out, "✅ %s\n", result.Check)
+			continue
+		}
+		failed++
+		fmt.Fprintf(out, "❌ %s\n", result.Check)
+		for _, problem := range result.Problems {
+			fmt.Fprintf(out, "   - %s\n", problem)
+		}
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d metadata check(s) failed for release %s: fix the metadata or use --skip-validation", failed, release.Name)
+	}
+	return nil
+}
+
+// sbomAsset returns the SBOM of the repository tree at commit as an asset
+func sbomAsset(client sbomClient, repo, version, commit, format string) (module_release.Asset, error) {
+	sbom, err := module_release.NewSBOM(client, repo, version, commit, time.Now())
+	if err != nil {
+		return module_release.Asset{}, err
+	}
+	content, err := sbom.Document(format)
+	if err != nil {
+		return module_release.Asset{}, err
+	}
+	return module_release.Asset{Name: sbom.AssetName(format), Content: content}, nil
+}
+
+// readPrivateKey reads the ed25519 private key signing the provenance
+func readPrivateKey(path string) (ed25519.PrivateKey, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the provenance key: %w", err)
+	}
+	key, err := module_release.ParsePrivateKey(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the provenance key %s: %w", path, err)
+	}
+	return key, nil
+}
+
+// provenanceAsset returns the signed provenance of a release tagged on
+// commit. Its changes are prNumbers, the PRs of the release notes since
+// previousRelease, and their linked issues; a PR that cannot be read fails
+// the provenance rather than leaving out its issues.
+func provenanceAsset(client linkedIssuesNotesClient, cfg *config.Config, repo, tag, previousRelease, commit string, prNumbers []int, key ed25519.PrivateKey) (module_release.Asset, error) {
+	provenance := &module_release.Provenance{
+		Repo:            repo,
+		Tag:             tag,
+		Commit:          commit,
+		PreviousRelease: previousRelease,
+		IssuesRepo:      cfg.IssuesRepo,
+		Created:         time.Now(),
+	}
+
+	if previousRelease != "" {
+		issueMap, err := collectAllLinkedIssues(client, repo, cfg.IssuesRepo, prNumbers)
+		if err != nil {
+			return module_release.Asset{}, err
+		}
+		provenance.PullRequests = prNumbers
+		provenance.LinkedIssues = sortedIssueNumbers(issueMap)
+	}
+
+	content, err := provenance.Sign(key)
+	if err != nil {
+		return module_release.Asset{}, err
+	}
+	return module_release.Asset{Name: module_release.ProvenanceAssetName(repo, tag), Content: content}, nil
+}
+
+// uploadReleaseAssets attaches the assets and their SHA256SUMS to a release
+func uploadReleaseAssets(out io.Writer, client assetUploader, repo string, releaseID int64, assets []module_release.Asset) error {
+	uploads := append(assets[:len(assets):len(assets)], module_release.ChecksumsFile(assets))
+	for _, asset := range uploads {
+		if _, err := client.UploadReleaseAsset(repo, releaseID, asset.Name, asset.Content); err != nil {
+			return err
+		}
+		fmt.Fprintf(out, "📦 Uploaded %s\n", asset.Name)
+	}
+	return nil
+}
+
+// resolveCreateReleaseName returns the release name and whether it is a
+// pre-release. Without an explicit name, the next pre-release of stage is
+// generated. An explicit name must not be taken by a draft.
+func resolveCreateReleaseName(client createReleaseFlowClient, repo, branch string, args []string, stages module_release.PrereleaseStages, stage string) (string, bool, error) {
 	releaseName := ""
 	if len(args) > 0 {
 		releaseName = args[0]
 	}
 
-	isPrerelease := testing || strings.Contains(releaseName, "-")
+	isPrerelease := stage != "" || strings.Contains(releaseName, "-")
 
-	if testing && releaseName == "" {
-		nextRelease, err := module_release.NextTestingRelease(client, repo)
+	if stage != "" && releaseName == "" {
+		nextRelease, err := module_release.NextPrerelease(client, repo, branch, stages, stage)
 		if err != nil {
-			return "", false, fmt.Errorf("failed to generate testing release name: %w", err)
+			return "", false, fmt.Errorf("failed to generate %s release name: %w", stage, err)
 		}
 		releaseName = nextRelease
 	}
 
-	if releaseName == "" && !testing {
+	if releaseName == "" && stage == "" {
 		return "", false, fmt.Errorf("please provide the release name as an argument")
 	}
 
@@ -108,11 +396,21 @@ func resolveCreateReleaseName(client createReleaseFlowClient, repo string, args
 		return "", false, fmt.Errorf("invalid semver format for release name: %s", releaseName)
 	}
 
+	if len(args) > 0 {
+		drafts, err := client.ListDraftReleases(repo)
+		if err != nil {
+			return "", false, fmt.Errorf("failed to list draft releases: %w", err)
+		}
+		if findDraftRelease(drafts, releaseName) != nil {
+			return "", false, fmt.Errorf("a draft release %s already exists: publish it with the publish command", releaseName)
+		}
+	}
+
 	return releaseName, isPrerelease, nil
 }
 
-func previousReleaseForCreate(client createReleaseFlowClient, repo string, isPrerelease bool) string {
-	release, err := module_release.GetLatestRelease(client, repo, !isPrerelease)
+func previousReleaseForCreate(client createReleaseFlowClient, repo, branch string, isPrerelease bool) string {
+	release, err := module_release.GetLatestBranchRelease(client, repo, branch, !isPrerelease)
 	if err != nil {
 		return ""
 	}
@@ -120,27 +418,20 @@ func previousReleaseForCreate(client createReleaseFlowClient, repo string, isPre
 	return release.TagName
 }
 
-func linkedIssuesNotesReader(client linkedIssuesNotesClient, repo, previousRelease, issuesRepo string, include bool) io.Reader {
-	if !include || previousRelease == "" {
-		return nil
-	}
-
-	notes, err := generateLinkedIssuesNotes(client, repo, previousRelease, issuesRepo)
-	if err != nil || notes == "" {
-		return nil
-	}
-
-	return bytes.NewBufferString(notes)
-}
-
-// generateLinkedIssuesNotes generates release notes with linked issues
-func generateLinkedIssuesNotes(client linkedIssuesNotesClient, repo, previousRelease, issuesRepo string) (string, error) {
+// generateLinkedIssuesNotes generates release notes with the issues linked
+// to the PRs of the previousRelease...head range
+func generateLinkedIssuesNotes(client linkedIssuesNotesClient, repo, previousRelease, head, issuesRepo string) (string, error) {
 	// Scan for PRs
-	prNumbers, err := module_release.ScanForPRs(client, repo, previousRelease, "main")
+	prNumbers, err := module_release.ScanForPRs(client, repo, previousRelease, head)
 	if err != nil {
 		return "", err
 	}
+	return linkedIssuesList(client, repo, issuesRepo, prNumbers), nil
+}
 
+// linkedIssuesList lists the issues linked to the PRs, empty when there are
+// none
+func linkedIssuesList(client linkedIssuesNotesClient, repo, issuesRepo string, prNumbers []int) string {
 	// Collect linked issues
 	issueMap := make(map[int]string)
 	for _, prNum := range prNumbers {
@@ -162,7 +453,7 @@ func generateLinkedIssuesNotes(client linkedIssuesNotesClient, repo, previousRel
 	}
 
 	if len(issueMap) == 0 {
-		return "", nil
+		return ""
 	}
 
 	// Format notes
@@ -175,9 +466,9 @@ func generateLinkedIssuesNotes(client linkedIssuesNotesClient, repo, previousRel
 	sort.Ints(issueNumbers)
 	for _, issueNum := range issueNumbers {
 		title := issueMap[issueNum]
-		notes.WriteString(fmt.Sprintf("- [%s#%d](https://github.com/%s/issues/%d): %s\n",
-			issuesRepo, issueNum, issuesRepo, issueNum, title))
+		issueURL := github.WebURL(fmt.Sprintf("%s/issues/%d", issuesRepo, issueNum))
+		notes.WriteString(fmt.Sprintf("- [%s#%d](%s): %s\n", issuesRepo, issueNum, issueURL, title))
 	}
 
-	return notes.String(), nil
+	return notes.String()
 }
@@ -1,31 +1,46 @@
 package module_release
 
 import (
+	"bytes"
 	"errors"
-	"io"
+	"reflect"
+	"strings"
 	"testing"
 
+	internalconfig "github.com/NethServer/gh-ns8/internal/config"
 	ghgithub "github.com/NethServer/gh-ns8/internal/github"
+	internalmodule "github.com/NethServer/gh-ns8/internal/module_release"
 )
 
 type fakeLinkedIssuesNotesClient struct {
 	comparison *ghgithub.CompareResult
-	compareErr error
-	commitPRs  map[string][]int
+	// comparisons override comparison for base...head ranges
+	comparisons map[string]*ghgithub.CompareResult
+	compareErr  error
+	commitPRs   map[string][]int
+	// commitErrs fail the PR lookups of commits
+	commitErrs map[string]error
 	prErrs     map[int]error
 	prs        map[int]*ghgithub.PullRequest
 	issueErrs  map[int]error
 	issues     map[int]*ghgithub.Issue
+	parents    map[int]int
 }
 
-func (f fakeLinkedIssuesNotesClient) CompareCommits(_, _, _ string) (*ghgithub.CompareResult, error) {
+func (f fakeLinkedIssuesNotesClient) CompareCommits(_, base, head string) (*ghgithub.CompareResult, error) {
 	if f.compareErr != nil {
 		return nil, f.compareErr
 	}
+	if comparison, ok := f.comparisons[base+"..."+head]; ok {
+		return comparison, nil
+	}
 	return f.comparison, nil
 }
 
 func (f fakeLinkedIssuesNotesClient) GetPullRequestsForCommit(_ string, sha string) ([]int, error) {
+	if err, ok := f.commitErrs[sha]; ok {
+		return nil, err
+	}
 	return f.commitPRs[sha], nil
 }
 
@@ -43,6 +58,10 @@ func (f fakeLinkedIssuesNotesClient) GetIssue(_ string, number int) (*ghgithub.I
 	return f.issues[number], nil
 }
 
+func (f fakeLinkedIssuesNotesClient) GetParentIssueNumber(_ string, issueNumber int) (int, error) {
+	return f.parents[issueNumber], nil
+}
+
 func makeCommandCompareResult(shas ...string) *ghgithub.CompareResult {
 	result := &ghgithub.CompareResult{
 		Commits: make([]struct {
@@ -74,7 +93,7 @@ func TestGenerateLinkedIssuesNotesDeduplicatesAndSortsIssues(t *testing.T) {
 		},
 	}
 
-	got, err := generateLinkedIssuesNotes(client, "NethServer/ns8-mail", "1.2.0", "NethServer/dev")
+	got, err := generateLinkedIssuesNotes(client, "NethServer/ns8-mail", "1.2.0", "main", "NethServer/dev")
 	if err != nil {
 		t.Fatalf("generateLinkedIssuesNotes() returned error: %v", err)
 	}
@@ -98,7 +117,7 @@ func TestGenerateLinkedIssuesNotesReturnsEmptyWhenNoIssuesAreLinked(t *testing.T
 		},
 	}
 
-	got, err := generateLinkedIssuesNotes(client, "NethServer/ns8-mail", "1.2.0", "NethServer/dev")
+	got, err := generateLinkedIssuesNotes(client, "NethServer/ns8-mail", "1.2.0", "main", "NethServer/dev")
 	if err != nil {
 		t.Fatalf("generateLinkedIssuesNotes() returned error: %v", err)
 	}
@@ -121,7 +140,7 @@ func TestGenerateLinkedIssuesNotesSkipsUnavailableIssues(t *testing.T) {
 		},
 	}
 
-	got, err := generateLinkedIssuesNotes(client, "NethServer/ns8-mail", "1.2.0", "NethServer/dev")
+	got, err := generateLinkedIssuesNotes(client, "NethServer/ns8-mail", "1.2.0", "main", "NethServer/dev")
 	if err != nil {
 		t.Fatalf("generateLinkedIssuesNotes() returned error: %v", err)
 	}
@@ -136,6 +155,11 @@ type fakeCreateReleaseFlowClient struct {
 	listCalls         []bool
 	commitSHAs        map[string]string
 	commitErrs        map[string]error
+	drafts            []ghgithub.Release
+}
+
+func (f *fakeCreateReleaseFlowClient) ListDraftReleases(_ string) ([]ghgithub.Release, error) {
+	return f.drafts, nil
 }
 
 func (f *fakeCreateReleaseFlowClient) ListReleases(_ string, _ int, excludePreReleases bool) ([]ghgithub.Release, error) {
@@ -146,6 +170,10 @@ func (f *fakeCreateReleaseFlowClient) ListReleases(_ string, _ int, excludePreRe
 	return f.releasesByExclude[excludePreReleases], nil
 }
 
+func (f *fakeCreateReleaseFlowClient) GetCompareStatus(_, _, _ string) (string, error) {
+	return "behind", nil
+}
+
 func (f *fakeCreateReleaseFlowClient) GetCommitSHA(_, ref string) (string, error) {
 	if err, ok := f.commitErrs[ref]; ok {
 		return "", err
@@ -166,7 +194,7 @@ func TestResolveCreateReleaseNameAutoGeneratesTestingRelease(t *testing.T) {
 		},
 	}
 
-	gotName, gotPrerelease, err := resolveCreateReleaseName(client, "NethServer/ns8-mail", nil, true)
+	gotName, gotPrerelease, err := resolveCreateReleaseName(client, "NethServer/ns8-mail", "main", nil, nil, "testing")
 	if err != nil {
 		t.Fatalf("resolveCreateReleaseName() returned error: %v", err)
 	}
@@ -182,21 +210,21 @@ func TestResolveCreateReleaseNameAutoGeneratesTestingRelease(t *testing.T) {
 }
 
 func TestResolveCreateReleaseNameRequiresVersionForStableRelease(t *testing.T) {
-	_, _, err := resolveCreateReleaseName(&fakeCreateReleaseFlowClient{}, "NethServer/ns8-mail", nil, false)
+	_, _, err := resolveCreateReleaseName(&fakeCreateReleaseFlowClient{}, "NethServer/ns8-mail", "main", nil, nil, "")
 	if err == nil || err.Error() != "please provide the release name as an argument" {
 		t.Fatalf("resolveCreateReleaseName() error = %v, want missing release name error", err)
 	}
 }
 
 func TestResolveCreateReleaseNameRejectsInvalidSemver(t *testing.T) {
-	_, _, err := resolveCreateReleaseName(&fakeCreateReleaseFlowClient{}, "NethServer/ns8-mail", []string{"latest"}, false)
+	_, _, err := resolveCreateReleaseName(&fakeCreateReleaseFlowClient{}, "NethServer/ns8-mail", "main", []string{"latest"}, nil, "")
 	if err == nil || err.Error() != "invalid semver format for release name: latest" {
 		t.Fatalf("resolveCreateReleaseName() error = %v, want invalid semver error", err)
 	}
 }
 
 func TestResolveCreateReleaseNameMarksExplicitPrerelease(t *testing.T) {
-	gotName, gotPrerelease, err := resolveCreateReleaseName(&fakeCreateReleaseFlowClient{}, "NethServer/ns8-mail", []string{"1.2.4-testing.3"}, false)
+	gotName, gotPrerelease, err := resolveCreateReleaseName(&fakeCreateReleaseFlowClient{}, "NethServer/ns8-mail", "main", []string{"1.2.4-testing.3"}, nil, "")
 	if err != nil {
 		t.Fatalf("resolveCreateReleaseName() returned error: %v", err)
 	}
@@ -221,7 +249,7 @@ func TestResolveCreateReleaseNameReturnsTestingGenerationError(t *testing.T) {
 		},
 	}
 
-	_, _, err := resolveCreateReleaseName(client, "NethServer/ns8-mail", nil, true)
+	_, _, err := resolveCreateReleaseName(client, "NethServer/ns8-mail", "main", nil, nil, "testing")
 	want := "failed to generate testing release name: the latest release tag is the HEAD of the main branch"
 	if err == nil || err.Error() != want {
 		t.Fatalf("resolveCreateReleaseName() error = %v, want %q", err, want)
@@ -240,12 +268,12 @@ func TestPreviousReleaseForCreateChoosesStableOrAnyRelease(t *testing.T) {
 		},
 	}
 
-	gotStable := previousReleaseForCreate(client, "NethServer/ns8-mail", false)
+	gotStable := previousReleaseForCreate(client, "NethServer/ns8-mail", "main", false)
 	if gotStable != "1.2.3" {
 		t.Fatalf("previousReleaseForCreate() stable = %q, want %q", gotStable, "1.2.3")
 	}
 
-	gotPrerelease := previousReleaseForCreate(client, "NethServer/ns8-mail", true)
+	gotPrerelease := previousReleaseForCreate(client, "NethServer/ns8-mail", "main", true)
 	if gotPrerelease != "1.2.4-testing.1" {
 		t.Fatalf("previousReleaseForCreate() prerelease = %q, want %q", gotPrerelease, "1.2.4-testing.1")
 	}
@@ -256,78 +284,144 @@ func TestPreviousReleaseForCreateChoosesStableOrAnyRelease(t *testing.T) {
 }
 
 func TestPreviousReleaseForCreateReturnsEmptyOnLookupError(t *testing.T) {
-	got := previousReleaseForCreate(&fakeCreateReleaseFlowClient{listErr: errors.New("boom")}, "NethServer/ns8-mail", false)
+	got := previousReleaseForCreate(&fakeCreateReleaseFlowClient{listErr: errors.New("boom")}, "NethServer/ns8-mail", "main", false)
 	if got != "" {
 		t.Fatalf("previousReleaseForCreate() = %q, want empty string", got)
 	}
 }
 
-func TestLinkedIssuesNotesReaderReturnsNotesWhenEnabled(t *testing.T) {
+func TestLinkedIssuesListReturnsNilWhenNotesAreEmpty(t *testing.T) {
 	client := fakeLinkedIssuesNotesClient{
-		comparison: makeCommandCompareResult("commit-a"),
-		commitPRs: map[string][]int{
-			"commit-a": {1},
-		},
 		prs: map[int]*ghgithub.PullRequest{
-			1: {Body: "Refs NethServer/dev#10"},
-		},
-		issues: map[int]*ghgithub.Issue{
-			10: {Title: "Issue title"},
+			1: {Body: "No linked issues here"},
 		},
 	}
 
-	reader := linkedIssuesNotesReader(client, "NethServer/ns8-mail", "1.2.3", "NethServer/dev", true)
-	if reader == nil {
-		t.Fatal("linkedIssuesNotesReader() = nil, want reader")
+	if got := linkedIssuesList(client, "NethServer/ns8-mail", "NethServer/dev", []int{1}); got != "" {
+		t.Fatalf("linkedIssuesList() = %q, want empty when there are no linked issues", got)
 	}
+}
 
-	got, err := io.ReadAll(reader)
+func TestScanReleasePRsSkipsUnneededScans(t *testing.T) {
+	client := newRangeNotesTestClient()
+	client.compareErr = errors.New("boom")
+
+	if got, err := scanReleasePRs(client, internalconfig.Default(), "NethServer/ns8-mail", "", "main", true, true, true); got != nil || err != nil {
+		t.Fatalf("scanReleasePRs() = %v, %v, want no scan without previous release", got, err)
+	}
+	if got, err := scanReleasePRs(client, internalconfig.Default(), "NethServer/ns8-mail", "1.2.3", "main", false, false, false); got != nil || err != nil {
+		t.Fatalf("scanReleasePRs() = %v, %v, want no scan for the GitHub notes alone", got, err)
+	}
+}
+
+func TestScanReleasePRsCoverTheTargetRange(t *testing.T) {
+	got, err := scanReleasePRs(newRangeNotesTestClient(), internalconfig.Default(), "NethServer/ns8-mail", "1.2.3", "commit-a", false, true, false)
 	if err != nil {
-		t.Fatalf("io.ReadAll() returned error: %v", err)
+		t.Fatalf("scanReleasePRs() returned error: %v", err)
 	}
+	if !reflect.DeepEqual(got, []int{1}) {
+		t.Fatalf("scanReleasePRs() = %v, want only the PRs up to the target commit", got)
+	}
+}
 
-	want := "## Linked Issues\n" +
-		"- [NethServer/dev#10](https://github.com/NethServer/dev/issues/10): Issue title\n"
-	if string(got) != want {
-		t.Fatalf("linkedIssuesNotesReader() = %q, want %q", string(got), want)
+func TestScanReleasePRsFailureAbortsTheProvenance(t *testing.T) {
+	client := newRangeNotesTestClient()
+	client.commitErrs = map[string]error{"commit-b": ghgithub.ErrForbidden}
+
+	// The linked issues of the GitHub notes tolerate the missing PRs
+	got, err := scanReleasePRs(client, internalconfig.Default(), "NethServer/ns8-mail", "1.2.3", "main", false, true, false)
+	if err != nil || !reflect.DeepEqual(got, []int{1}) {
+		t.Fatalf("scanReleasePRs() = %v, %v, want the PRs that could be listed", got, err)
+	}
+
+	_, err = scanReleasePRs(client, internalconfig.Default(), "NethServer/ns8-mail", "1.2.3", "main", false, true, true)
+	if !errors.Is(err, ghgithub.ErrForbidden) || !strings.Contains(err.Error(), "commit-b") {
+		t.Fatalf("scanReleasePRs() error = %v, want the commit-b lookup error", err)
 	}
 }
 
-func TestLinkedIssuesNotesReaderSkipsDisabledOrEmptyRanges(t *testing.T) {
-	client := fakeLinkedIssuesNotesClient{
-		comparison: makeCommandCompareResult("commit-a"),
-		commitPRs: map[string][]int{
-			"commit-a": {1},
-		},
-		prs: map[int]*ghgithub.PullRequest{
-			1: {Body: "Refs NethServer/dev#10"},
-		},
-		issues: map[int]*ghgithub.Issue{
-			10: {Title: "Issue title"},
-		},
+func TestScanReleasePRsOfProvenanceAcceptsRangesWithoutPRs(t *testing.T) {
+	client := newRangeNotesTestClient()
+	client.commitPRs = map[string][]int{}
+
+	got, err := scanReleasePRs(client, internalconfig.Default(), "NethServer/ns8-mail", "1.2.3", "main", false, false, true)
+	if err != nil || len(got) != 0 {
+		t.Fatalf("scanReleasePRs() = %v, %v, want no PRs and no error", got, err)
 	}
 
-	if reader := linkedIssuesNotesReader(client, "NethServer/ns8-mail", "", "NethServer/dev", true); reader != nil {
-		t.Fatalf("linkedIssuesNotesReader() = %v, want nil when previous release is empty", reader)
+	if _, err := scanReleasePRs(client, internalconfig.Default(), "NethServer/ns8-mail", "1.2.3", "main", true, false, false); err == nil {
+		t.Fatal("scanReleasePRs() returned nil error, want the missing PRs of the cumulative notes")
+	}
+}
+
+func TestResolveCreateReleaseNameRejectsDraftTag(t *testing.T) {
+	client := &fakeCreateReleaseFlowClient{
+		drafts: []ghgithub.Release{{DatabaseID: 7, TagName: "1.2.4"}},
 	}
 
-	if reader := linkedIssuesNotesReader(client, "NethServer/ns8-mail", "1.2.3", "NethServer/dev", false); reader != nil {
-		t.Fatalf("linkedIssuesNotesReader() = %v, want nil when notes are disabled", reader)
+	_, _, err := resolveCreateReleaseName(client, "NethServer/ns8-mail", "main", []string{"1.2.4"}, nil, "")
+	if err == nil || err.Error() != "a draft release 1.2.4 already exists: publish it with the publish command" {
+		t.Fatalf("resolveCreateReleaseName() error = %v, want draft release error", err)
 	}
 }
 
-func TestLinkedIssuesNotesReaderReturnsNilWhenNotesAreEmpty(t *testing.T) {
-	client := fakeLinkedIssuesNotesClient{
-		comparison: makeCommandCompareResult("commit-a"),
-		commitPRs: map[string][]int{
-			"commit-a": {1},
-		},
-		prs: map[int]*ghgithub.PullRequest{
-			1: {Body: "No linked issues here"},
-		},
+type fakeSBOMFileClient struct {
+	files map[string]string
+}
+
+func (f *fakeSBOMFileClient) ListFiles(_, _ string) ([]string, error) {
+	paths := make([]string, 0, len(f.files))
+	for path := range f.files {
+		paths = append(paths, path)
+	}
+	return paths, nil
+}
+
+func (f *fakeSBOMFileClient) GetFile(_, path, _ string) (*ghgithub.File, error) {
+	return &ghgithub.File{Path: path, Content: []byte(f.files[path])}, nil
+}
+
+func TestSBOMAssetIsNamedAfterTheRelease(t *testing.T) {
+	client := &fakeSBOMFileClient{files: map[string]string{
+		"go.mod": "module example.com/mail\n\nrequire github.com/spf13/cobra v1.8.0\n",
+	}}
+
+	asset, err := sbomAsset(client, "NethServer/ns8-mail", "1.2.0", "abc123", internalmodule.SBOMCycloneDX)
+	if err != nil {
+		t.Fatalf("sbomAsset() returned error: %v", err)
+	}
+	if asset.Name != "ns8-mail-1.2.0.cdx.json" || !strings.Contains(string(asset.Content), "pkg:golang/github.com/spf13/cobra@v1.8.0") {
+		t.Fatalf("sbomAsset() = %s %s, want the CycloneDX SBOM of ns8-mail 1.2.0", asset.Name, asset.Content)
+	}
+}
+
+func TestValidateReleaseMetadataReportsFailedChecks(t *testing.T) {
+	cfg := internalconfig.Default()
+	cfg.Validation = internalconfig.Validation{
+		Checks: []string{internalconfig.CheckVersionMatch, internalconfig.CheckNoPrerelease},
+		Files:  []internalconfig.MetadataFile{{Path: "ui/package.json", VersionField: "version"}},
+	}
+	client := &fakeSBOMFileClient{files: map[string]string{"ui/package.json": `{"version": "1.1.0"}`}}
+	release := internalmodule.MetadataRelease{Name: "1.2.0", Stages: internalmodule.DefaultPrereleaseStages}
+
+	var out bytes.Buffer
+	err := validateReleaseMetadata(&out, client, cfg, "NethServer/ns8-mail", "abc123", release)
+	if err == nil || !strings.Contains(err.Error(), "1 metadata check(s) failed for release 1.2.0") {
+		t.Fatalf("validateReleaseMetadata() error = %v, want one failed check", err)
+	}
+	want := "🔎 Module metadata of 1.2.0 at commit abc123\n❌ version-match\n   - ui/package.json: version 1.1.0 does not match 1.2.0\n✅ no-prerelease\n"
+	if out.String() != want {
+		t.Fatalf("output = %q, want %q", out.String(), want)
+	}
+
+	client.files["ui/package.json"] = `{"version": "1.2.0"}`
+	out.Reset()
+	if err := validateReleaseMetadata(&out, client, cfg, "NethServer/ns8-mail", "abc123", release); err != nil {
+		t.Fatalf("validateReleaseMetadata() returned error: %v", err)
 	}
 
-	if reader := linkedIssuesNotesReader(client, "NethServer/ns8-mail", "1.2.3", "NethServer/dev", true); reader != nil {
-		t.Fatalf("linkedIssuesNotesReader() = %v, want nil when generated notes are empty", reader)
+	out.Reset()
+	if err := validateReleaseMetadata(&out, client, internalconfig.Default(), "NethServer/ns8-mail", "abc123", release); err != nil || out.Len() != 0 {
+		t.Fatalf("validateReleaseMetadata() = %q, %v, want no output without checks", out.String(), err)
 	}
 }
@@ -0,0 +1,65 @@
+package module_release
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/NethServer/gh-ns8/internal/github"
+	"github.com/spf13/cobra"
+)
+
+// Token permissions required by each command, as documented in the README
+// "Minimum PAT Permissions" section
+const (
+	readPermission  = "no additional scope for public repositories and the `repo` scope for private repositories"
+	writePermission = "the `public_repo` scope for public repositories or the `repo` scope for private repositories"
+)
+
+// withErrorHints wraps a RunE function so GitHub API failures are followed by
+// a hint on how to fix them. permission is the token permission the command
+// requires.
+func withErrorHints(permission string, run func(*cobra.Command, []string) error) func(*cobra.Command, []string) error {
+	return func(cmd *cobra.Command, args []string) error {
+		return explainError(run(cmd, args), cmd.Name(), permission)
+	}
+}
+
+// explainError appends an actionable hint to typed GitHub errors
+func explainError(err error, command, permission string) error {
+	if err == nil {
+		return nil
+	}
+
+	hint := errorHint(err, command, permission)
+	if hint == "" {
+		return err
+	}
+	return fmt.Errorf("%w\n%s", err, hint)
+}
+
+func errorHint(err error, command, permission string) string {
+	switch {
+	case errors.Is(err, github.ErrUnauthorized):
+		return "GitHub rejected the credentials: run `gh auth login` or set a valid GH_TOKEN."
+	case errors.Is(err, github.ErrRateLimited):
+		return "The GitHub API rate limit is exhausted: wait for the reset and run the command again."
+	case errors.Is(err, github.ErrForbidden):
+		hint := fmt.Sprintf("The token is not allowed to perform this operation: the %s command requires %s (see \"Minimum PAT Permissions\" in the README).", command, permission)
+		var apiErr *github.APIError
+		if errors.As(err, &apiErr) {
+			if missing := apiErr.MissingScopes(); len(missing) > 0 {
+				hint += fmt.Sprintf(" Missing scope: %s.", strings.Join(missing, " or "))
+			}
+		}
+		return hint
+	case errors.Is(err, github.ErrNotFound):
+		return "Check the repository and release names: private repositories are reported as not found when the token lacks the `repo` scope."
+	case errors.Is(err, github.ErrConflict):
+		return "The request conflicts with the current repository state: refresh and run the command again."
+	case errors.Is(err, github.ErrValidationFailed):
+		return "GitHub rejected the request as invalid, for example because the release tag already exists."
+	default:
+		return ""
+	}
+}
@@ -0,0 +1,78 @@
+package module_release
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	ghgithub "github.com/NethServer/gh-ns8/internal/github"
+	"github.com/spf13/cobra"
+)
+
+func TestExplainErrorAddsHintsForTypedErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want string
+	}{
+		{
+			name: "unauthorized",
+			err:  fmt.Errorf("failed to list releases: %w", ghgithub.ErrUnauthorized),
+			want: "run `gh auth login`",
+		},
+		{
+			name: "forbidden",
+			err:  fmt.Errorf("failed to create release: %w", ghgithub.ErrForbidden),
+			want: "the create command requires " + writePermission,
+		},
+		{
+			name: "rate limited",
+			err:  ghgithub.ErrRateLimited,
+			want: "rate limit is exhausted",
+		},
+		{
+			name: "not found",
+			err:  ghgithub.ErrNotFound,
+			want: "lacks the `repo` scope",
+		},
+		{
+			name: "validation failed",
+			err:  ghgithub.ErrValidationFailed,
+			want: "release tag already exists",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := explainError(tt.err, "create", writePermission)
+			if !errors.Is(got, tt.err) {
+				t.Fatalf("explainError() = %v, want wrapped original error", got)
+			}
+			if !strings.HasPrefix(got.Error(), tt.err.Error()+"\n") || !strings.Contains(got.Error(), tt.want) {
+				t.Fatalf("explainError() = %q, want hint containing %q", got.Error(), tt.want)
+			}
+		})
+	}
+}
+
+func TestExplainErrorKeepsOtherErrors(t *testing.T) {
+	err := errors.New("please provide the release name as an argument")
+	if got := explainError(err, "create", writePermission); got != err {
+		t.Fatalf("explainError() = %v, want original error", got)
+	}
+	if got := explainError(nil, "create", writePermission); got != nil {
+		t.Fatalf("explainError(nil) = %v, want nil", got)
+	}
+}
+
+func TestWithErrorHintsUsesCommandName(t *testing.T) {
+	run := withErrorHints(readPermission, func(*cobra.Command, []string) error {
+		return ghgithub.ErrForbidden
+	})
+
+	err := run(&cobra.Command{Use: "check"}, nil)
+	if err == nil || !strings.Contains(err.Error(), "the check command requires "+readPermission) {
+		t.Fatalf("withErrorHints() error = %v, want check command permission hint", err)
+	}
+}
@@ -1,7 +1,11 @@
 package module_release
 
 import (
+	"fmt"
+
 	"github.com/NethServer/gh-ns8/cmd"
+	"github.com/NethServer/gh-ns8/internal/github"
+	"github.com/NethServer/gh-ns8/internal/module_release"
 	"github.com/spf13/cobra"
 )
 
@@ -9,6 +13,8 @@ var (
 	// Shared flags
 	repoFlag       string
 	issuesRepoFlag string
+	branchFlag     string
+	lineFlag       string
 )
 
 // moduleReleaseCmd represents the module-release command
@@ -23,7 +29,9 @@ func init() {
 
 	// Persistent flags for all subcommands
 	moduleReleaseCmd.PersistentFlags().StringVar(&repoFlag, "repo", "", "The GitHub NethServer 8 module repository (e.g., owner/ns8-module)")
-	moduleReleaseCmd.PersistentFlags().StringVar(&issuesRepoFlag, "issues-repo", "NethServer/dev", "Issues repository (default: NethServer/dev)")
+	moduleReleaseCmd.PersistentFlags().StringVar(&issuesRepoFlag, "issues-repo", "NethServer/dev", "Issues repository (overrides issues_repo in the configuration)")
+	moduleReleaseCmd.PersistentFlags().StringVar(&lineFlag, "line", "", "Only consider releases of a MAJOR.MINOR version line, e.g. 1.4 for hotfix releases")
+	moduleReleaseCmd.PersistentFlags().StringVar(&branchFlag, "branch", module_release.DefaultBranch, "The branch releases are made from, e.g. a stable-1.4 maintenance branch (overrides branch in the configuration)")
 
 	// Register custom completion for repo flag
 	moduleReleaseCmd.RegisterFlagCompletionFunc("repo", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
@@ -36,4 +44,40 @@ func init() {
 	moduleReleaseCmd.AddCommand(checkCmd)
 	moduleReleaseCmd.AddCommand(commentCmd)
 	moduleReleaseCmd.AddCommand(cleanCmd)
+	moduleReleaseCmd.AddCommand(notesCmd)
+	moduleReleaseCmd.AddCommand(changelogCmd)
+	moduleReleaseCmd.AddCommand(publishCmd)
+	moduleReleaseCmd.AddCommand(verifyAssetsCmd)
+	moduleReleaseCmd.AddCommand(verifyCmd)
+}
+
+// commandClient is the GitHub client of the module-release commands. When
+// --line is set its release listings only include that version line, so the
+// latest, next and previous releases are all resolved within the line.
+type commandClient struct {
+	*github.Client
+	line *module_release.VersionLine
+}
+
+// ListReleases lists the releases of the version line
+func (c *commandClient) ListReleases(repo string, limit int, excludePreReleases bool) ([]github.Release, error) {
+	return module_release.ListLineReleases(c.Client, repo, c.line, limit, excludePreReleases)
+}
+
+func newCommandClient() (*commandClient, error) {
+	var line *module_release.VersionLine
+	if lineFlag != "" {
+		parsed, err := module_release.ParseVersionLine(lineFlag)
+		if err != nil {
+			return nil, err
+		}
+		line = parsed
+	}
+
+	client, err := github.NewClient()
+	if err != nil {
+		return nil, fmt.Errorf("failed to create GitHub client: %w", err)
+	}
+
+	return &commandClient{Client: client, line: line}, nil
 }
@@ -1,6 +1,7 @@
 package module_release
 
 import (
+	"strings"
 	"testing"
 
 	"github.com/spf13/cobra"
@@ -27,11 +28,26 @@ func TestModuleReleaseCommandConfiguration(t *testing.T) {
 		t.Fatalf("issues-repo flag default = %q, want %q", issuesRepoFlag.DefValue, "NethServer/dev")
 	}
 
+	branchFlag := moduleReleaseCmd.PersistentFlags().Lookup("branch")
+	if branchFlag == nil || branchFlag.DefValue != "main" {
+		t.Fatalf("branch flag = %v, want default main", branchFlag)
+	}
+
+	lineFlag := moduleReleaseCmd.PersistentFlags().Lookup("line")
+	if lineFlag == nil || lineFlag.DefValue != "" {
+		t.Fatalf("line flag = %v, want empty default", lineFlag)
+	}
+
 	testCases := map[string]*cobra.Command{
-		"create":  createCmd,
-		"check":   checkCmd,
-		"comment": commentCmd,
-		"clean":   cleanCmd,
+		"create":        createCmd,
+		"check":         checkCmd,
+		"comment":       commentCmd,
+		"clean":         cleanCmd,
+		"notes":         notesCmd,
+		"changelog":     changelogCmd,
+		"publish":       publishCmd,
+		"verify-assets": verifyAssetsCmd,
+		"verify":        verifyCmd,
 	}
 	for name, want := range testCases {
 		got, _, err := moduleReleaseCmd.Find([]string{name})
@@ -58,3 +74,16 @@ func TestModuleReleaseRepoCompletion(t *testing.T) {
 		t.Fatalf("repo completion suggestions = %v, want [owner/ns8-module]", suggestions)
 	}
 }
+
+func TestNewCommandClientRejectsInvalidLine(t *testing.T) {
+	originalLine := lineFlag
+	lineFlag = "1.4.2"
+	defer func() {
+		lineFlag = originalLine
+	}()
+
+	_, err := newCommandClient()
+	if err == nil || !strings.Contains(err.Error(), "invalid version line: 1.4.2") {
+		t.Fatalf("newCommandClient() error = %v, want invalid version line", err)
+	}
+}
@@ -0,0 +1,294 @@
+package module_release
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/NethServer/gh-ns8/internal/config"
+	"github.com/NethServer/gh-ns8/internal/github"
+	"github.com/NethServer/gh-ns8/internal/module_release"
+	"github.com/spf13/cobra"
+)
+
+var notesUpdateFlag string
+
+// notesCmd represents the notes command
+var notesCmd = &cobra.Command{
+	Use:   "notes FROM [TO] | notes --update TAG",
+	Short: "Render or update release notes",
+	Long: `Render the release notes of the commits between two refs (a release tag, branch or commit SHA) without creating anything.
+TO defaults to the head of the configured branch. With the github notes source only the linked issues are rendered, as GitHub generates the rest when the release is created.
+
+With --update, regenerate the notes of an existing release since its previous release, replacing the section managed by the extension and keeping the text written by hand. The cumulative notes of a stable release are regenerated from its pre-releases, as long as they exist.`,
+	Args: func(cmd *cobra.Command, args []string) error {
+		if notesUpdateFlag != "" {
+			return cobra.NoArgs(cmd, args)
+		}
+		return cobra.RangeArgs(1, 2)(cmd, args)
+	},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		// Updating a release requires write access
+		if notesUpdateFlag != "" {
+			return withErrorHints(writePermission, runNotesUpdate)(cmd, args)
+		}
+		return withErrorHints(readPermission, runNotes)(cmd, args)
+	},
+}
+
+func init() {
+	notesCmd.Flags().StringVar(&notesFlag, "notes", config.NotesGitHub, "Release notes: github, native or both (overrides notes.source)")
+	notesCmd.Flags().StringVar(&notesUpdateFlag, "update", "", "Regenerate the notes of an existing release")
+}
+
+type releaseNotesClient interface {
+	linkedIssuesNotesClient
+	GetParentIssueNumber(repo string, issueNumber int) (int, error)
+}
+
+// renderReleaseNotes renders the native release notes of the base...head
+// range with the configured template
+func renderReleaseNotes(client releaseNotesClient, cfg *config.Config, repo, base, head string) (string, error) {
+	prNumbers, err := module_release.ScanForPRs(client, repo, base, head)
+	if err != nil {
+		return "", err
+	}
+	return renderPRNotes(client, cfg, repo, prNumbers)
+}
+
+// renderPRNotes renders the native release notes of the PRs with the
+// configured template
+func renderPRNotes(client releaseNotesClient, cfg *config.Config, repo string, prNumbers []int) (string, error) {
+	classifier, err := module_release.NewPRClassifier(cfg.Categories)
+	if err != nil {
+		return "", err
+	}
+
+	notes := module_release.BuildReleaseNotes(client, classifier, repo, cfg.IssuesRepo, prNumbers)
+	return notes.Render(cfg.Notes.Template)
+}
+
+// createReleaseNotes returns the body of a new release and whether GitHub
+// should append its generated notes, as set by notes.source. prNumbers are
+// the PRs since previousRelease. The first release of a branch, without a
+// previous release, only has generated notes.
+func createReleaseNotes(client releaseNotesClient, cfg *config.Config, repo, previousRelease string, prNumbers []int, withLinkedIssues bool) (string, bool, error) {
+	if previousRelease == "" {
+		return "", true, nil
+	}
+
+	switch cfg.Notes.Source {
+	case config.NotesNative, config.NotesBoth:
+		notes, err := renderPRNotes(client, cfg, repo, prNumbers)
+		if err != nil {
+			return "", false, err
+		}
+		return notes, cfg.Notes.Source == config.NotesBoth, nil
+	default:
+		if !withLinkedIssues {
+			return "", true, nil
+		}
+		return linkedIssuesList(client, repo, cfg.IssuesRepo, prNumbers), true, nil
+	}
+}
+
+type cumulativeNotesClient interface {
+	releaseNotesClient
+	ListReleases(repo string, limit int, excludePreReleases bool) ([]github.Release, error)
+	GetReleaseNotes(repo, tag string) (string, error)
+}
+
+// cumulativePreReleases returns the pre-releases of the version line of
+// releaseName created since previousRelease, oldest first. endTag is
+// releaseName for an existing release, empty for a new one.
+func cumulativePreReleases(client cumulativeNotesClient, repo, releaseName, previousRelease, endTag string) ([]string, error) {
+	if previousRelease == "" {
+		return nil, nil
+	}
+
+	// Pre-releases of other lines, e.g. of a maintenance branch, are not
+	// part of the release
+	line, err := module_release.VersionLineOf(releaseName)
+	if err != nil {
+		return nil, err
+	}
+	allPreReleases, err := module_release.GetPreReleasesBetween(client, repo, previousRelease, endTag)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get pre-releases: %w", err)
+	}
+	// Pre-releases are listed newest first
+	var preReleases []string
+	for i := len(allPreReleases) - 1; i >= 0; i-- {
+		if line.Contains(allPreReleases[i]) {
+			preReleases = append(preReleases, allPreReleases[i])
+		}
+	}
+	return preReleases, nil
+}
+
+// cumulativeReleaseNotes returns the body of a stable release aggregating
+// the notes of preReleases, created since previousRelease, the previous
+// stable release, and whether GitHub should append its generated notes.
+// prNumbers are the PRs since previousRelease. GitHub notes only cover the
+// changes since the latest pre-release, so they are appended with the both
+// source only.
+func cumulativeReleaseNotes(client cumulativeNotesClient, cfg *config.Config, repo, previousRelease string, preReleases []string, prNumbers []int) (string, bool, error) {
+	if previousRelease == "" {
+		return "", true, nil
+	}
+
+	classifier, err := module_release.NewPRClassifier(cfg.Categories)
+	if err != nil {
+		return "", false, err
+	}
+
+	notes, err := module_release.BuildCumulativeReleaseNotes(client, classifier, repo, cfg.IssuesRepo, previousRelease, preReleases, prNumbers)
+	if err != nil {
+		return "", false, err
+	}
+	body, err := notes.Render(cfg.Notes.Template)
+	if err != nil {
+		return "", false, err
+	}
+	return module_release.CumulativeNotesMarker + "\n" + body, cfg.Notes.Source == config.NotesBoth, nil
+}
+
+func runNotes(cmd *cobra.Command, args []string) error {
+	// Create GitHub client
+	client, err := newCommandClient()
+	if err != nil {
+		return err
+	}
+
+	// Get and validate repository
+	repo, err := module_release.GetOrValidateRepo(client, repoFlag)
+	if err != nil {
+		return err
+	}
+
+	cfg, err := config.FromContext(cmd.Context())
+	if err != nil {
+		return err
+	}
+
+	from := args[0]
+	to := cfg.Branch
+	if len(args) > 1 {
+		to = args[1]
+	}
+
+	return printRangeNotes(cmd.OutOrStdout(), cmd.ErrOrStderr(), client, cfg, repo, from, to)
+}
+
+// rangeReleaseNotes returns the notes of the from...to range as set by
+// notes.source: the native notes, or the linked issues list for the github
+// source
+func rangeReleaseNotes(client releaseNotesClient, cfg *config.Config, repo, from, to string) (string, error) {
+	if cfg.Notes.Source == config.NotesGitHub {
+		return generateLinkedIssuesNotes(client, repo, from, to, cfg.IssuesRepo)
+	}
+	return renderReleaseNotes(client, cfg, repo, from, to)
+}
+
+func printRangeNotes(out, errWriter io.Writer, client releaseNotesClient, cfg *config.Config, repo, from, to string) error {
+	notes, err := rangeReleaseNotes(client, cfg, repo, from, to)
+	if err != nil {
+		return fmt.Errorf("failed to generate release notes: %w", err)
+	}
+
+	if notes == "" {
+		fmt.Fprintf(errWriter, "No release notes for %s...%s\n", from, to)
+		return nil
+	}
+
+	fmt.Fprint(out, notes)
+	return nil
+}
+
+type releaseNotesUpdateClient interface {
+	releaseNotesClient
+	ListReleases(repo string, limit int, excludePreReleases bool) ([]github.Release, error)
+	ViewRelease(repo, tag string) (*github.Release, error)
+	GetReleaseNotes(repo, tag string) (string, error)
+	UpdateRelease(repo, tag string, update github.ReleaseUpdate) error
+}
+
+// updatedCumulativeNotes regenerates the cumulative notes of the stable
+// release tag. Once its pre-releases are deleted, e.g. by clean, the notes
+// would lose their pre-releases, so they are not regenerated.
+func updatedCumulativeNotes(client releaseNotesUpdateClient, cfg *config.Config, repo, tag, previousRelease string) (string, error) {
+	preReleases, err := cumulativePreReleases(client, repo, tag, previousRelease, tag)
+	if err != nil {
+		return "", err
+	}
+	if len(preReleases) == 0 {
+		return "", fmt.Errorf("the cumulative notes of %s cannot be regenerated: its pre-releases no longer exist", tag)
+	}
+
+	prNumbers, err := module_release.ScanForPRs(client, repo, previousRelease, tag)
+	if err != nil {
+		return "", err
+	}
+	notes, _, err := cumulativeReleaseNotes(client, cfg, repo, previousRelease, preReleases, prNumbers)
+	return notes, err
+}
+
+func runNotesUpdate(cmd *cobra.Command, args []string) error {
+	// Create GitHub client
+	client, err := newCommandClient()
+	if err != nil {
+		return err
+	}
+
+	// Get and validate repository
+	repo, err := module_release.GetOrValidateRepo(client, repoFlag)
+	if err != nil {
+		return err
+	}
+
+	cfg, err := config.FromContext(cmd.Context())
+	if err != nil {
+		return err
+	}
+
+	return updateReleaseNotes(cmd.OutOrStdout(), client, cfg, repo, notesUpdateFlag)
+}
+
+// updateReleaseNotes regenerates the managed notes of a release from its
+// previous release, as create does. Cumulative notes are regenerated from
+// the pre-releases, which must still exist.
+func updateReleaseNotes(out io.Writer, client releaseNotesUpdateClient, cfg *config.Config, repo, tag string) error {
+	previousRelease, err := module_release.FindPreviousRelease(client, repo, tag)
+	if err != nil {
+		return fmt.Errorf("failed to find previous release: %w", err)
+	}
+
+	body, err := client.GetReleaseNotes(repo, tag)
+	if err != nil {
+		return err
+	}
+
+	var notes string
+	if module_release.IsCumulativeNotes(body) {
+		notes, err = updatedCumulativeNotes(client, cfg, repo, tag, previousRelease)
+	} else {
+		notes, err = rangeReleaseNotes(client, cfg, repo, previousRelease, tag)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to generate release notes: %w", err)
+	}
+
+	updated, hadManaged := module_release.ReplaceManagedNotes(body, notes)
+	if updated == body {
+		fmt.Fprintf(out, "✅ The notes of %s are up to date\n", tag)
+		return nil
+	}
+
+	if err := client.UpdateRelease(repo, tag, github.ReleaseUpdate{Notes: &updated}); err != nil {
+		return err
+	}
+	if !hadManaged {
+		fmt.Fprintf(out, "ℹ️  The notes of %s had no managed section, the notes were added at the top\n", tag)
+	}
+	fmt.Fprintf(out, "✅ The notes of %s were updated since %s\n", tag, previousRelease)
+	return nil
+}
@@ -0,0 +1,415 @@
+package module_release
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	internalconfig "github.com/NethServer/gh-ns8/internal/config"
+	ghgithub "github.com/NethServer/gh-ns8/internal/github"
+	internalmodule "github.com/NethServer/gh-ns8/internal/module_release"
+)
+
+func newNotesTestClient() fakeLinkedIssuesNotesClient {
+	pr := makeTestPullRequest(1, "Refs NethServer/dev#10", "alice", "closed", true)
+	pr.Title = "Fix redirect"
+	return fakeLinkedIssuesNotesClient{
+		comparison: makeCommandCompareResult("commit-a"),
+		commitPRs: map[string][]int{
+			"commit-a": {1},
+		},
+		prs: map[int]*ghgithub.PullRequest{
+			1: pr,
+		},
+		issues: map[int]*ghgithub.Issue{
+			10: {Title: "Fix login loop"},
+		},
+	}
+}
+
+func TestCreateReleaseNotesFollowsNotesSource(t *testing.T) {
+	native := "## Features and fixes\n\n" +
+		"- [NethServer/dev#10](https://github.com/NethServer/dev/issues/10) Fix login loop\n" +
+		"  - Fix redirect by @alice in https://github.com/NethServer/ns8-mail/pull/1\n\n" +
+		"## Contributors\n\n" +
+		"- @alice\n"
+	linked := "## Linked Issues\n" +
+		"- [NethServer/dev#10](https://github.com/NethServer/dev/issues/10): Fix login loop\n"
+
+	tests := []struct {
+		source           string
+		withLinkedIssues bool
+		wantNotes        string
+		wantGenerate     bool
+	}{
+		{source: internalconfig.NotesGitHub, wantNotes: "", wantGenerate: true},
+		{source: internalconfig.NotesGitHub, withLinkedIssues: true, wantNotes: linked, wantGenerate: true},
+		{source: internalconfig.NotesNative, wantNotes: native, wantGenerate: false},
+		{source: internalconfig.NotesBoth, wantNotes: native, wantGenerate: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.source, func(t *testing.T) {
+			cfg := internalconfig.Default()
+			cfg.Notes.Source = tt.source
+
+			notes, generate, err := createReleaseNotes(newNotesTestClient(), cfg, "NethServer/ns8-mail", "1.2.3", []int{1}, tt.withLinkedIssues)
+			if err != nil {
+				t.Fatalf("createReleaseNotes() returned error: %v", err)
+			}
+			if notes != tt.wantNotes || generate != tt.wantGenerate {
+				t.Fatalf("createReleaseNotes() = %q, %v, want %q, %v", notes, generate, tt.wantNotes, tt.wantGenerate)
+			}
+		})
+	}
+}
+
+func TestCreateReleaseNotesOfFirstReleaseAreGenerated(t *testing.T) {
+	cfg := internalconfig.Default()
+	cfg.Notes.Source = internalconfig.NotesNative
+
+	notes, generate, err := createReleaseNotes(newNotesTestClient(), cfg, "NethServer/ns8-mail", "", nil, false)
+	if err != nil || notes != "" || !generate {
+		t.Fatalf("createReleaseNotes() = %q, %v, %v, want only generated notes", notes, generate, err)
+	}
+}
+
+func TestRenderReleaseNotesUsesConfiguredTemplate(t *testing.T) {
+	cfg := internalconfig.Default()
+	cfg.Notes.Template = "{{range .Issues}}{{.Ref}} {{end}}"
+
+	got, err := renderReleaseNotes(newNotesTestClient(), cfg, "NethServer/ns8-mail", "1.2.3", "main")
+	if err != nil {
+		t.Fatalf("renderReleaseNotes() returned error: %v", err)
+	}
+	if strings.TrimSpace(got) != "NethServer/dev#10" {
+		t.Fatalf("renderReleaseNotes() = %q, want the configured template output", got)
+	}
+}
+
+func newRangeNotesTestClient() fakeLinkedIssuesNotesClient {
+	client := newNotesTestClient()
+	later := makeTestPullRequest(2, "Refs NethServer/dev#11", "bob", "closed", true)
+	later.Title = "Add phone model"
+	client.prs[2] = later
+	client.issues[11] = &ghgithub.Issue{Title: "Phone support"}
+	client.commitPRs["commit-b"] = []int{2}
+	client.comparisons = map[string]*ghgithub.CompareResult{
+		"1.2.3...commit-a": makeCommandCompareResult("commit-a"),
+		"1.2.3...main":     makeCommandCompareResult("commit-a", "commit-b"),
+	}
+	return client
+}
+
+func TestPrintRangeNotesFollowsNotesSource(t *testing.T) {
+	tests := []struct {
+		source string
+		want   []string
+	}{
+		{source: internalconfig.NotesGitHub, want: []string{"## Linked Issues", "NethServer/dev#10", "NethServer/dev#11"}},
+		{source: internalconfig.NotesNative, want: []string{"## Features and fixes", "Add phone model by @bob", "## Contributors"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.source, func(t *testing.T) {
+			cfg := internalconfig.Default()
+			cfg.Notes.Source = tt.source
+
+			var out, errOut strings.Builder
+			if err := printRangeNotes(&out, &errOut, newRangeNotesTestClient(), cfg, "NethServer/ns8-mail", "1.2.3", "main"); err != nil {
+				t.Fatalf("printRangeNotes() returned error: %v", err)
+			}
+			for _, want := range tt.want {
+				if !strings.Contains(out.String(), want) {
+					t.Fatalf("printRangeNotes() output = %q, want it to contain %q", out.String(), want)
+				}
+			}
+			if errOut.Len() != 0 {
+				t.Fatalf("printRangeNotes() errors = %q, want none", errOut.String())
+			}
+		})
+	}
+}
+
+func TestPrintRangeNotesReportsMissingLinkedIssues(t *testing.T) {
+	client := newRangeNotesTestClient()
+	client.prs[3] = makeTestPullRequest(3, "", "carol", "closed", true)
+	client.commitPRs["commit-c"] = []int{3}
+	client.comparisons["1.2.3...commit-c"] = makeCommandCompareResult("commit-c")
+
+	var out, errOut strings.Builder
+	if err := printRangeNotes(&out, &errOut, client, internalconfig.Default(), "NethServer/ns8-mail", "1.2.3", "commit-c"); err != nil {
+		t.Fatalf("printRangeNotes() returned error: %v", err)
+	}
+	if out.Len() != 0 || errOut.String() != "No release notes for 1.2.3...commit-c\n" {
+		t.Fatalf("printRangeNotes() = %q, %q, want the empty range message", out.String(), errOut.String())
+	}
+}
+
+func TestPrintRangeNotesReturnsScanError(t *testing.T) {
+	client := newRangeNotesTestClient()
+	client.compareErr = errors.New("not found")
+
+	var out, errOut strings.Builder
+	err := printRangeNotes(&out, &errOut, client, internalconfig.Default(), "NethServer/ns8-mail", "missing", "main")
+	if err == nil || !strings.Contains(err.Error(), "failed to generate release notes") {
+		t.Fatalf("printRangeNotes() error = %v, want the wrapped scan error", err)
+	}
+}
+
+type fakeCumulativeNotesClient struct {
+	fakeLinkedIssuesNotesClient
+	releases []ghgithub.Release
+	bodies   map[string]string
+}
+
+func (f fakeCumulativeNotesClient) ListReleases(_ string, _ int, _ bool) ([]ghgithub.Release, error) {
+	return f.releases, nil
+}
+
+func (f fakeCumulativeNotesClient) GetReleaseNotes(_ string, tag string) (string, error) {
+	return f.bodies[tag], nil
+}
+
+func newCumulativeNotesTestClient() fakeCumulativeNotesClient {
+	client := newRangeNotesTestClient()
+	client.comparisons["1.2.3...1.3.0-testing.1"] = makeCommandCompareResult("commit-a")
+	client.comparisons["1.3.0-testing.1...1.3.0-testing.2"] = makeCommandCompareResult("commit-b")
+	client.comparisons["1.2.3...commit-b"] = makeCommandCompareResult("commit-a", "commit-b")
+	return fakeCumulativeNotesClient{
+		fakeLinkedIssuesNotesClient: client,
+		releases: []ghgithub.Release{
+			{TagName: "1.3.0-testing.2", IsPrerelease: true, CreatedAt: "2024-01-08T00:00:00Z"},
+			{TagName: "1.2.4-testing.1", IsPrerelease: true, CreatedAt: "2024-01-06T00:00:00Z"},
+			{TagName: "1.3.0-testing.1", IsPrerelease: true, CreatedAt: "2024-01-05T00:00:00Z"},
+			{TagName: "1.2.3", CreatedAt: "2024-01-01T00:00:00Z"},
+		},
+		bodies: map[string]string{
+			"1.3.0-testing.1": "Please test the new phone support.\n\n" + internalmodule.ManagedNotes("## Linked Issues\n- stale"),
+		},
+	}
+}
+
+func TestCumulativePreReleasesStayInTheVersionLine(t *testing.T) {
+	got, err := cumulativePreReleases(newCumulativeNotesTestClient(), "NethServer/ns8-mail", "1.3.0", "1.2.3", "")
+	if err != nil {
+		t.Fatalf("cumulativePreReleases() returned error: %v", err)
+	}
+	if want := []string{"1.3.0-testing.1", "1.3.0-testing.2"}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("cumulativePreReleases() = %v, want %v", got, want)
+	}
+
+	if got, err := cumulativePreReleases(newCumulativeNotesTestClient(), "NethServer/ns8-mail", "1.3.0", "", ""); got != nil || err != nil {
+		t.Fatalf("cumulativePreReleases() = %v, %v, want none without previous release", got, err)
+	}
+}
+
+func TestCumulativeReleaseNotesAggregatePreReleases(t *testing.T) {
+	for _, tt := range []struct {
+		source       string
+		wantGenerate bool
+	}{
+		{source: internalconfig.NotesGitHub, wantGenerate: false},
+		{source: internalconfig.NotesBoth, wantGenerate: true},
+	} {
+		t.Run(tt.source, func(t *testing.T) {
+			cfg := internalconfig.Default()
+			cfg.Notes.Source = tt.source
+
+			notes, generate, err := cumulativeReleaseNotes(newCumulativeNotesTestClient(), cfg, "NethServer/ns8-mail", "1.2.3", []string{"1.3.0-testing.1", "1.3.0-testing.2"}, []int{1, 2})
+			if err != nil {
+				t.Fatalf("cumulativeReleaseNotes() returned error: %v", err)
+			}
+			if generate != tt.wantGenerate {
+				t.Fatalf("cumulativeReleaseNotes() generate = %v, want %v", generate, tt.wantGenerate)
+			}
+			if !strings.HasPrefix(notes, internalmodule.CumulativeNotesMarker+"\n") {
+				t.Fatalf("cumulativeReleaseNotes() =\n%s\nwant the cumulative notes marker first", notes)
+			}
+			for _, want := range []string{
+				"Fix redirect by @alice in https://github.com/NethServer/ns8-mail/pull/1 (1.3.0-testing.1)",
+				"Add phone model by @bob in https://github.com/NethServer/ns8-mail/pull/2 (1.3.0-testing.2)",
+				"This release includes the changes of 1.3.0-testing.1, 1.3.0-testing.2.\n\n### 1.3.0-testing.1\n\nPlease test the new phone support.\n",
+			} {
+				if !strings.Contains(notes, want) {
+					t.Fatalf("cumulativeReleaseNotes() =\n%s\nwant it to contain %q", notes, want)
+				}
+			}
+		})
+	}
+}
+
+func TestCumulativeReleaseNotesOfFirstReleaseAreGenerated(t *testing.T) {
+	notes, generate, err := cumulativeReleaseNotes(newCumulativeNotesTestClient(), internalconfig.Default(), "NethServer/ns8-mail", "", nil, nil)
+	if err != nil || notes != "" || !generate {
+		t.Fatalf("cumulativeReleaseNotes() = %q, %v, %v, want only generated notes", notes, generate, err)
+	}
+}
+
+func TestCumulativePreReleasesRequirePreviousReleaseInList(t *testing.T) {
+	_, err := cumulativePreReleases(newCumulativeNotesTestClient(), "NethServer/ns8-mail", "1.3.0", "1.1.0", "")
+	if err == nil || !strings.Contains(err.Error(), "failed to get pre-releases") {
+		t.Fatalf("cumulativePreReleases() error = %v, want the pre-release lookup error", err)
+	}
+}
+
+type fakeReleaseNotesUpdateClient struct {
+	fakeCumulativeNotesClient
+	updates *[]string
+}
+
+func (f fakeReleaseNotesUpdateClient) ViewRelease(_ string, tag string) (*ghgithub.Release, error) {
+	for _, release := range f.releases {
+		if release.TagName == tag {
+			return &release, nil
+		}
+	}
+	return nil, ghgithub.ErrNotFound
+}
+
+func (f fakeReleaseNotesUpdateClient) UpdateRelease(_ string, tag string, update ghgithub.ReleaseUpdate) error {
+	*f.updates = append(*f.updates, tag+": "+*update.Notes)
+	return nil
+}
+
+func newReleaseNotesUpdateTestClient(body string) fakeReleaseNotesUpdateClient {
+	client := newCumulativeNotesTestClient()
+	client.comparisons["1.2.3...1.3.0-testing.1"] = makeCommandCompareResult("commit-a", "commit-b")
+	client.bodies = map[string]string{"1.3.0-testing.1": body}
+	return fakeReleaseNotesUpdateClient{
+		fakeCumulativeNotesClient: client,
+		updates:                   &[]string{},
+	}
+}
+
+func TestUpdateReleaseNotesReplacesManagedSection(t *testing.T) {
+	body := "Please test the new phone support.\n\n" +
+		internalmodule.NotesStartMarker + "\n## Linked Issues\n- stale\n" + internalmodule.NotesEndMarker +
+		"\n\n## What's Changed\n* Fix redirect\n"
+	client := newReleaseNotesUpdateTestClient(body)
+
+	var out strings.Builder
+	if err := updateReleaseNotes(&out, client, internalconfig.Default(), "NethServer/ns8-mail", "1.3.0-testing.1"); err != nil {
+		t.Fatalf("updateReleaseNotes() returned error: %v", err)
+	}
+
+	want := "1.3.0-testing.1: Please test the new phone support.\n\n" +
+		internalmodule.NotesStartMarker + "\n## Linked Issues\n" +
+		"- [NethServer/dev#10](https://github.com/NethServer/dev/issues/10): Fix login loop\n" +
+		"- [NethServer/dev#11](https://github.com/NethServer/dev/issues/11): Phone support\n" +
+		internalmodule.NotesEndMarker + "\n\n## What's Changed\n* Fix redirect\n"
+	if len(*client.updates) != 1 || (*client.updates)[0] != want {
+		t.Fatalf("updates = %q, want %q", *client.updates, want)
+	}
+	if out.String() != "✅ The notes of 1.3.0-testing.1 were updated since 1.2.3\n" {
+		t.Fatalf("updateReleaseNotes() output = %q", out.String())
+	}
+}
+
+func TestUpdateReleaseNotesAddsMissingSection(t *testing.T) {
+	client := newReleaseNotesUpdateTestClient("## What's Changed\n")
+
+	var out strings.Builder
+	if err := updateReleaseNotes(&out, client, internalconfig.Default(), "NethServer/ns8-mail", "1.3.0-testing.1"); err != nil {
+		t.Fatalf("updateReleaseNotes() returned error: %v", err)
+	}
+	if len(*client.updates) != 1 || !strings.HasPrefix((*client.updates)[0], "1.3.0-testing.1: "+internalmodule.NotesStartMarker) {
+		t.Fatalf("updates = %q, want the managed section prepended", *client.updates)
+	}
+	if !strings.Contains(out.String(), "had no managed section") {
+		t.Fatalf("updateReleaseNotes() output = %q, want the missing section notice", out.String())
+	}
+}
+
+func TestUpdateReleaseNotesSkipsUpToDateNotes(t *testing.T) {
+	notes := "## Linked Issues\n" +
+		"- [NethServer/dev#10](https://github.com/NethServer/dev/issues/10): Fix login loop\n" +
+		"- [NethServer/dev#11](https://github.com/NethServer/dev/issues/11): Phone support\n"
+	client := newReleaseNotesUpdateTestClient(internalmodule.ManagedNotes(notes) + "\n\n## What's Changed\n")
+
+	var out strings.Builder
+	if err := updateReleaseNotes(&out, client, internalconfig.Default(), "NethServer/ns8-mail", "1.3.0-testing.1"); err != nil {
+		t.Fatalf("updateReleaseNotes() returned error: %v", err)
+	}
+	if len(*client.updates) != 0 || out.String() != "✅ The notes of 1.3.0-testing.1 are up to date\n" {
+		t.Fatalf("updateReleaseNotes() = %q, updates %q, want no update", out.String(), *client.updates)
+	}
+}
+
+func newCumulativeUpdateTestClient(body string) fakeReleaseNotesUpdateClient {
+	client := newReleaseNotesUpdateTestClient("")
+	client.releases = append([]ghgithub.Release{{TagName: "1.3.0", CreatedAt: "2024-01-10T00:00:00Z"}}, client.releases...)
+	client.comparisons["1.2.3...1.3.0"] = makeCommandCompareResult("commit-a", "commit-b")
+	client.comparisons["1.2.3...1.3.0-testing.1"] = makeCommandCompareResult("commit-a")
+	client.bodies["1.3.0"] = body
+	return client
+}
+
+func TestUpdateReleaseNotesKeepsCumulativeNotes(t *testing.T) {
+	body := internalmodule.ManagedNotes(internalmodule.CumulativeNotesMarker + "\n## Pre-releases\n\nstale\n")
+	client := newCumulativeUpdateTestClient(body)
+
+	var out strings.Builder
+	if err := updateReleaseNotes(&out, client, internalconfig.Default(), "NethServer/ns8-mail", "1.3.0"); err != nil {
+		t.Fatalf("updateReleaseNotes() returned error: %v", err)
+	}
+	if len(*client.updates) != 1 {
+		t.Fatalf("updates = %q, want one", *client.updates)
+	}
+	for _, want := range []string{
+		internalmodule.NotesStartMarker + "\n" + internalmodule.CumulativeNotesMarker + "\n",
+		"Add phone model by @bob in https://github.com/NethServer/ns8-mail/pull/2 (1.3.0-testing.2)",
+		"This release includes the changes of 1.3.0-testing.1, 1.3.0-testing.2.",
+	} {
+		if !strings.Contains((*client.updates)[0], want) {
+			t.Fatalf("update =\n%s\nwant it to contain %q", (*client.updates)[0], want)
+		}
+	}
+}
+
+func TestUpdateReleaseNotesRefusesCumulativeNotesWithoutPreReleases(t *testing.T) {
+	body := internalmodule.ManagedNotes(internalmodule.CumulativeNotesMarker + "\n## Pre-releases\n")
+	client := newCumulativeUpdateTestClient(body)
+	client.releases = []ghgithub.Release{
+		{TagName: "1.3.0", CreatedAt: "2024-01-10T00:00:00Z"},
+		{TagName: "1.2.3", CreatedAt: "2024-01-01T00:00:00Z"},
+	}
+
+	var out strings.Builder
+	err := updateReleaseNotes(&out, client, internalconfig.Default(), "NethServer/ns8-mail", "1.3.0")
+	if err == nil || !strings.Contains(err.Error(), "its pre-releases no longer exist") || len(*client.updates) != 0 {
+		t.Fatalf("updateReleaseNotes() error = %v, updates %q, want the deleted pre-releases error", err, *client.updates)
+	}
+}
+
+func TestUpdateReleaseNotesRequiresPreviousRelease(t *testing.T) {
+	client := newReleaseNotesUpdateTestClient("")
+
+	var out strings.Builder
+	err := updateReleaseNotes(&out, client, internalconfig.Default(), "NethServer/ns8-mail", "1.2.3")
+	if err == nil || !strings.Contains(err.Error(), "failed to find previous release") {
+		t.Fatalf("updateReleaseNotes() error = %v, want the previous release error", err)
+	}
+}
+
+func TestNotesCommandArgsDependOnUpdate(t *testing.T) {
+	original := notesUpdateFlag
+	defer func() { notesUpdateFlag = original }()
+
+	notesUpdateFlag = ""
+	if err := notesCmd.Args(notesCmd, nil); err == nil {
+		t.Fatal("notes without a range returned nil error")
+	}
+	if err := notesCmd.Args(notesCmd, []string{"1.2.3", "main"}); err != nil {
+		t.Fatalf("notes FROM TO returned error: %v", err)
+	}
+
+	notesUpdateFlag = "1.3.0"
+	if err := notesCmd.Args(notesCmd, []string{"1.2.3"}); err == nil {
+		t.Fatal("notes --update with a range returned nil error")
+	}
+	if err := notesCmd.Args(notesCmd, nil); err != nil {
+		t.Fatalf("notes --update returned error: %v", err)
+	}
+}
@@ -0,0 +1,146 @@
+package module_release
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/NethServer/gh-ns8/internal/config"
+	"github.com/NethServer/gh-ns8/internal/github"
+	"github.com/NethServer/gh-ns8/internal/module_release"
+	"github.com/spf13/cobra"
+)
+
+var publishCommentFlag bool
+
+// publishCmd represents the publish command
+var publishCmd = &cobra.Command{
+	Use:   "publish [TAG]",
+	Short: "Publish a draft release",
+	Long: `Publish a draft release created with create --draft. The target commit of the draft is validated again on the release branch: a draft targeting the branch is tagged on its latest commit.
+Without TAG the only draft of the repository is published. With --comment the linked issues are commented as the comment command does.`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: withErrorHints(writePermission, runPublish),
+}
+
+func init() {
+	publishCmd.Flags().BoolVar(&publishCommentFlag, "comment", false, "Comment on the linked issues once the release is published")
+}
+
+type draftReleaseClient interface {
+	ListDraftReleases(repo string) ([]github.Release, error)
+}
+
+type publishClient interface {
+	draftReleaseClient
+	GetLatestCommit(repo, branch string) (string, error)
+	GetCompareStatus(repo, base, head string) (string, error)
+	UpdateReleaseByID(repo string, id int64, update github.ReleaseUpdate) error
+}
+
+func runPublish(cmd *cobra.Command, args []string) error {
+	// Create GitHub client
+	client, err := newCommandClient()
+	if err != nil {
+		return err
+	}
+
+	// Get and validate repository
+	repo, err := module_release.GetOrValidateRepo(client, repoFlag)
+	if err != nil {
+		return err
+	}
+
+	cfg, err := config.FromContext(cmd.Context())
+	if err != nil {
+		return err
+	}
+
+	tag := ""
+	if len(args) > 0 {
+		tag = args[0]
+	}
+
+	published, err := publishDraftRelease(cmd.OutOrStdout(), client, repo, cfg.Branch, tag, client.line)
+	if err != nil {
+		return err
+	}
+
+	if !publishCommentFlag {
+		return nil
+	}
+	fmt.Fprintln(cmd.OutOrStdout())
+	return commentRelease(cmd.OutOrStdout(), cmd.ErrOrStderr(), client, cfg, repo, published, false, false)
+}
+
+// publishDraftRelease publishes the draft tag, the only draft when tag is
+// empty, on its validated target commit and returns the published tag
+func publishDraftRelease(out io.Writer, client publishClient, repo, branch, tag string, line *module_release.VersionLine) (string, error) {
+	drafts, err := client.ListDraftReleases(repo)
+	if err != nil {
+		return "", err
+	}
+
+	draft, err := selectDraftRelease(drafts, tag)
+	if err != nil {
+		return "", err
+	}
+	if line != nil && !line.Contains(draft.TagName) {
+		return "", fmt.Errorf("release %s is not in the %s version line", draft.TagName, line)
+	}
+
+	// A draft targeting the branch is tagged on its latest commit
+	target := draft.Target
+	if target == branch {
+		target = ""
+	}
+	commitInfo, err := module_release.GetOrValidateCommit(client, repo, branch, target)
+	if err != nil {
+		return "", err
+	}
+
+	isDraft := false
+	if err := client.UpdateReleaseByID(repo, draft.DatabaseID, github.ReleaseUpdate{
+		Draft:  &isDraft,
+		Target: &commitInfo.SHA,
+	}); err != nil {
+		return "", fmt.Errorf("failed to publish release %s: %w", draft.TagName, err)
+	}
+
+	fmt.Fprintf(out, "✅ Release %s published on commit %s\n", draft.TagName, commitInfo.SHA)
+	return draft.TagName, nil
+}
+
+// selectDraftRelease returns the draft tag, the only draft when tag is empty
+func selectDraftRelease(drafts []github.Release, tag string) (*github.Release, error) {
+	if tag != "" {
+		draft := findDraftRelease(drafts, tag)
+		if draft == nil {
+			return nil, fmt.Errorf("no draft release %s found", tag)
+		}
+		return draft, nil
+	}
+
+	switch len(drafts) {
+	case 0:
+		return nil, fmt.Errorf("no draft release found")
+	case 1:
+		return &drafts[0], nil
+	}
+
+	tags := make([]string, 0, len(drafts))
+	for _, draft := range drafts {
+		tags = append(tags, draft.TagName)
+	}
+	return nil, fmt.Errorf("several draft releases found, please provide the tag to publish: %s", strings.Join(tags, ", "))
+}
+
+// findDraftRelease returns the draft with tag, nil when there is none
+func findDraftRelease(drafts []github.Release, tag string) *github.Release {
+	for i := range drafts {
+		if drafts[i].TagName == tag {
+			return &drafts[i]
+		}
+	}
+	return nil
+}
@@ -0,0 +1,107 @@
+package module_release
+
+import (
+	"bytes"
+	"testing"
+
+	ghgithub "github.com/NethServer/gh-ns8/internal/github"
+)
+
+type fakePublishClient struct {
+	drafts        []ghgithub.Release
+	latestCommit  string
+	compareStatus string
+	updatedID     int64
+	update        ghgithub.ReleaseUpdate
+}
+
+func (f *fakePublishClient) ListDraftReleases(_ string) ([]ghgithub.Release, error) {
+	return f.drafts, nil
+}
+
+func (f *fakePublishClient) GetLatestCommit(_, _ string) (string, error) {
+	return f.latestCommit, nil
+}
+
+func (f *fakePublishClient) GetCompareStatus(_, _, _ string) (string, error) {
+	return f.compareStatus, nil
+}
+
+func (f *fakePublishClient) UpdateReleaseByID(_ string, id int64, update ghgithub.ReleaseUpdate) error {
+	f.updatedID = id
+	f.update = update
+	return nil
+}
+
+func TestPublishDraftReleaseTagsBranchHead(t *testing.T) {
+	client := &fakePublishClient{
+		drafts:       []ghgithub.Release{{DatabaseID: 7, TagName: "1.2.4", Target: "main"}},
+		latestCommit: "head-sha",
+	}
+
+	var out bytes.Buffer
+	tag, err := publishDraftRelease(&out, client, "NethServer/ns8-mail", "main", "", nil)
+	if err != nil {
+		t.Fatalf("publishDraftRelease() returned error: %v", err)
+	}
+	if tag != "1.2.4" {
+		t.Fatalf("publishDraftRelease() = %q, want %q", tag, "1.2.4")
+	}
+	if client.updatedID != 7 || client.update.Draft == nil || *client.update.Draft || client.update.Target == nil || *client.update.Target != "head-sha" {
+		t.Fatalf("publishDraftRelease() update = %d %+v, want draft 7 published on head-sha", client.updatedID, client.update)
+	}
+	if want := "✅ Release 1.2.4 published on commit head-sha\n"; out.String() != want {
+		t.Fatalf("publishDraftRelease() output = %q, want %q", out.String(), want)
+	}
+}
+
+func TestPublishDraftReleaseValidatesTargetCommit(t *testing.T) {
+	client := &fakePublishClient{
+		drafts:        []ghgithub.Release{{DatabaseID: 7, TagName: "1.2.4", Target: "old-sha"}},
+		compareStatus: "diverged",
+	}
+
+	_, err := publishDraftRelease(&bytes.Buffer{}, client, "NethServer/ns8-mail", "main", "1.2.4", nil)
+	if err == nil || err.Error() != "the commit sha is not on the branch: main" {
+		t.Fatalf("publishDraftRelease() error = %v, want branch error", err)
+	}
+	if client.updatedID != 0 {
+		t.Fatalf("publishDraftRelease() updated draft %d, want no update", client.updatedID)
+	}
+}
+
+func TestSelectDraftRelease(t *testing.T) {
+	drafts := []ghgithub.Release{{TagName: "1.2.5"}, {TagName: "1.2.4"}}
+
+	testCases := []struct {
+		name    string
+		drafts  []ghgithub.Release
+		tag     string
+		want    string
+		wantErr string
+	}{
+		{name: "explicit tag", drafts: drafts, tag: "1.2.4", want: "1.2.4"},
+		{name: "missing tag", drafts: drafts, tag: "1.2.6", wantErr: "no draft release 1.2.6 found"},
+		{name: "only draft", drafts: drafts[:1], want: "1.2.5"},
+		{name: "no drafts", wantErr: "no draft release found"},
+		{name: "several drafts", drafts: drafts, wantErr: "several draft releases found, please provide the tag to publish: 1.2.5, 1.2.4"},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			got, err := selectDraftRelease(testCase.drafts, testCase.tag)
+			if testCase.wantErr != "" {
+				if err == nil || err.Error() != testCase.wantErr {
+					t.Fatalf("selectDraftRelease() error = %v, want %q", err, testCase.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("selectDraftRelease() returned error: %v", err)
+			}
+			if got.TagName != testCase.want {
+				t.Fatalf("selectDraftRelease() = %q, want %q", got.TagName, testCase.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,99 @@
+package module_release
+
+import (
+	"crypto/ed25519"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/NethServer/gh-ns8/internal/module_release"
+	"github.com/spf13/cobra"
+)
+
+var verifyKeyFlag string
+
+// verifyCmd represents the verify command
+var verifyCmd = &cobra.Command{
+	Use:   "verify TAG",
+	Short: "Verify the provenance of a release",
+	Long:  `Check the provenance statement attached by create --provenance-key: its signature must match the public key and its commit must be the commit of the release tag.`,
+	Args:  cobra.ExactArgs(1),
+	RunE:  withErrorHints(readPermission, runVerify),
+}
+
+func init() {
+	verifyCmd.Flags().StringVar(&verifyKeyFlag, "key", "", "The ed25519 public key of the provenance, e.g. ~/.ssh/id_ed25519.pub")
+	verifyCmd.MarkFlagRequired("key")
+}
+
+type provenanceVerifyClient interface {
+	releaseAssetsClient
+	GetCommitSHA(repo, ref string) (string, error)
+}
+
+func runVerify(cmd *cobra.Command, args []string) error {
+	data, err := os.ReadFile(verifyKeyFlag)
+	if err != nil {
+		return fmt.Errorf("failed to read the public key: %w", err)
+	}
+	key, err := module_release.ParsePublicKey(data)
+	if err != nil {
+		return fmt.Errorf("failed to read the public key %s: %w", verifyKeyFlag, err)
+	}
+
+	// Create GitHub client
+	client, err := newCommandClient()
+	if err != nil {
+		return err
+	}
+
+	// Get and validate repository
+	repo, err := module_release.GetOrValidateRepo(client, repoFlag)
+	if err != nil {
+		return err
+	}
+
+	return verifyProvenance(cmd.OutOrStdout(), client, repo, args[0], key)
+}
+
+// verifyProvenance checks the signature of the provenance asset of a release
+// and that its commit is the commit of the tag
+func verifyProvenance(out io.Writer, client provenanceVerifyClient, repo, tag string, key ed25519.PublicKey) error {
+	assets, err := client.ListReleaseAssets(repo, tag)
+	if err != nil {
+		return err
+	}
+
+	name := module_release.ProvenanceAssetName(repo, tag)
+	for _, asset := range assets {
+		if asset.Name != name {
+			continue
+		}
+
+		content, err := client.DownloadReleaseAsset(asset)
+		if err != nil {
+			return err
+		}
+		statement, err := module_release.VerifyProvenance(content, key)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(out, "✅ %s is signed by %s\n", name, module_release.KeyFingerprint(key))
+
+		commit, err := statement.SubjectCommit(module_release.ProvenanceSubjectName(repo, tag))
+		if err != nil {
+			return err
+		}
+		tagCommit, err := module_release.GetReleaseCommitSHA(client, repo, tag)
+		if err != nil {
+			return err
+		}
+		if commit != tagCommit {
+			return fmt.Errorf("the provenance commit %s is not the commit %s of tag %s", commit, tagCommit, tag)
+		}
+		fmt.Fprintf(out, "✅ Tag %s is on commit %s, as recorded\n", tag, commit)
+		return nil
+	}
+
+	return fmt.Errorf("release %s has no %s asset", tag, name)
+}
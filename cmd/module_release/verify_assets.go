@@ -0,0 +1,110 @@
+package module_release
+
+import (
+	"fmt"
+	"io"
+	"sort"
+
+	"github.com/NethServer/gh-ns8/internal/github"
+	"github.com/NethServer/gh-ns8/internal/module_release"
+	"github.com/spf13/cobra"
+)
+
+// verifyAssetsCmd represents the verify-assets command
+var verifyAssetsCmd = &cobra.Command{
+	Use:   "verify-assets TAG",
+	Short: "Verify the assets of a release against its SHA256SUMS",
+	Long: `Download the assets of a release and check them against the SHA256SUMS asset uploaded by create --asset.
+The command fails when an asset does not match its checksum or a listed asset is missing.`,
+	Args: cobra.ExactArgs(1),
+	RunE: withErrorHints(readPermission, runVerifyAssets),
+}
+
+type releaseAssetsClient interface {
+	ListReleaseAssets(repo, tag string) ([]github.ReleaseAsset, error)
+	DownloadReleaseAsset(asset github.ReleaseAsset) ([]byte, error)
+}
+
+func runVerifyAssets(cmd *cobra.Command, args []string) error {
+	// Create GitHub client
+	client, err := newCommandClient()
+	if err != nil {
+		return err
+	}
+
+	// Get and validate repository
+	repo, err := module_release.GetOrValidateRepo(client, repoFlag)
+	if err != nil {
+		return err
+	}
+
+	return verifyReleaseAssets(cmd.OutOrStdout(), client, repo, args[0])
+}
+
+// verifyReleaseAssets checks the assets of a release against its SHA256SUMS.
+// Assets missing from the checksums are reported but do not fail.
+func verifyReleaseAssets(out io.Writer, client releaseAssetsClient, repo, tag string) error {
+	assets, err := client.ListReleaseAssets(repo, tag)
+	if err != nil {
+		return err
+	}
+
+	byName := make(map[string]github.ReleaseAsset, len(assets))
+	for _, asset := range assets {
+		byName[asset.Name] = asset
+	}
+	checksumsAsset, ok := byName[module_release.ChecksumsAsset]
+	if !ok {
+		return fmt.Errorf("release %s has no %s asset", tag, module_release.ChecksumsAsset)
+	}
+
+	content, err := client.DownloadReleaseAsset(checksumsAsset)
+	if err != nil {
+		return err
+	}
+	checksums, err := module_release.ParseChecksums(content)
+	if err != nil {
+		return err
+	}
+
+	names := make([]string, 0, len(checksums))
+	for name := range checksums {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	failed := 0
+	for _, name := range names {
+		asset, ok := byName[name]
+		if !ok {
+			fmt.Fprintf(out, "❌ %s: missing\n", name)
+			failed++
+			continue
+		}
+
+		content, err := client.DownloadReleaseAsset(asset)
+		if err != nil {
+			fmt.Fprintf(out, "❌ %s: %v\n", name, err)
+			failed++
+			continue
+		}
+		if module_release.SHA256Sum(content) != checksums[name] {
+			fmt.Fprintf(out, "❌ %s: checksum mismatch\n", name)
+			failed++
+			continue
+		}
+		fmt.Fprintf(out, "✅ %s\n", name)
+	}
+
+	for _, asset := range assets {
+		if _, listed := checksums[asset.Name]; !listed && asset.Name != module_release.ChecksumsAsset {
+			fmt.Fprintf(out, "⚠️  %s: not in %s\n", asset.Name, module_release.ChecksumsAsset)
+		}
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("%d asset(s) of %s failed verification", failed, tag)
+	}
+	fmt.Fprintf(out, "\n✅ Verified %d asset(s) of %s\n", len(checksums), tag)
+	return nil
+}
@@ -0,0 +1,88 @@
+package module_release
+
+import (
+	"bytes"
+	"testing"
+
+	ghgithub "github.com/NethServer/gh-ns8/internal/github"
+	internalmodule "github.com/NethServer/gh-ns8/internal/module_release"
+)
+
+type fakeReleaseAssetsClient struct {
+	assets   []ghgithub.ReleaseAsset
+	contents map[string][]byte
+}
+
+func (f *fakeReleaseAssetsClient) ListReleaseAssets(_, _ string) ([]ghgithub.ReleaseAsset, error) {
+	return f.assets, nil
+}
+
+func (f *fakeReleaseAssetsClient) DownloadReleaseAsset(asset ghgithub.ReleaseAsset) ([]byte, error) {
+	return f.contents[asset.Name], nil
+}
+
+func (f *fakeReleaseAssetsClient) UploadReleaseAsset(_ string, _ int64, name string, content []byte) (*ghgithub.ReleaseAsset, error) {
+	if f.contents == nil {
+		f.contents = map[string][]byte{}
+	}
+	asset := ghgithub.ReleaseAsset{ID: int64(len(f.assets) + 1), Name: name, Size: int64(len(content))}
+	f.assets = append(f.assets, asset)
+	f.contents[name] = content
+	return &asset, nil
+}
+
+func TestUploadedAssetsVerify(t *testing.T) {
+	client := &fakeReleaseAssetsClient{}
+	assets := []internalmodule.Asset{{Name: "ui.tar.gz", Content: []byte("ui")}, {Name: "sbom.json", Content: []byte("{}")}}
+
+	var out bytes.Buffer
+	if err := uploadReleaseAssets(&out, client, "NethServer/ns8-mail", 7, assets); err != nil {
+		t.Fatalf("uploadReleaseAssets() returned error: %v", err)
+	}
+	want := "📦 Uploaded ui.tar.gz\n📦 Uploaded sbom.json\n📦 Uploaded SHA256SUMS\n"
+	if out.String() != want {
+		t.Fatalf("uploadReleaseAssets() output = %q, want %q", out.String(), want)
+	}
+
+	out.Reset()
+	if err := verifyReleaseAssets(&out, client, "NethServer/ns8-mail", "1.2.0"); err != nil {
+		t.Fatalf("verifyReleaseAssets() returned error: %v", err)
+	}
+	want = "✅ sbom.json\n✅ ui.tar.gz\n\n✅ Verified 2 asset(s) of 1.2.0\n"
+	if out.String() != want {
+		t.Fatalf("verifyReleaseAssets() output = %q, want %q", out.String(), want)
+	}
+}
+
+func TestVerifyReleaseAssetsReportsMismatches(t *testing.T) {
+	checksums := internalmodule.ChecksumsFile([]internalmodule.Asset{
+		{Name: "sbom.json", Content: []byte("{}")},
+		{Name: "ui.tar.gz", Content: []byte("ui")},
+	})
+	client := &fakeReleaseAssetsClient{
+		assets: []ghgithub.ReleaseAsset{{Name: "ui.tar.gz"}, {Name: "extra.txt"}, {Name: checksums.Name}},
+		contents: map[string][]byte{
+			"ui.tar.gz":    []byte("tampered"),
+			checksums.Name: checksums.Content,
+		},
+	}
+
+	var out bytes.Buffer
+	err := verifyReleaseAssets(&out, client, "NethServer/ns8-mail", "1.2.0")
+	if err == nil || err.Error() != "2 asset(s) of 1.2.0 failed verification" {
+		t.Fatalf("verifyReleaseAssets() error = %v, want verification error", err)
+	}
+	want := "❌ sbom.json: missing\n❌ ui.tar.gz: checksum mismatch\n⚠️  extra.txt: not in SHA256SUMS\n"
+	if out.String() != want {
+		t.Fatalf("verifyReleaseAssets() output = %q, want %q", out.String(), want)
+	}
+}
+
+func TestVerifyReleaseAssetsRequiresChecksums(t *testing.T) {
+	client := &fakeReleaseAssetsClient{assets: []ghgithub.ReleaseAsset{{Name: "ui.tar.gz"}}}
+
+	err := verifyReleaseAssets(&bytes.Buffer{}, client, "NethServer/ns8-mail", "1.2.0")
+	if err == nil || err.Error() != "release 1.2.0 has no SHA256SUMS asset" {
+		t.Fatalf("verifyReleaseAssets() error = %v, want missing checksums error", err)
+	}
+}
@@ -0,0 +1,106 @@
+package module_release
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"errors"
+	"strings"
+	"testing"
+
+	internalconfig "github.com/NethServer/gh-ns8/internal/config"
+	ghgithub "github.com/NethServer/gh-ns8/internal/github"
+	internalmodule "github.com/NethServer/gh-ns8/internal/module_release"
+)
+
+type fakeProvenanceVerifyClient struct {
+	fakeReleaseAssetsClient
+	tagCommits map[string]string
+}
+
+func (f *fakeProvenanceVerifyClient) GetCommitSHA(_, ref string) (string, error) {
+	return f.tagCommits[ref], nil
+}
+
+func newTestProvenanceAsset(t *testing.T) (internalmodule.Asset, ed25519.PublicKey) {
+	t.Helper()
+
+	public, private, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		t.Fatalf("GenerateKey() returned error: %v", err)
+	}
+	asset, err := provenanceAsset(newRangeNotesTestClient(), internalconfig.Default(), "NethServer/ns8-mail", "1.2.4", "1.2.3", "commit-b", []int{1, 2}, private)
+	if err != nil {
+		t.Fatalf("provenanceAsset() returned error: %v", err)
+	}
+	return asset, public
+}
+
+func TestProvenanceAssetRecordsTheReleaseChanges(t *testing.T) {
+	asset, public := newTestProvenanceAsset(t)
+	if asset.Name != "ns8-mail-1.2.4.intoto.jsonl" {
+		t.Fatalf("provenanceAsset() name = %q, want %q", asset.Name, "ns8-mail-1.2.4.intoto.jsonl")
+	}
+
+	statement, err := internalmodule.VerifyProvenance(asset.Content, public)
+	if err != nil {
+		t.Fatalf("VerifyProvenance() returned error: %v", err)
+	}
+	build := statement.Predicate.BuildDefinition
+	if got := build.InternalParameters.PullRequests; len(got) != 2 || got[0] != 1 || got[1] != 2 {
+		t.Fatalf("provenanceAsset() pull requests = %v, want [1 2]", got)
+	}
+	if got := build.InternalParameters.LinkedIssues; len(got) != 2 || got[0] != 10 || got[1] != 11 {
+		t.Fatalf("provenanceAsset() linked issues = %v, want [10 11]", got)
+	}
+}
+
+func TestProvenanceAssetFailsOnUnreadablePullRequests(t *testing.T) {
+	_, private, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		t.Fatalf("GenerateKey() returned error: %v", err)
+	}
+	client := newRangeNotesTestClient()
+	client.prErrs = map[int]error{2: ghgithub.ErrForbidden}
+
+	_, err = provenanceAsset(client, internalconfig.Default(), "NethServer/ns8-mail", "1.2.4", "1.2.3", "commit-b", []int{1, 2}, private)
+	if !errors.Is(err, ghgithub.ErrForbidden) || !strings.Contains(err.Error(), "#2") {
+		t.Fatalf("provenanceAsset() error = %v, want the PR #2 read error", err)
+	}
+}
+
+func TestVerifyProvenanceChecksTagCommit(t *testing.T) {
+	asset, public := newTestProvenanceAsset(t)
+	client := &fakeProvenanceVerifyClient{
+		fakeReleaseAssetsClient: fakeReleaseAssetsClient{
+			assets:   []ghgithub.ReleaseAsset{{Name: asset.Name}},
+			contents: map[string][]byte{asset.Name: asset.Content},
+		},
+		tagCommits: map[string]string{"tags/1.2.4": "commit-b"},
+	}
+
+	var out bytes.Buffer
+	if err := verifyProvenance(&out, client, "NethServer/ns8-mail", "1.2.4", public); err != nil {
+		t.Fatalf("verifyProvenance() returned error: %v", err)
+	}
+	want := "✅ ns8-mail-1.2.4.intoto.jsonl is signed by " + internalmodule.KeyFingerprint(public) + "\n" +
+		"✅ Tag 1.2.4 is on commit commit-b, as recorded\n"
+	if out.String() != want {
+		t.Fatalf("verifyProvenance() output = %q, want %q", out.String(), want)
+	}
+
+	client.tagCommits["tags/1.2.4"] = "moved"
+	err := verifyProvenance(&bytes.Buffer{}, client, "NethServer/ns8-mail", "1.2.4", public)
+	if err == nil || err.Error() != "the provenance commit commit-b is not the commit moved of tag 1.2.4" {
+		t.Fatalf("verifyProvenance() error = %v, want commit mismatch", err)
+	}
+}
+
+func TestVerifyProvenanceRequiresTheAsset(t *testing.T) {
+	public, _, _ := ed25519.GenerateKey(nil)
+	client := &fakeProvenanceVerifyClient{}
+
+	err := verifyProvenance(&bytes.Buffer{}, client, "NethServer/ns8-mail", "1.2.4", public)
+	if err == nil || !strings.Contains(err.Error(), "has no ns8-mail-1.2.4.intoto.jsonl asset") {
+		t.Fatalf("verifyProvenance() error = %v, want missing asset error", err)
+	}
+}
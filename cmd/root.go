@@ -3,12 +3,20 @@ package cmd
 import (
 	"fmt"
 	"os"
+	"strings"
 
+	"github.com/NethServer/gh-ns8/internal/config"
+	"github.com/NethServer/gh-ns8/internal/github"
 	"github.com/spf13/cobra"
 )
 
 var (
-	debugMode bool
+	debugMode  bool
+	recordFile string
+	replayFile string
+	noCache    bool
+	refresh    bool
+	hostname   string
 )
 
 // rootCmd represents the base command when called without any subcommands
@@ -16,13 +24,69 @@ var rootCmd = &cobra.Command{
 	Use:   "ns8",
 	Short: "NethServer 8 CLI extension",
 	Long:  `A GitHub CLI extension for NethServer 8 module management and automation.`,
-	PersistentPreRun: func(cmd *cobra.Command, args []string) {
+	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+		if hostname != "" {
+			os.Setenv(github.EnvHost, hostname)
+		}
 		if debugMode {
-			os.Setenv("DEBUG", "1")
+			os.Setenv(github.EnvDebug, "1")
+		}
+		if recordFile != "" && replayFile != "" {
+			return fmt.Errorf("--record and --replay cannot be used together")
+		}
+		if recordFile != "" {
+			os.Setenv(github.EnvRecord, recordFile)
+		}
+		if replayFile != "" {
+			os.Setenv(github.EnvReplay, replayFile)
+		}
+		if noCache {
+			os.Setenv(github.EnvNoCache, "1")
+		}
+		if refresh {
+			os.Setenv(github.EnvRefresh, "1")
 		}
+		cmd.SetContext(config.WithLoader(cmd.Context(), func() (*config.Config, error) {
+			return loadConfig(cmd)
+		}))
+		return nil
 	},
 }
 
+// loadConfig loads the configuration of the repository selected by the
+// --repo flag of cmd, or else of the working directory, with the values of
+// the flags set on the command line. The local file only applies to the
+// repository of the working directory.
+func loadConfig(cmd *cobra.Command) (*config.Config, error) {
+	repo := ""
+	if flag := cmd.Flags().Lookup("repo"); flag != nil {
+		repo = flag.Value.String()
+	}
+	// Outside a repository only the local and user files apply
+	current, _ := github.GetCurrentRepository()
+	withLocal := repo == "" || strings.EqualFold(repo, current)
+	if repo == "" {
+		repo = current
+	}
+
+	flags := map[string]string{}
+	for name, key := range config.FlagKeys {
+		if cmd.Flags().Changed(name) {
+			flags[key] = cmd.Flags().Lookup(name).Value.String()
+		}
+	}
+
+	if repo == "" {
+		return config.Load(nil, "", withLocal, flags)
+	}
+
+	client, err := github.NewClient()
+	if err != nil {
+		return nil, fmt.Errorf("failed to create GitHub client: %w", err)
+	}
+	return config.Load(client, repo, withLocal, flags)
+}
+
 // Execute adds all child commands to the root command and sets flags appropriately.
 func Execute() {
 	if err := rootCmd.Execute(); err != nil {
@@ -32,10 +96,25 @@ func Execute() {
 }
 
 func init() {
+	rootCmd.PersistentFlags().StringVar(&hostname, "hostname", "", "The GitHub host to use, e.g. a GitHub Enterprise Server instance (default: GH_HOST or the gh default host)")
 	rootCmd.PersistentFlags().BoolVar(&debugMode, "debug", false, "Enable debug mode")
+	rootCmd.PersistentFlags().StringVar(&recordFile, "record", "", "Record every GitHub API request and response into a cassette file")
+	rootCmd.PersistentFlags().StringVar(&replayFile, "replay", "", "Serve GitHub API responses from a cassette file, without network access")
+	rootCmd.PersistentFlags().BoolVar(&noCache, "no-cache", false, "Do not use the on-disk GitHub API response cache")
+	rootCmd.PersistentFlags().BoolVar(&refresh, "refresh", false, "Ignore cached GitHub API responses and fetch them again")
 }
 
 // AddModuleReleaseCommand adds the module-release command to root
 func AddModuleReleaseCommand(cmd *cobra.Command) {
 	rootCmd.AddCommand(cmd)
 }
+
+// AddCacheCommand adds the cache command to root
+func AddCacheCommand(cmd *cobra.Command) {
+	rootCmd.AddCommand(cmd)
+}
+
+// AddConfigCommand adds the config command to root
+func AddConfigCommand(cmd *cobra.Command) {
+	rootCmd.AddCommand(cmd)
+}
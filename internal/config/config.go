@@ -0,0 +1,715 @@
+package config
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"slices"
+	"strings"
+	"text/template"
+
+	"gopkg.in/yaml.v3"
+)
+
+// FileName is the path of the configuration file in a module repository
+const FileName = ".github/ns8-release.yml"
+
+// Configuration sources, from the lowest to the highest precedence
+const (
+	SourceDefault    = "default"
+	SourceRepository = "repository"
+	SourceUser       = "user"
+	SourceLocal      = "local"
+	SourceFlags      = "flags"
+)
+
+var (
+	repoPattern         = regexp.MustCompile(`^[^/\s]+/[^/\s]+$`)
+	categoryNamePattern = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]*$`)
+)
+
+// Built-in PR categories of the PRs no category rule matches
+const (
+	// CategoryGeneric collects the open PRs
+	CategoryGeneric = "generic"
+	// CategoryMerged collects the merged and closed PRs
+	CategoryMerged = "merged"
+)
+
+// PR and issue states a rule can match
+const (
+	StateOpen   = "open"
+	StateMerged = "merged"
+	StateClosed = "closed"
+)
+
+// Issue progress values, as shown by the check command
+const (
+	ProgressInProgress = "in_progress"
+	ProgressTesting    = "testing"
+	ProgressVerified   = "verified"
+)
+
+// Sources of the issue progress
+const (
+	ProgressSourceLabels  = "labels"
+	ProgressSourceProject = "project"
+)
+
+// Sources of the release notes of the create command
+const (
+	// NotesGitHub are the notes generated by GitHub
+	NotesGitHub = "github"
+	// NotesNative are the notes rendered from notes.template
+	NotesNative = "native"
+	// NotesBoth are the native notes followed by the GitHub ones
+	NotesBoth = "both"
+)
+
+// ReviewApproved requires the approval of every PR linked to an issue
+const ReviewApproved = "approved"
+
+// Treatments of parent issues by the readiness policy
+const (
+	// ParentsChildren groups a parent issue by its children
+	ParentsChildren = "children"
+	// ParentsSelf groups a parent issue by its own state, like any issue
+	ParentsSelf = "self"
+)
+
+// Checks of the module metadata run by create before the release
+const (
+	// CheckRequiredFiles requires the metadata files to exist
+	CheckRequiredFiles = "required-files"
+	// CheckVersionMatch requires the versions of the metadata files to be
+	// the release name
+	CheckVersionMatch = "version-match"
+	// CheckNoPrerelease rejects pre-release versions, e.g. 1.2.0-testing.1,
+	// in the metadata of a stable release
+	CheckNoPrerelease = "no-prerelease"
+)
+
+// MetadataChecks are the names of the known metadata checks
+var MetadataChecks = []string{CheckRequiredFiles, CheckVersionMatch, CheckNoPrerelease}
+
+// Config is the release policy of a module repository
+type Config struct {
+	// IssuesRepo is the repository tracking the issues linked by PRs
+	IssuesRepo string `yaml:"issues_repo"`
+	// Branch is the branch releases are made from
+	Branch     string     `yaml:"branch"`
+	Prerelease Prerelease `yaml:"prerelease"`
+	// Categories classify the PRs shown by check, the first category with a
+	// matching rule wins
+	Categories []Category `yaml:"categories"`
+	Labels     Labels     `yaml:"labels"`
+	// Progress is the source of the issue progress, labels or project
+	Progress  string    `yaml:"progress"`
+	Project   Project   `yaml:"project"`
+	Readiness Readiness `yaml:"readiness"`
+	Notes     Notes     `yaml:"notes"`
+	Comments  Comments  `yaml:"comments"`
+	// Validation are the checks of the module metadata run before creating
+	// a release
+	Validation Validation `yaml:"validation"`
+
+	sources map[string]string
+	layers  []LayerInfo
+}
+
+// Prerelease configures pre-release names
+type Prerelease struct {
+	// Stages are the ordered prerelease identifiers, e.g. dev, testing, rc
+	Stages []string `yaml:"stages"`
+}
+
+// Category is a PR category of the check command
+type Category struct {
+	// Name identifies the category, e.g. renovate
+	Name string `yaml:"name"`
+	// Title names the category in the legend, the name when empty
+	Title string `yaml:"title,omitempty"`
+	Emoji string `yaml:"emoji"`
+	// Warning, when set, lists the open PRs of the category under this
+	// message before the summary
+	Warning string `yaml:"warning,omitempty"`
+	// Rules are alternatives: a PR matching any of them is in the category
+	Rules []Rule `yaml:"rules"`
+}
+
+// Rule matches the PRs satisfying all of its conditions. A list condition
+// is satisfied by any of its values.
+type Rule struct {
+	Authors []string `yaml:"authors,omitempty"`
+	Labels  []string `yaml:"labels,omitempty"`
+	// Title is a regular expression matched against the PR title
+	Title string `yaml:"title,omitempty"`
+	// BranchPrefix matches the start of the PR head branch, e.g. renovate/
+	BranchPrefix string `yaml:"branch_prefix,omitempty"`
+	// State is open, merged or closed (closed without merging)
+	State string `yaml:"state,omitempty"`
+}
+
+// Labels are the issue labels tracking the QA progress
+type Labels struct {
+	Testing  string `yaml:"testing"`
+	Verified string `yaml:"verified"`
+}
+
+// Project is a GitHub Projects v2 board tracking the issues
+type Project struct {
+	// Owner is the login of the organization or user owning the project
+	Owner  string        `yaml:"owner"`
+	Number int           `yaml:"number"`
+	Status ProjectStatus `yaml:"status"`
+	// Updates are the fields the comment command sets with --update-project
+	Updates ProjectUpdates `yaml:"updates"`
+}
+
+// ProjectStatus is the single-select field of the project tracking the QA
+// progress: the Testing and Verified options map to the testing and verified
+// progress, any other value to in progress
+type ProjectStatus struct {
+	Field    string `yaml:"field"`
+	Testing  string `yaml:"testing"`
+	Verified string `yaml:"verified"`
+}
+
+// ProjectUpdates are the project fields set for the linked issues of a
+// release; empty values are left alone
+type ProjectUpdates struct {
+	// PrereleaseStatus and ReleaseStatus are the status options set for a
+	// prerelease and a release
+	PrereleaseStatus string `yaml:"prerelease_status"`
+	ReleaseStatus    string `yaml:"release_status"`
+	// VersionField is the field set to the release version
+	VersionField string `yaml:"version_field"`
+	// DateField is the field set to the release date
+	DateField string `yaml:"date_field"`
+}
+
+// Readiness is the policy deciding which issues are ready to release. An
+// accepted issue is ready once all its linked PRs are merged; an issue with
+// merged PRs that is not accepted blocks the release.
+type Readiness struct {
+	// Accept are alternative rules: an issue matching any of them is accepted
+	Accept []ReadinessRule `yaml:"accept"`
+	// Parents is children or self, see ParentsChildren and ParentsSelf
+	Parents string `yaml:"parents"`
+}
+
+// ReadinessRule matches the issues satisfying all of its conditions
+type ReadinessRule struct {
+	// Labels are satisfied by any of the issue labels
+	Labels []string `yaml:"labels,omitempty"`
+	// Progress is in_progress, testing or verified
+	Progress string `yaml:"progress,omitempty"`
+	// State is the issue state, open or closed
+	State string `yaml:"state,omitempty"`
+	// Review is approved to require the approval of every linked PR
+	Review string `yaml:"review,omitempty"`
+}
+
+// Notes configure the release notes
+type Notes struct {
+	// Source is github, native or both, see NotesGitHub, NotesNative and
+	// NotesBoth
+	Source string `yaml:"source"`
+	// Template is the text/template source of the native notes, the
+	// built-in template when empty
+	Template string `yaml:"template"`
+}
+
+// Comments are the text/template sources of the comments posted on the
+// linked issues. Templates receive the Repo, Release and URL fields.
+type Comments struct {
+	Prerelease string `yaml:"prerelease"`
+	Release    string `yaml:"release"`
+}
+
+// Validation configures the metadata checks create runs on the files of the
+// release commit, aborting the release when one fails
+type Validation struct {
+	// Checks are the names of the checks to run, none when empty
+	Checks []string       `yaml:"checks"`
+	Files  []MetadataFile `yaml:"files"`
+}
+
+// MetadataFile is a file of the module carrying version metadata
+type MetadataFile struct {
+	Path string `yaml:"path"`
+	// Optional files are only checked when present
+	Optional bool `yaml:"optional,omitempty"`
+	// VersionField is the dotted path of the version in a JSON or YAML
+	// file, e.g. version
+	VersionField string `yaml:"version_field,omitempty"`
+	// VersionPattern is a regular expression whose first group matches the
+	// versions of a text file, e.g. ghcr.io/nethserver/mail:(\S+)
+	VersionPattern string `yaml:"version_pattern,omitempty"`
+}
+
+// CommentData is passed to the comment templates
+type CommentData struct {
+	Repo    string
+	Release string
+	URL     string
+}
+
+// LayerInfo describes a configuration layer and whether it was found
+type LayerInfo struct {
+	Source string
+	Path   string
+	Found  bool
+}
+
+// Default returns the built-in configuration
+func Default() *Config {
+	return &Config{
+		IssuesRepo: "NethServer/dev",
+		Branch:     "main",
+		Prerelease: Prerelease{
+			Stages: []string{"testing"},
+		},
+		Categories: []Category{
+			{
+				Name:  "renovate",
+				Title: "Renovate",
+				Emoji: "🤖",
+				Rules: []Rule{{Authors: []string{"renovate[bot]"}, State: StateMerged}},
+			},
+			{
+				Name:    "translation",
+				Title:   "Translation",
+				Emoji:   "🌐",
+				Warning: "Open Weblate PRs detected",
+				Rules:   []Rule{{Authors: []string{"weblate"}}},
+			},
+		},
+		Labels: Labels{
+			Testing:  "testing",
+			Verified: "verified",
+		},
+		Progress: ProgressSourceLabels,
+		Project: Project{
+			Owner: "NethServer",
+			Status: ProjectStatus{
+				Field:    "Status",
+				Testing:  "Testing",
+				Verified: "Verified",
+			},
+			Updates: ProjectUpdates{
+				PrereleaseStatus: "Testing",
+			},
+		},
+		Readiness: Readiness{
+			Accept:  []ReadinessRule{{Progress: ProgressVerified}},
+			Parents: ParentsChildren,
+		},
+		Notes: Notes{
+			Source: NotesGitHub,
+		},
+		Comments: Comments{
+			Prerelease: "Testing release `{{.Repo}}` [{{.Release}}]({{.URL}})",
+			Release:    "Release `{{.Repo}}` [{{.Release}}]({{.URL}})",
+		},
+	}
+}
+
+// Layer is a set of configuration values from one source. Values are nested
+// maps as decoded from YAML.
+type Layer struct {
+	Source string
+	// Path locates the source, e.g. the file name
+	Path   string
+	Values map[string]interface{}
+	// Missing is set for a source that does not exist, such as an absent file
+	Missing bool
+}
+
+// ParseLayer parses a YAML configuration document, rejecting unknown keys
+func ParseLayer(source, path string, data []byte) (Layer, error) {
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(&Config{}); err != nil && !errors.Is(err, io.EOF) {
+		return Layer{}, fmt.Errorf("invalid configuration in %s: %w", path, err)
+	}
+
+	layer := Layer{Source: source, Path: path, Values: map[string]interface{}{}}
+	if err := yaml.Unmarshal(data, &layer.Values); err != nil {
+		return Layer{}, fmt.Errorf("invalid configuration in %s: %w", path, err)
+	}
+	if layer.Values == nil {
+		layer.Values = map[string]interface{}{}
+	}
+	return layer, nil
+}
+
+// MissingLayer records a source that does not exist
+func MissingLayer(source, path string) Layer {
+	return Layer{Source: source, Path: path, Missing: true}
+}
+
+// FlagLayer builds the layer of values set on the command line. Keys are
+// dotted paths, e.g. prerelease.stages; list values are comma separated.
+func FlagLayer(values map[string]string) (Layer, error) {
+	defaults, err := toValues(Default())
+	if err != nil {
+		return Layer{}, err
+	}
+
+	layer := Layer{Source: SourceFlags, Path: "command line", Values: map[string]interface{}{}}
+	for key, value := range values {
+		path := strings.Split(key, ".")
+		if _, isList := lookup(defaults, path).([]interface{}); isList {
+			var items []interface{}
+			for _, item := range strings.Split(value, ",") {
+				items = append(items, strings.TrimSpace(item))
+			}
+			setValue(layer.Values, path, items)
+			continue
+		}
+		setValue(layer.Values, path, value)
+	}
+	return layer, nil
+}
+
+// Merge applies layers over the default configuration, from the lowest to
+// the highest precedence. Maps are merged key by key, any other value,
+// lists included, replaces the lower one.
+func Merge(layers ...Layer) (*Config, error) {
+	merged, err := toValues(Default())
+	if err != nil {
+		return nil, err
+	}
+
+	sources := map[string]string{}
+	for _, path := range leafPaths(merged, nil) {
+		sources[path] = SourceDefault
+	}
+
+	infos := []LayerInfo{{Source: SourceDefault, Path: "built-in", Found: true}}
+	for _, layer := range layers {
+		infos = append(infos, LayerInfo{Source: layer.Source, Path: layer.Path, Found: !layer.Missing})
+		mergeValues(merged, layer.Values, nil, layer.Source, sources)
+	}
+
+	data, err := yaml.Marshal(merged)
+	if err != nil {
+		return nil, err
+	}
+	config := &Config{}
+	if err := yaml.Unmarshal(data, config); err != nil {
+		return nil, fmt.Errorf("invalid configuration: %w", err)
+	}
+	config.sources = sources
+	config.layers = infos
+
+	if err := config.Validate(); err != nil {
+		return nil, err
+	}
+	return config, nil
+}
+
+// Validate checks the configuration values
+func (c *Config) Validate() error {
+	if !repoPattern.MatchString(c.IssuesRepo) {
+		return fmt.Errorf("invalid configuration: issues_repo must be owner/name, got %q", c.IssuesRepo)
+	}
+	if c.Branch == "" {
+		return fmt.Errorf("invalid configuration: branch is empty")
+	}
+	if len(c.Prerelease.Stages) == 0 {
+		return fmt.Errorf("invalid configuration: prerelease.stages is empty")
+	}
+	if c.Labels.Testing == "" || c.Labels.Verified == "" {
+		return fmt.Errorf("invalid configuration: labels.testing and labels.verified are required")
+	}
+	switch c.Progress {
+	case ProgressSourceLabels:
+	case ProgressSourceProject:
+		if err := c.Project.validate(); err != nil {
+			return fmt.Errorf("invalid configuration: project: %w", err)
+		}
+	default:
+		return fmt.Errorf("invalid configuration: progress must be %s or %s, got %q", ProgressSourceLabels, ProgressSourceProject, c.Progress)
+	}
+	if err := validateCategories(c.Categories); err != nil {
+		return fmt.Errorf("invalid configuration: %w", err)
+	}
+	if err := c.Readiness.validate(); err != nil {
+		return fmt.Errorf("invalid configuration: readiness: %w", err)
+	}
+	switch c.Notes.Source {
+	case NotesGitHub, NotesNative, NotesBoth:
+	default:
+		return fmt.Errorf("invalid configuration: notes.source must be %s, %s or %s, got %q", NotesGitHub, NotesNative, NotesBoth, c.Notes.Source)
+	}
+	if err := c.Validation.validate(); err != nil {
+		return fmt.Errorf("invalid configuration: validation: %w", err)
+	}
+	for key, text := range map[string]string{"notes.template": c.Notes.Template, "comments.prerelease": c.Comments.Prerelease, "comments.release": c.Comments.Release} {
+		if _, err := template.New(key).Parse(text); err != nil {
+			return fmt.Errorf("invalid configuration: %s: %w", key, err)
+		}
+	}
+	return nil
+}
+
+func validateCategories(categories []Category) error {
+	seen := map[string]bool{CategoryGeneric: true, CategoryMerged: true}
+	for _, category := range categories {
+		if !categoryNamePattern.MatchString(category.Name) {
+			return fmt.Errorf("invalid category name: %q", category.Name)
+		}
+		if seen[category.Name] {
+			return fmt.Errorf("category %s is already defined", category.Name)
+		}
+		seen[category.Name] = true
+
+		if category.Emoji == "" {
+			return fmt.Errorf("category %s has no emoji", category.Name)
+		}
+		if len(category.Rules) == 0 {
+			return fmt.Errorf("category %s has no rules", category.Name)
+		}
+		for i, rule := range category.Rules {
+			if err := rule.validate(); err != nil {
+				return fmt.Errorf("category %s rule %d: %w", category.Name, i+1, err)
+			}
+		}
+	}
+	return nil
+}
+
+func (r Rule) validate() error {
+	if len(r.Authors) == 0 && len(r.Labels) == 0 && r.Title == "" && r.BranchPrefix == "" && r.State == "" {
+		return fmt.Errorf("no conditions")
+	}
+	if _, err := regexp.Compile(r.Title); err != nil {
+		return fmt.Errorf("invalid title: %w", err)
+	}
+	switch r.State {
+	case "", StateOpen, StateMerged, StateClosed:
+		return nil
+	default:
+		return fmt.Errorf("invalid state %q (must be %s, %s or %s)", r.State, StateOpen, StateMerged, StateClosed)
+	}
+}
+
+func (p Project) validate() error {
+	if p.Owner == "" || p.Number <= 0 {
+		return fmt.Errorf("owner and number are required")
+	}
+	if p.Status.Field == "" || p.Status.Testing == "" || p.Status.Verified == "" {
+		return fmt.Errorf("status.field, status.testing and status.verified are required")
+	}
+	return nil
+}
+
+func (r Readiness) validate() error {
+	if len(r.Accept) == 0 {
+		return fmt.Errorf("accept is empty")
+	}
+	for i, rule := range r.Accept {
+		if len(rule.Labels) == 0 && rule.Progress == "" && rule.State == "" && rule.Review == "" {
+			return fmt.Errorf("accept rule %d has no conditions", i+1)
+		}
+		switch rule.Progress {
+		case "", ProgressInProgress, ProgressTesting, ProgressVerified:
+		default:
+			return fmt.Errorf("accept rule %d: invalid progress %q (must be %s, %s or %s)", i+1, rule.Progress, ProgressInProgress, ProgressTesting, ProgressVerified)
+		}
+		switch rule.State {
+		case "", StateOpen, StateClosed:
+		default:
+			return fmt.Errorf("accept rule %d: invalid state %q (must be %s or %s)", i+1, rule.State, StateOpen, StateClosed)
+		}
+		if rule.Review != "" && rule.Review != ReviewApproved {
+			return fmt.Errorf("accept rule %d: invalid review %q (must be %s)", i+1, rule.Review, ReviewApproved)
+		}
+	}
+	switch r.Parents {
+	case ParentsChildren, ParentsSelf:
+		return nil
+	default:
+		return fmt.Errorf("invalid parents %q (must be %s or %s)", r.Parents, ParentsChildren, ParentsSelf)
+	}
+}
+
+func (v Validation) validate() error {
+	seen := map[string]bool{}
+	for _, check := range v.Checks {
+		if !slices.Contains(MetadataChecks, check) {
+			return fmt.Errorf("unknown check %q (must be %s)", check, strings.Join(MetadataChecks, ", "))
+		}
+		if seen[check] {
+			return fmt.Errorf("check %s is listed twice", check)
+		}
+		seen[check] = true
+	}
+	if len(v.Checks) > 0 && len(v.Files) == 0 {
+		return fmt.Errorf("files is empty")
+	}
+
+	versioned := false
+	for i, file := range v.Files {
+		if file.Path == "" {
+			return fmt.Errorf("file %d has no path", i+1)
+		}
+		if file.VersionField != "" && file.VersionPattern != "" {
+			return fmt.Errorf("file %s: version_field and version_pattern are exclusive", file.Path)
+		}
+		if file.VersionPattern != "" {
+			pattern, err := regexp.Compile(file.VersionPattern)
+			if err != nil {
+				return fmt.Errorf("file %s: invalid version_pattern: %w", file.Path, err)
+			}
+			if pattern.NumSubexp() == 0 {
+				return fmt.Errorf("file %s: version_pattern has no group matching the version", file.Path)
+			}
+		}
+		versioned = versioned || file.VersionField != "" || file.VersionPattern != ""
+	}
+	if seen[CheckVersionMatch] && !versioned {
+		return fmt.Errorf("%s needs a file with a version_field or a version_pattern", CheckVersionMatch)
+	}
+	return nil
+}
+
+// Source returns the source of the value of a dotted key
+func (c *Config) Source(key string) string {
+	if source, ok := c.sources[key]; ok {
+		return source
+	}
+	return SourceDefault
+}
+
+// Layers returns the layers the configuration was merged from, from the
+// lowest to the highest precedence
+func (c *Config) Layers() []LayerInfo {
+	if len(c.layers) == 0 {
+		return []LayerInfo{{Source: SourceDefault, Path: "built-in", Found: true}}
+	}
+	return c.layers
+}
+
+// CommentBody renders the comment posted on the issues linked to a release
+func (c *Config) CommentBody(data CommentData, prerelease bool) (string, error) {
+	text := c.Comments.Release
+	if prerelease {
+		text = c.Comments.Prerelease
+	}
+
+	tmpl, err := template.New("comment").Parse(text)
+	if err != nil {
+		return "", fmt.Errorf("invalid comment template: %w", err)
+	}
+
+	var body strings.Builder
+	if err := tmpl.Execute(&body, data); err != nil {
+		return "", fmt.Errorf("failed to render comment template: %w", err)
+	}
+	return body.String(), nil
+}
+
+// Describe renders the configuration as YAML, annotating every value with
+// its source
+func (c *Config) Describe() (string, error) {
+	var node yaml.Node
+	if err := node.Encode(c); err != nil {
+		return "", err
+	}
+	c.annotate(&node, nil)
+
+	var out bytes.Buffer
+	encoder := yaml.NewEncoder(&out)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(&node); err != nil {
+		return "", err
+	}
+	return out.String(), nil
+}
+
+func (c *Config) annotate(node *yaml.Node, path []string) {
+	if node.Kind != yaml.MappingNode {
+		node.LineComment = c.Source(strings.Join(path, "."))
+		return
+	}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		key, value := node.Content[i], node.Content[i+1]
+		childPath := append(append([]string{}, path...), key.Value)
+		if value.Kind == yaml.MappingNode {
+			c.annotate(value, childPath)
+			continue
+		}
+		key.LineComment = c.Source(strings.Join(childPath, "."))
+	}
+}
+
+func toValues(config *Config) (map[string]interface{}, error) {
+	data, err := yaml.Marshal(config)
+	if err != nil {
+		return nil, err
+	}
+	values := map[string]interface{}{}
+	if err := yaml.Unmarshal(data, &values); err != nil {
+		return nil, err
+	}
+	return values, nil
+}
+
+func mergeValues(dst, src map[string]interface{}, path []string, source string, sources map[string]string) {
+	for key, value := range src {
+		childPath := append(append([]string{}, path...), key)
+		if srcMap, ok := value.(map[string]interface{}); ok {
+			dstMap, ok := dst[key].(map[string]interface{})
+			if !ok {
+				dstMap = map[string]interface{}{}
+				dst[key] = dstMap
+			}
+			mergeValues(dstMap, srcMap, childPath, source, sources)
+			continue
+		}
+		dst[key] = value
+		sources[strings.Join(childPath, ".")] = source
+	}
+}
+
+func leafPaths(values map[string]interface{}, path []string) []string {
+	var paths []string
+	for key, value := range values {
+		childPath := append(append([]string{}, path...), key)
+		if child, ok := value.(map[string]interface{}); ok {
+			paths = append(paths, leafPaths(child, childPath)...)
+			continue
+		}
+		paths = append(paths, strings.Join(childPath, "."))
+	}
+	return paths
+}
+
+func lookup(values map[string]interface{}, path []string) interface{} {
+	var current interface{} = values
+	for _, key := range path {
+		currentMap, ok := current.(map[string]interface{})
+		if !ok {
+			return nil
+		}
+		current = currentMap[key]
+	}
+	return current
+}
+
+func setValue(values map[string]interface{}, path []string, value interface{}) {
+	for _, key := range path[:len(path)-1] {
+		child, ok := values[key].(map[string]interface{})
+		if !ok {
+			child = map[string]interface{}{}
+			values[key] = child
+		}
+		values = child
+	}
+	values[path[len(path)-1]] = value
+}
@@ -0,0 +1,316 @@
+package config
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func mustParseLayer(t *testing.T, source, data string) Layer {
+	t.Helper()
+	layer, err := ParseLayer(source, source+".yml", []byte(data))
+	if err != nil {
+		t.Fatalf("ParseLayer() returned error: %v", err)
+	}
+	return layer
+}
+
+func TestMergeWithoutLayersReturnsDefaults(t *testing.T) {
+	got, err := Merge()
+	if err != nil {
+		t.Fatalf("Merge() returned error: %v", err)
+	}
+
+	want := Default()
+	if got.IssuesRepo != want.IssuesRepo || got.Branch != want.Branch ||
+		!reflect.DeepEqual(got.Prerelease, want.Prerelease) ||
+		!reflect.DeepEqual(got.Categories, want.Categories) ||
+		got.Labels != want.Labels || got.Comments != want.Comments {
+		t.Fatalf("Merge() = %+v, want the defaults %+v", got, want)
+	}
+	if got.Source("labels.testing") != SourceDefault {
+		t.Fatalf("Source(labels.testing) = %q, want %q", got.Source("labels.testing"), SourceDefault)
+	}
+}
+
+func TestMergeAppliesLayersInOrder(t *testing.T) {
+	repo := mustParseLayer(t, SourceRepository, `
+branch: stable-1.4
+prerelease:
+  stages: [dev, testing, rc]
+labels:
+  testing: qa
+`)
+	local := mustParseLayer(t, SourceLocal, `
+branch: stable-1.5
+comments:
+  release: "Released {{.Release}}"
+`)
+	flags, err := FlagLayer(map[string]string{"prerelease.stages": "alpha, beta"})
+	if err != nil {
+		t.Fatalf("FlagLayer() returned error: %v", err)
+	}
+
+	got, err := Merge(repo, MissingLayer(SourceUser, "user.yml"), local, flags)
+	if err != nil {
+		t.Fatalf("Merge() returned error: %v", err)
+	}
+
+	if got.Branch != "stable-1.5" {
+		t.Errorf("Branch = %q, want the local override", got.Branch)
+	}
+	if !reflect.DeepEqual(got.Prerelease.Stages, []string{"alpha", "beta"}) {
+		t.Errorf("Prerelease.Stages = %v, want the flag list to replace the repository one", got.Prerelease.Stages)
+	}
+	if got.Labels.Testing != "qa" || got.Labels.Verified != "verified" {
+		t.Errorf("Labels = %+v, want testing from the repository and verified from the defaults", got.Labels)
+	}
+	if got.Comments.Prerelease != Default().Comments.Prerelease {
+		t.Errorf("Comments.Prerelease = %q, want the default", got.Comments.Prerelease)
+	}
+
+	sources := map[string]string{
+		"branch":            SourceLocal,
+		"prerelease.stages": SourceFlags,
+		"labels.testing":    SourceRepository,
+		"labels.verified":   SourceDefault,
+		"comments.release":  SourceLocal,
+	}
+	for key, want := range sources {
+		if source := got.Source(key); source != want {
+			t.Errorf("Source(%s) = %q, want %q", key, source, want)
+		}
+	}
+
+	var found []string
+	for _, layer := range got.Layers() {
+		if layer.Found {
+			found = append(found, layer.Source)
+		}
+	}
+	if !reflect.DeepEqual(found, []string{SourceDefault, SourceRepository, SourceLocal, SourceFlags}) {
+		t.Errorf("found layers = %v, want all but the user one", found)
+	}
+}
+
+func TestMergeReplacesCategories(t *testing.T) {
+	got, err := Merge(mustParseLayer(t, SourceRepository, `
+categories:
+  - name: dependencies
+    title: Dependencies
+    emoji: 📦
+    rules:
+      - authors: ["dependabot[bot]", "renovate[bot]"]
+      - labels: [dependencies]
+        state: merged
+`))
+	if err != nil {
+		t.Fatalf("Merge() returned error: %v", err)
+	}
+
+	want := []Category{{
+		Name:  "dependencies",
+		Title: "Dependencies",
+		Emoji: "📦",
+		Rules: []Rule{
+			{Authors: []string{"dependabot[bot]", "renovate[bot]"}},
+			{Labels: []string{"dependencies"}, State: StateMerged},
+		},
+	}}
+	if !reflect.DeepEqual(got.Categories, want) {
+		t.Fatalf("Categories = %+v, want %+v", got.Categories, want)
+	}
+	if got.Source("categories") != SourceRepository {
+		t.Fatalf("Source(categories) = %q, want %q", got.Source("categories"), SourceRepository)
+	}
+}
+
+func TestMergeReplacesReadinessRules(t *testing.T) {
+	got, err := Merge(mustParseLayer(t, SourceRepository, `
+readiness:
+  accept:
+    - progress: verified
+    - labels: [no-qa]
+      state: closed
+      review: approved
+`))
+	if err != nil {
+		t.Fatalf("Merge() returned error: %v", err)
+	}
+
+	want := Readiness{
+		Accept: []ReadinessRule{
+			{Progress: ProgressVerified},
+			{Labels: []string{"no-qa"}, State: StateClosed, Review: ReviewApproved},
+		},
+		Parents: ParentsChildren,
+	}
+	if !reflect.DeepEqual(got.Readiness, want) {
+		t.Fatalf("Readiness = %+v, want %+v", got.Readiness, want)
+	}
+	if got.Source("readiness.parents") != SourceDefault {
+		t.Fatalf("Source(readiness.parents) = %q, want %q", got.Source("readiness.parents"), SourceDefault)
+	}
+}
+
+func TestMergeSelectsProjectProgress(t *testing.T) {
+	got, err := Merge(mustParseLayer(t, SourceRepository, `
+progress: project
+project:
+  number: 12
+  status:
+    verified: Done
+`))
+	if err != nil {
+		t.Fatalf("Merge() returned error: %v", err)
+	}
+
+	want := Project{
+		Owner:   "NethServer",
+		Number:  12,
+		Status:  ProjectStatus{Field: "Status", Testing: "Testing", Verified: "Done"},
+		Updates: ProjectUpdates{PrereleaseStatus: "Testing"},
+	}
+	if got.Progress != ProgressSourceProject || got.Project != want {
+		t.Fatalf("Progress = %q, Project = %+v, want %q and %+v", got.Progress, got.Project, ProgressSourceProject, want)
+	}
+}
+
+func TestParseLayerRejectsUnknownKeys(t *testing.T) {
+	_, err := ParseLayer(SourceLocal, FileName, []byte("lables:\n  testing: qa\n"))
+	if err == nil || !strings.Contains(err.Error(), FileName) {
+		t.Fatalf("ParseLayer() error = %v, want an error naming %s", err, FileName)
+	}
+}
+
+func TestParseLayerAcceptsEmptyDocument(t *testing.T) {
+	layer, err := ParseLayer(SourceLocal, FileName, nil)
+	if err != nil {
+		t.Fatalf("ParseLayer() returned error: %v", err)
+	}
+	if len(layer.Values) != 0 {
+		t.Fatalf("Values = %v, want none", layer.Values)
+	}
+}
+
+func TestMergeValidatesValues(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+	}{
+		{name: "issues repo", data: "issues_repo: dev"},
+		{name: "empty branch", data: `branch: ""`},
+		{name: "no stages", data: "prerelease:\n  stages: []"},
+		{name: "empty label", data: `labels: {verified: ""}`},
+		{name: "comment template", data: `comments: {release: "{{.Release"}`},
+		{name: "category name", data: "categories: [{name: Deps, emoji: x, rules: [{authors: [a]}]}]"},
+		{name: "built-in category", data: "categories: [{name: merged, emoji: x, rules: [{authors: [a]}]}]"},
+		{name: "duplicate category", data: "categories: [{name: a, emoji: x, rules: [{state: open}]}, {name: a, emoji: y, rules: [{state: open}]}]"},
+		{name: "category emoji", data: "categories: [{name: deps, rules: [{authors: [a]}]}]"},
+		{name: "category rules", data: "categories: [{name: deps, emoji: x}]"},
+		{name: "empty rule", data: "categories: [{name: deps, emoji: x, rules: [{}]}]"},
+		{name: "rule title", data: "categories: [{name: deps, emoji: x, rules: [{title: \"(\"}]}]"},
+		{name: "rule state", data: "categories: [{name: deps, emoji: x, rules: [{state: draft}]}]"},
+		{name: "progress source", data: "progress: board"},
+		{name: "project number", data: "progress: project"},
+		{name: "project status", data: "progress: project\nproject:\n  number: 12\n  status: {field: \"\"}"},
+		{name: "notes source", data: "notes:\n  source: markdown"},
+		{name: "notes template", data: "notes:\n  template: \"{{range .Issues}}\""},
+		{name: "no readiness rules", data: "readiness:\n  accept: []"},
+		{name: "empty readiness rule", data: "readiness:\n  accept: [{}]"},
+		{name: "readiness progress", data: "readiness:\n  accept: [{progress: done}]"},
+		{name: "readiness state", data: "readiness:\n  accept: [{state: merged}]"},
+		{name: "readiness review", data: "readiness:\n  accept: [{review: requested}]"},
+		{name: "readiness parents", data: "readiness:\n  parents: none"},
+		{name: "validation check", data: "validation:\n  checks: [lint]\n  files: [{path: ui/package.json}]"},
+		{name: "duplicate validation check", data: "validation:\n  checks: [required-files, required-files]\n  files: [{path: ui/package.json}]"},
+		{name: "validation files", data: "validation:\n  checks: [required-files]"},
+		{name: "validation file path", data: "validation:\n  files: [{version_field: version}]"},
+		{name: "validation version source", data: "validation:\n  files: [{path: README.md, version_field: version, version_pattern: \"v(.+)\"}]"},
+		{name: "validation version pattern", data: "validation:\n  files: [{path: README.md, version_pattern: \"(\"}]"},
+		{name: "validation version group", data: "validation:\n  files: [{path: README.md, version_pattern: \"mail:\\\\S+\"}]"},
+		{name: "validation version match", data: "validation:\n  checks: [version-match]\n  files: [{path: ui/package.json}]"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := Merge(mustParseLayer(t, SourceLocal, tt.data)); err == nil {
+				t.Fatal("Merge() returned nil error, want a validation error")
+			}
+		})
+	}
+}
+
+func TestMergeReadsValidation(t *testing.T) {
+	got, err := Merge(mustParseLayer(t, SourceRepository, `
+validation:
+  checks: [required-files, version-match]
+  files:
+    - path: ui/package.json
+      version_field: version
+    - path: README.md
+      optional: true
+      version_pattern: 'ns8-mail:(\S+)'
+`))
+	if err != nil {
+		t.Fatalf("Merge() returned error: %v", err)
+	}
+
+	want := Validation{
+		Checks: []string{CheckRequiredFiles, CheckVersionMatch},
+		Files: []MetadataFile{
+			{Path: "ui/package.json", VersionField: "version"},
+			{Path: "README.md", Optional: true, VersionPattern: `ns8-mail:(\S+)`},
+		},
+	}
+	if !reflect.DeepEqual(got.Validation, want) {
+		t.Fatalf("Validation = %+v, want %+v", got.Validation, want)
+	}
+	if len(Default().Validation.Checks) != 0 {
+		t.Fatalf("default Validation.Checks = %v, want none", Default().Validation.Checks)
+	}
+}
+
+func TestCommentBodyRendersTemplates(t *testing.T) {
+	cfg := Default()
+	data := CommentData{Repo: "NethServer/ns8-mail", Release: "1.2.0", URL: "https://github.com/NethServer/ns8-mail/releases/tag/1.2.0"}
+
+	got, err := cfg.CommentBody(data, false)
+	if err != nil {
+		t.Fatalf("CommentBody() returned error: %v", err)
+	}
+	if want := "Release `NethServer/ns8-mail` [1.2.0](https://github.com/NethServer/ns8-mail/releases/tag/1.2.0)"; got != want {
+		t.Fatalf("CommentBody() = %q, want %q", got, want)
+	}
+
+	cfg.Comments.Prerelease = "{{.Release}} is ready for QA"
+	got, err = cfg.CommentBody(data, true)
+	if err != nil {
+		t.Fatalf("CommentBody() returned error: %v", err)
+	}
+	if got != "1.2.0 is ready for QA" {
+		t.Fatalf("CommentBody() = %q, want the configured prerelease comment", got)
+	}
+}
+
+func TestDescribeAnnotatesSources(t *testing.T) {
+	cfg, err := Merge(mustParseLayer(t, SourceRepository, "branch: stable-1.4\n"))
+	if err != nil {
+		t.Fatalf("Merge() returned error: %v", err)
+	}
+
+	got, err := cfg.Describe()
+	if err != nil {
+		t.Fatalf("Describe() returned error: %v", err)
+	}
+
+	for _, want := range []string{
+		"branch: stable-1.4 # repository\n",
+		"issues_repo: NethServer/dev # default\n",
+		"  testing: testing # default\n",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("Describe() = %q, want it to contain %q", got, want)
+		}
+	}
+}
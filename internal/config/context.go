@@ -0,0 +1,52 @@
+package config
+
+import (
+	"context"
+	"sync"
+)
+
+// FlagKeys maps the command line flags overriding configuration values to
+// their dotted keys
+var FlagKeys = map[string]string{
+	"issues-repo": "issues_repo",
+	"branch":      "branch",
+	"stages":      "prerelease.stages",
+	"notes":       "notes.source",
+}
+
+type loaderKey struct{}
+
+type loader struct {
+	once   sync.Once
+	load   func() (*Config, error)
+	config *Config
+	err    error
+}
+
+// WithLoader returns a context carrying the function loading the effective
+// configuration. The function runs at most once, the first time a command
+// asks for the configuration, so commands that do not need it never reach
+// the GitHub API.
+func WithLoader(ctx context.Context, load func() (*Config, error)) context.Context {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	return context.WithValue(ctx, loaderKey{}, &loader{load: load})
+}
+
+// FromContext returns the configuration of the context, the defaults when
+// no loader is set
+func FromContext(ctx context.Context) (*Config, error) {
+	if ctx == nil {
+		return Default(), nil
+	}
+	l, ok := ctx.Value(loaderKey{}).(*loader)
+	if !ok {
+		return Default(), nil
+	}
+
+	l.once.Do(func() {
+		l.config, l.err = l.load()
+	})
+	return l.config, l.err
+}
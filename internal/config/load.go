@@ -0,0 +1,100 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/NethServer/gh-ns8/internal/github"
+	ghconfig "github.com/cli/go-gh/v2/pkg/config"
+)
+
+type fileClient interface {
+	GetFile(repo, path, ref string) (*github.File, error)
+}
+
+// UserFile returns the path of the user-level configuration file
+func UserFile() string {
+	return filepath.Join(ghconfig.ConfigDir(), "gh-ns8", "ns8-release.yml")
+}
+
+// Load merges the effective configuration of repo. Layers are applied from
+// the lowest to the highest precedence: defaults, the FileName of the
+// repository, the user file, the FileName of the working directory and
+// finally the flags, keyed as in FlagKeys values. The repository file is
+// read on the branch set by the higher layers, or else on the default
+// branch; it is skipped when client is nil or repo is empty. The local file
+// is skipped unless withLocal, i.e. the working directory is a checkout of
+// repo.
+func Load(client fileClient, repo string, withLocal bool, flags map[string]string) (*Config, error) {
+	userLayer, err := loadFileLayer(SourceUser, UserFile())
+	if err != nil {
+		return nil, err
+	}
+	layers := []Layer{userLayer}
+
+	if withLocal {
+		localLayer, err := loadFileLayer(SourceLocal, FileName)
+		if err != nil {
+			return nil, err
+		}
+		layers = append(layers, localLayer)
+	} else {
+		layers = append(layers, MissingLayer(SourceLocal, ""))
+	}
+
+	if len(flags) > 0 {
+		flagLayer, err := FlagLayer(flags)
+		if err != nil {
+			return nil, err
+		}
+		layers = append(layers, flagLayer)
+	}
+
+	// The branch decides which revision of the repository file applies
+	upper, err := Merge(layers...)
+	if err != nil {
+		return nil, err
+	}
+	ref := ""
+	if upper.Source("branch") != SourceDefault {
+		ref = upper.Branch
+	}
+
+	repoLayer, err := loadRepositoryLayer(client, repo, ref)
+	if err != nil {
+		return nil, err
+	}
+	return Merge(append([]Layer{repoLayer}, layers...)...)
+}
+
+func loadRepositoryLayer(client fileClient, repo, ref string) (Layer, error) {
+	if client == nil || repo == "" {
+		return MissingLayer(SourceRepository, ""), nil
+	}
+
+	path := fmt.Sprintf("%s:%s", repo, FileName)
+	if ref != "" {
+		path += "@" + ref
+	}
+	file, err := client.GetFile(repo, FileName, ref)
+	if errors.Is(err, github.ErrNotFound) {
+		return MissingLayer(SourceRepository, path), nil
+	}
+	if err != nil {
+		return Layer{}, fmt.Errorf("failed to load configuration: %w", err)
+	}
+	return ParseLayer(SourceRepository, path, file.Content)
+}
+
+func loadFileLayer(source, path string) (Layer, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return MissingLayer(source, path), nil
+	}
+	if err != nil {
+		return Layer{}, fmt.Errorf("failed to load configuration: %w", err)
+	}
+	return ParseLayer(source, path, data)
+}
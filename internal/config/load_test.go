@@ -0,0 +1,159 @@
+package config
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/NethServer/gh-ns8/internal/github"
+)
+
+type fakeFileClient struct {
+	// files are keyed by repo:path, followed by @ref when ref is set
+	files map[string]string
+	err   error
+}
+
+func (f fakeFileClient) GetFile(repo, path, ref string) (*github.File, error) {
+	if f.err != nil {
+		return nil, f.err
+	}
+	key := repo + ":" + path
+	if ref != "" {
+		key += "@" + ref
+	}
+	content, ok := f.files[key]
+	if !ok {
+		return nil, fmt.Errorf("failed to get %s: %w", path, github.ErrNotFound)
+	}
+	return &github.File{Path: path, Content: []byte(content)}, nil
+}
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestLoadMergesRepositoryUserLocalAndFlags(t *testing.T) {
+	t.Setenv("GH_CONFIG_DIR", t.TempDir())
+	t.Chdir(t.TempDir())
+
+	client := fakeFileClient{files: map[string]string{
+		"NethServer/ns8-mail:" + FileName + "@local-branch": "branch: stable-1.4\nissues_repo: NethServer/ns8-mail\n",
+	}}
+	writeFile(t, UserFile(), "labels:\n  testing: qa\nbranch: user-branch\n")
+	writeFile(t, FileName, "branch: local-branch\n")
+
+	got, err := Load(client, "NethServer/ns8-mail", true, map[string]string{"issues_repo": "NethServer/dev"})
+	if err != nil {
+		t.Fatalf("Load() returned error: %v", err)
+	}
+
+	if got.Branch != "local-branch" || got.Source("branch") != SourceLocal {
+		t.Errorf("branch = %q from %s, want local-branch from the local file", got.Branch, got.Source("branch"))
+	}
+	if got.Labels.Testing != "qa" || got.Source("labels.testing") != SourceUser {
+		t.Errorf("labels.testing = %q from %s, want qa from the user file", got.Labels.Testing, got.Source("labels.testing"))
+	}
+	if got.IssuesRepo != "NethServer/dev" || got.Source("issues_repo") != SourceFlags {
+		t.Errorf("issues_repo = %q from %s, want the flag value", got.IssuesRepo, got.Source("issues_repo"))
+	}
+}
+
+func TestLoadSkipsMissingSources(t *testing.T) {
+	t.Setenv("GH_CONFIG_DIR", t.TempDir())
+	t.Chdir(t.TempDir())
+
+	got, err := Load(fakeFileClient{}, "NethServer/ns8-mail", true, nil)
+	if err != nil {
+		t.Fatalf("Load() returned error: %v", err)
+	}
+	for _, layer := range got.Layers()[1:] {
+		if layer.Found {
+			t.Errorf("layer %s found, want it missing", layer.Source)
+		}
+	}
+	if got.Branch != Default().Branch {
+		t.Errorf("Branch = %q, want the default", got.Branch)
+	}
+}
+
+func TestLoadReturnsRepositoryErrors(t *testing.T) {
+	t.Setenv("GH_CONFIG_DIR", t.TempDir())
+	t.Chdir(t.TempDir())
+
+	_, err := Load(fakeFileClient{err: github.ErrForbidden}, "NethServer/ns8-mail", true, nil)
+	if !errors.Is(err, github.ErrForbidden) {
+		t.Fatalf("Load() error = %v, want ErrForbidden", err)
+	}
+}
+
+func TestLoadReadsRepositoryFileOnBranch(t *testing.T) {
+	t.Setenv("GH_CONFIG_DIR", t.TempDir())
+	t.Chdir(t.TempDir())
+
+	client := fakeFileClient{files: map[string]string{
+		"NethServer/ns8-mail:" + FileName:                 "issues_repo: NethServer/dev\n",
+		"NethServer/ns8-mail:" + FileName + "@stable-1.4": "issues_repo: NethServer/ns8-mail\n",
+	}}
+
+	got, err := Load(client, "NethServer/ns8-mail", true, map[string]string{"branch": "stable-1.4"})
+	if err != nil {
+		t.Fatalf("Load() returned error: %v", err)
+	}
+	if got.IssuesRepo != "NethServer/ns8-mail" {
+		t.Errorf("issues_repo = %q, want the value of the stable-1.4 file", got.IssuesRepo)
+	}
+
+	got, err = Load(client, "NethServer/ns8-mail", true, nil)
+	if err != nil {
+		t.Fatalf("Load() returned error: %v", err)
+	}
+	if got.IssuesRepo != "NethServer/dev" {
+		t.Errorf("issues_repo = %q, want the value of the default branch file", got.IssuesRepo)
+	}
+}
+
+func TestLoadSkipsLocalFileOfOtherRepository(t *testing.T) {
+	t.Setenv("GH_CONFIG_DIR", t.TempDir())
+	t.Chdir(t.TempDir())
+
+	writeFile(t, FileName, "branch: local-branch\n")
+
+	got, err := Load(fakeFileClient{}, "NethServer/ns8-mail", false, nil)
+	if err != nil {
+		t.Fatalf("Load() returned error: %v", err)
+	}
+	if got.Branch != Default().Branch || got.Source("branch") != SourceDefault {
+		t.Errorf("branch = %q from %s, want the default without the local file", got.Branch, got.Source("branch"))
+	}
+}
+
+func TestFromContextLoadsOnce(t *testing.T) {
+	calls := 0
+	ctx := WithLoader(context.Background(), func() (*Config, error) {
+		calls++
+		return Default(), nil
+	})
+
+	for i := 0; i < 2; i++ {
+		if _, err := FromContext(ctx); err != nil {
+			t.Fatalf("FromContext() returned error: %v", err)
+		}
+	}
+	if calls != 1 {
+		t.Fatalf("loader called %d times, want 1", calls)
+	}
+
+	if cfg, err := FromContext(nil); err != nil || cfg.Branch != Default().Branch {
+		t.Fatalf("FromContext(nil) = %+v, %v, want the defaults", cfg, err)
+	}
+}
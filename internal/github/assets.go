@@ -0,0 +1,95 @@
+package github
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/cli/go-gh/v2/pkg/api"
+)
+
+// ReleaseAsset is a file attached to a release
+type ReleaseAsset struct {
+	ID   int64  `json:"id"`
+	Name string `json:"name"`
+	Size int64  `json:"size"`
+	// URL is the API URL of the asset, used to download it
+	URL string `json:"url"`
+}
+
+// ListReleaseAssets lists the assets of a published release
+func (c *Client) ListReleaseAssets(repo, tag string) ([]ReleaseAsset, error) {
+	var release struct {
+		Assets []ReleaseAsset `json:"assets"`
+	}
+	if err := c.get(fmt.Sprintf("repos/%s/releases/tags/%s", repo, url.PathEscape(tag)), &release); err != nil {
+		return nil, fmt.Errorf("failed to list the assets of %s: %w", tag, err)
+	}
+	return release.Assets, nil
+}
+
+// UploadReleaseAsset attaches a file to a release by its database ID, so
+// that drafts get their assets before they are published. An asset with the
+// same name returns ErrValidationFailed.
+func (c *Client) UploadReleaseAsset(repo string, releaseID int64, name string, content []byte) (*ReleaseAsset, error) {
+	var release struct {
+		UploadURL string `json:"upload_url"`
+	}
+	if err := c.get(fmt.Sprintf("repos/%s/releases/%d", repo, releaseID), &release); err != nil {
+		return nil, fmt.Errorf("failed to upload %s: %w", name, err)
+	}
+
+	// upload_url is a URI template, e.g. .../assets{?name,label}
+	uploadURL, _, _ := strings.Cut(release.UploadURL, "{")
+	req, err := http.NewRequest(http.MethodPost, uploadURL+"?name="+url.QueryEscape(name), bytes.NewReader(content))
+	if err != nil {
+		return nil, fmt.Errorf("failed to upload %s: %w", name, err)
+	}
+	req.Header.Set("Content-Type", "application/octet-stream")
+
+	var asset ReleaseAsset
+	if err := c.doRaw(req, &asset); err != nil {
+		return nil, fmt.Errorf("failed to upload %s: %w", name, err)
+	}
+	return &asset, nil
+}
+
+// DownloadReleaseAsset downloads the content of an asset listed by
+// ListReleaseAssets
+func (c *Client) DownloadReleaseAsset(asset ReleaseAsset) ([]byte, error) {
+	req, err := http.NewRequest(http.MethodGet, asset.URL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to download %s: %w", asset.Name, err)
+	}
+	req.Header.Set("Accept", "application/octet-stream")
+
+	var content bytes.Buffer
+	if err := c.doRaw(req, &content); err != nil {
+		return nil, fmt.Errorf("failed to download %s: %w", asset.Name, err)
+	}
+	return content.Bytes(), nil
+}
+
+// doRaw performs a request whose body is not JSON and classifies its error.
+// The response is copied to a bytes.Buffer or decoded as JSON.
+func (c *Client) doRaw(req *http.Request, response interface{}) error {
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return apiError(api.HandleHTTPError(resp))
+	}
+
+	if buffer, ok := response.(*bytes.Buffer); ok {
+		_, err = io.Copy(buffer, resp.Body)
+		return err
+	}
+	return json.NewDecoder(resp.Body).Decode(response)
+}
@@ -0,0 +1,92 @@
+package github
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"testing"
+)
+
+func TestUploadReleaseAssetPostsRawContent(t *testing.T) {
+	client := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case r.Method == http.MethodGet && r.URL.Path == "/repos/NethServer/ns8-mail/releases/7":
+			io.WriteString(w, `{"id":7,"upload_url":"https://uploads.github.com/repos/NethServer/ns8-mail/releases/7/assets{?name,label}"}`)
+		case r.Method == http.MethodPost && r.URL.Path == "/repos/NethServer/ns8-mail/releases/7/assets":
+			if r.Host != "uploads.github.com" || r.URL.Query().Get("name") != "ui.tar.gz" {
+				t.Errorf("upload request = %s %s, want uploads.github.com with name ui.tar.gz", r.Host, r.URL)
+			}
+			if r.Header.Get("Content-Type") != "application/octet-stream" {
+				t.Errorf("upload Content-Type = %q, want application/octet-stream", r.Header.Get("Content-Type"))
+			}
+			body, _ := io.ReadAll(r.Body)
+			if string(body) != "tarball" {
+				t.Errorf("upload body = %q, want %q", body, "tarball")
+			}
+			w.WriteHeader(http.StatusCreated)
+			io.WriteString(w, `{"id":42,"name":"ui.tar.gz","size":7,"url":"https://api.github.com/repos/NethServer/ns8-mail/releases/assets/42"}`)
+		default:
+			t.Errorf("unexpected request %s %s", r.Method, r.URL)
+		}
+	}))
+
+	got, err := client.UploadReleaseAsset("NethServer/ns8-mail", 7, "ui.tar.gz", []byte("tarball"))
+	if err != nil {
+		t.Fatalf("UploadReleaseAsset() returned error: %v", err)
+	}
+	if got.ID != 42 || got.Name != "ui.tar.gz" || got.Size != 7 {
+		t.Fatalf("UploadReleaseAsset() = %+v, want asset 42", got)
+	}
+}
+
+func TestUploadReleaseAssetReturnsValidationFailed(t *testing.T) {
+	client := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if r.Method == http.MethodGet {
+			io.WriteString(w, `{"id":7,"upload_url":"https://uploads.github.com/repos/NethServer/ns8-mail/releases/7/assets{?name,label}"}`)
+			return
+		}
+		w.WriteHeader(http.StatusUnprocessableEntity)
+		io.WriteString(w, `{"message":"Validation Failed","errors":[{"code":"already_exists"}]}`)
+	}))
+
+	_, err := client.UploadReleaseAsset("NethServer/ns8-mail", 7, "ui.tar.gz", []byte("tarball"))
+	if !errors.Is(err, ErrValidationFailed) {
+		t.Fatalf("UploadReleaseAsset() error = %v, want ErrValidationFailed", err)
+	}
+}
+
+func TestListAndDownloadReleaseAssets(t *testing.T) {
+	client := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/repos/NethServer/ns8-mail/releases/tags/1.2.0":
+			w.Header().Set("Content-Type", "application/json")
+			io.WriteString(w, `{"assets":[{"id":42,"name":"SHA256SUMS","size":8,"url":"https://api.github.com/repos/NethServer/ns8-mail/releases/assets/42"}]}`)
+		case "/repos/NethServer/ns8-mail/releases/assets/42":
+			if r.Header.Get("Accept") != "application/octet-stream" {
+				t.Errorf("download Accept = %q, want application/octet-stream", r.Header.Get("Accept"))
+			}
+			w.Header().Set("Content-Type", "application/octet-stream")
+			io.WriteString(w, "checksum")
+		default:
+			t.Errorf("unexpected request %s %s", r.Method, r.URL)
+		}
+	}))
+
+	assets, err := client.ListReleaseAssets("NethServer/ns8-mail", "1.2.0")
+	if err != nil {
+		t.Fatalf("ListReleaseAssets() returned error: %v", err)
+	}
+	if len(assets) != 1 || assets[0].Name != "SHA256SUMS" {
+		t.Fatalf("ListReleaseAssets() = %+v, want SHA256SUMS", assets)
+	}
+
+	content, err := client.DownloadReleaseAsset(assets[0])
+	if err != nil {
+		t.Fatalf("DownloadReleaseAsset() returned error: %v", err)
+	}
+	if string(content) != "checksum" {
+		t.Fatalf("DownloadReleaseAsset() = %q, want %q", content, "checksum")
+	}
+}
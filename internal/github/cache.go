@@ -0,0 +1,168 @@
+package github
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+
+	"github.com/cli/go-gh/v2/pkg/config"
+)
+
+// CacheDir returns the directory holding cached GitHub API responses
+func CacheDir() string {
+	return filepath.Join(config.CacheDir(), "gh-ns8", "http")
+}
+
+// ClearCache removes every cached GitHub API response
+func ClearCache() error {
+	if err := os.RemoveAll(CacheDir()); err != nil {
+		return fmt.Errorf("failed to clear cache: %w", err)
+	}
+	return nil
+}
+
+// cacheTransport keeps GET responses carrying an ETag on disk and
+// revalidates them with If-None-Match. GitHub does not count a 304 Not
+// Modified against the rate limit, so unchanged resources such as closed
+// issues and merged pull requests are free to fetch again.
+//
+// Only API responses are cached: release asset downloads are redirected to
+// signed URLs that change on every request, so they would never be hit.
+//
+// The cache is best effort: a file that cannot be read or written is
+// ignored and the request goes to GitHub as usual.
+type cacheTransport struct {
+	next http.RoundTripper
+	dir  string
+	// host is the GitHub host whose API responses are cached
+	host  string
+	debug io.Writer
+	// refresh skips revalidation and replaces every cached entry
+	refresh bool
+}
+
+func newCacheTransport(next http.RoundTripper, dir, host string, refresh bool, debug io.Writer) *cacheTransport {
+	if next == nil {
+		next = http.DefaultTransport
+	}
+	if debug == nil {
+		debug = io.Discard
+	}
+	return &cacheTransport{next: next, dir: dir, host: host, debug: debug, refresh: refresh}
+}
+
+// cacheable tells if the response of req may be cached: a GET of a JSON API
+// resource of the host, which is served from api.HOST or HOST/api
+func (t *cacheTransport) cacheable(req *http.Request) bool {
+	if req.Method != http.MethodGet || req.Header.Get("Accept") == "application/octet-stream" {
+		return false
+	}
+	hostname := req.URL.Hostname()
+	return hostname == "api."+t.host || hostname == t.host
+}
+
+// RoundTrip implements http.RoundTripper
+func (t *cacheTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if !t.cacheable(req) {
+		return t.next.RoundTrip(req)
+	}
+
+	path := t.entryPath(req)
+	var cached *RecordedResponse
+	if !t.refresh {
+		cached = t.load(path)
+	}
+
+	if cached != nil {
+		req = req.Clone(req.Context())
+		req.Header.Set("If-None-Match", cached.Header.Get("ETag"))
+		if modified := cached.Header.Get("Last-Modified"); modified != "" {
+			req.Header.Set("If-Modified-Since", modified)
+		}
+	}
+
+	resp, err := t.next.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+
+	if cached != nil && resp.StatusCode == http.StatusNotModified {
+		drainBody(resp)
+		fmt.Fprintf(t.debug, "GitHub API cache hit for %s\n", req.URL)
+		return cached.httpResponse(req)
+	}
+
+	if resp.StatusCode != http.StatusOK || resp.Header.Get("ETag") == "" {
+		return resp, nil
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+
+	t.store(path, newRecordedResponse(resp, body))
+	return resp, nil
+}
+
+// entryPath returns the cache file of a request. The key includes the
+// credentials, so responses fetched with a token are never served to another.
+func (t *cacheTransport) entryPath(req *http.Request) string {
+	key := sha256.New()
+	for _, part := range []string{req.Method, req.URL.String(), req.Header.Get("Accept"), req.Header.Get("Authorization")} {
+		io.WriteString(key, part)
+		key.Write([]byte{0})
+	}
+	return filepath.Join(t.dir, hex.EncodeToString(key.Sum(nil))+".json")
+}
+
+func (t *cacheTransport) load(path string) *RecordedResponse {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil
+	}
+
+	var entry RecordedResponse
+	if err := json.Unmarshal(data, &entry); err != nil || entry.Header.Get("ETag") == "" {
+		return nil
+	}
+	return &entry
+}
+
+// store writes an entry through a temporary file, so concurrent runs never
+// read a partially written one.
+func (t *cacheTransport) store(path string, entry RecordedResponse) {
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return
+	}
+
+	if err := os.MkdirAll(t.dir, 0o700); err != nil {
+		fmt.Fprintf(t.debug, "GitHub API cache disabled: %v\n", err)
+		return
+	}
+	tmp, err := os.CreateTemp(t.dir, "entry-*")
+	if err != nil {
+		fmt.Fprintf(t.debug, "GitHub API cache disabled: %v\n", err)
+		return
+	}
+	_, err = tmp.Write(data)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(tmp.Name(), path)
+	}
+	if err != nil {
+		os.Remove(tmp.Name())
+		fmt.Fprintf(t.debug, "GitHub API cache not updated: %v\n", err)
+	}
+}
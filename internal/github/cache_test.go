@@ -0,0 +1,151 @@
+package github
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func newCachingTestClient(t *testing.T, target *url.URL, dir string, refresh bool) *Client {
+	t.Helper()
+	return newCassetteClient(t, newCacheTransport(rewriteTransport{target: target}, dir, "github.com", refresh, nil))
+}
+
+func TestCacheTransportRevalidatesWithETag(t *testing.T) {
+	var conditional []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conditional = append(conditional, r.Header.Get("If-None-Match"))
+		if r.Header.Get("If-None-Match") == `"v1"` {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("ETag", `"v1"`)
+		json.NewEncoder(w).Encode(map[string]interface{}{"number": 10, "title": "Closed issue", "state": "closed"})
+	}))
+	defer server.Close()
+	target, _ := url.Parse(server.URL)
+	dir := t.TempDir()
+
+	for i := 0; i < 2; i++ {
+		issue, err := newCachingTestClient(t, target, dir, false).GetIssue("NethServer/dev", 10)
+		if err != nil {
+			t.Fatalf("GetIssue() returned error: %v", err)
+		}
+		if issue.Title != "Closed issue" || issue.State != "closed" {
+			t.Fatalf("GetIssue() = %+v, want the cached issue", issue)
+		}
+	}
+
+	if len(conditional) != 2 || conditional[0] != "" || conditional[1] != `"v1"` {
+		t.Fatalf("If-None-Match headers = %q, want none then the cached ETag", conditional)
+	}
+}
+
+func TestCacheTransportRefreshSkipsRevalidation(t *testing.T) {
+	version := 1
+	var conditional []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conditional = append(conditional, r.Header.Get("If-None-Match"))
+		etag := fmt.Sprintf(`"v%d"`, version)
+		if r.Header.Get("If-None-Match") == etag {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("ETag", etag)
+		json.NewEncoder(w).Encode(map[string]interface{}{"number": 10, "title": "Version " + etag})
+	}))
+	defer server.Close()
+	target, _ := url.Parse(server.URL)
+	dir := t.TempDir()
+
+	newCachingTestClient(t, target, dir, false).GetIssue("NethServer/dev", 10)
+	version = 2
+	refreshed, _ := newCachingTestClient(t, target, dir, true).GetIssue("NethServer/dev", 10)
+	cached, _ := newCachingTestClient(t, target, dir, false).GetIssue("NethServer/dev", 10)
+
+	if conditional[1] != "" {
+		t.Fatalf("refresh sent If-None-Match %q, want none", conditional[1])
+	}
+	if refreshed.Title != `Version "v2"` || cached.Title != `Version "v2"` {
+		t.Fatalf("titles = %q, %q, want the refreshed response", refreshed.Title, cached.Title)
+	}
+	if conditional[2] != `"v2"` {
+		t.Fatalf("If-None-Match after refresh = %q, want the refreshed ETag", conditional[2])
+	}
+}
+
+func TestCacheTransportSkipsWritesAndResponsesWithoutETag(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPost {
+			w.Header().Set("ETag", `"comment"`)
+			w.WriteHeader(http.StatusCreated)
+			json.NewEncoder(w).Encode(map[string]string{"html_url": "https://github.com/NethServer/dev/issues/10#issuecomment-1"})
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{"number": 10})
+	}))
+	defer server.Close()
+	target, _ := url.Parse(server.URL)
+	dir := t.TempDir()
+
+	client := newCachingTestClient(t, target, dir, false)
+	if _, err := client.GetIssue("NethServer/dev", 10); err != nil {
+		t.Fatalf("GetIssue() returned error: %v", err)
+	}
+	if _, err := client.CreateIssueComment("NethServer/dev", 10, "Released"); err != nil {
+		t.Fatalf("CreateIssueComment() returned error: %v", err)
+	}
+
+	entries, _ := os.ReadDir(dir)
+	if len(entries) != 0 {
+		t.Fatalf("cache has %d entries, want none", len(entries))
+	}
+}
+
+func TestCacheTransportKeysEntriesByToken(t *testing.T) {
+	transport := newCacheTransport(nil, "cache", "github.com", false, nil)
+
+	first, _ := http.NewRequest(http.MethodGet, "https://api.github.com/repos/NethServer/dev/issues/10", nil)
+	first.Header.Set("Authorization", "token first")
+	second := first.Clone(first.Context())
+	second.Header.Set("Authorization", "token second")
+
+	if transport.entryPath(first) == transport.entryPath(second) {
+		t.Fatal("entryPath() is the same for different tokens")
+	}
+	if filepath.Dir(transport.entryPath(first)) != "cache" {
+		t.Fatalf("entryPath() = %q, want a file in the cache directory", transport.entryPath(first))
+	}
+}
+
+func TestCacheTransportSkipsAssetDownloads(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("ETag", `"asset"`)
+		w.Write([]byte("asset content"))
+	}))
+	defer server.Close()
+	target, _ := url.Parse(server.URL)
+	dir := t.TempDir()
+	transport := newCacheTransport(rewriteTransport{target: target}, dir, "github.com", false, nil)
+
+	download, _ := http.NewRequest(http.MethodGet, "https://api.github.com/repos/NethServer/ns8-mail/releases/assets/1", nil)
+	download.Header.Set("Accept", "application/octet-stream")
+	signed, _ := http.NewRequest(http.MethodGet, "https://objects.githubusercontent.com/github-production-release-asset/1?X-Amz-Signature=abc", nil)
+	for _, req := range []*http.Request{download, signed} {
+		resp, err := transport.RoundTrip(req)
+		if err != nil {
+			t.Fatalf("RoundTrip(%s) returned error: %v", req.URL, err)
+		}
+		resp.Body.Close()
+	}
+
+	entries, _ := os.ReadDir(dir)
+	if len(entries) != 0 {
+		t.Fatalf("cache has %d entries, want none for asset downloads", len(entries))
+	}
+}
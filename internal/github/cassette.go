@@ -0,0 +1,265 @@
+package github
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"unicode/utf8"
+)
+
+// Interaction is a GitHub API request and its response, stored one per line
+// in a cassette file. Request headers are not recorded so cassettes never
+// contain credentials.
+type Interaction struct {
+	Request  RecordedRequest  `json:"request"`
+	Response RecordedResponse `json:"response"`
+}
+
+// RecordedRequest identifies a recorded request
+type RecordedRequest struct {
+	Method string `json:"method"`
+	URL    string `json:"url"`
+	Body   string `json:"body,omitempty"`
+}
+
+// RecordedResponse is a recorded response. Bodies that are not valid UTF-8
+// are stored base64 encoded.
+type RecordedResponse struct {
+	StatusCode int         `json:"status"`
+	Header     http.Header `json:"header"`
+	Body       string      `json:"body"`
+	Encoding   string      `json:"encoding,omitempty"`
+}
+
+// recordingTransport appends every interaction to a cassette file as soon
+// as it completes, so a run aborted by an error still leaves a usable file.
+type recordingTransport struct {
+	next     http.RoundTripper
+	cassette *cassetteFile
+}
+
+// cassetteFile is a cassette being recorded, shared by the transports
+// recording into it
+type cassetteFile struct {
+	mu   sync.Mutex
+	file *os.File
+}
+
+// cassetteFiles are the cassettes recorded by the process, by absolute path.
+// A command creates several clients, e.g. one loading the configuration:
+// they all append to the file the first one created.
+var (
+	cassetteFilesMu sync.Mutex
+	cassetteFiles   = map[string]*cassetteFile{}
+)
+
+func newRecordingTransport(next http.RoundTripper, path string) (*recordingTransport, error) {
+	absPath, err := filepath.Abs(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create cassette: %w", err)
+	}
+
+	cassetteFilesMu.Lock()
+	defer cassetteFilesMu.Unlock()
+	cassette, ok := cassetteFiles[absPath]
+	if !ok {
+		file, err := os.Create(absPath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create cassette: %w", err)
+		}
+		cassette = &cassetteFile{file: file}
+		cassetteFiles[absPath] = cassette
+	}
+	return &recordingTransport{next: next, cassette: cassette}, nil
+}
+
+// RoundTrip implements http.RoundTripper
+func (t *recordingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	reqBody, err := requestBody(req)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := t.next.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+
+	respBody, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(respBody))
+
+	interaction := Interaction{
+		Request: RecordedRequest{
+			Method: req.Method,
+			URL:    req.URL.String(),
+			Body:   string(reqBody),
+		},
+		Response: newRecordedResponse(resp, respBody),
+	}
+
+	line, err := json.Marshal(interaction)
+	if err != nil {
+		return nil, err
+	}
+
+	t.cassette.mu.Lock()
+	defer t.cassette.mu.Unlock()
+	if _, err := t.cassette.file.Write(append(line, '\n')); err != nil {
+		return nil, fmt.Errorf("failed to write cassette: %w", err)
+	}
+
+	return resp, nil
+}
+
+// replayTransport serves responses from a cassette without network access.
+// Identical requests are answered in recording order.
+type replayTransport struct {
+	mu           sync.Mutex
+	interactions []Interaction
+	used         []bool
+}
+
+func loadReplayTransport(path string) (*replayTransport, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open cassette: %w", err)
+	}
+	defer file.Close()
+
+	transport := &replayTransport{}
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 0, 64*1024), 64*1024*1024)
+	for lineNumber := 1; scanner.Scan(); lineNumber++ {
+		line := bytes.TrimSpace(scanner.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+		var interaction Interaction
+		if err := json.Unmarshal(line, &interaction); err != nil {
+			return nil, fmt.Errorf("invalid cassette %s at line %d: %w", path, lineNumber, err)
+		}
+		transport.interactions = append(transport.interactions, interaction)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read cassette: %w", err)
+	}
+
+	transport.used = make([]bool, len(transport.interactions))
+	return transport, nil
+}
+
+// host returns the GitHub host the cassette was recorded against
+func (t *replayTransport) host() string {
+	for _, interaction := range t.interactions {
+		u, err := url.Parse(interaction.Request.URL)
+		if err != nil {
+			continue
+		}
+		return strings.TrimPrefix(u.Hostname(), "api.")
+	}
+	return "github.com"
+}
+
+// RoundTrip implements http.RoundTripper
+func (t *replayTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	reqBody, err := requestBody(req)
+	if err != nil {
+		return nil, err
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	for i, interaction := range t.interactions {
+		if t.used[i] ||
+			interaction.Request.Method != req.Method ||
+			interaction.Request.URL != req.URL.String() ||
+			interaction.Request.Body != string(reqBody) {
+			continue
+		}
+		t.used[i] = true
+
+		resp, err := interaction.Response.httpResponse(req)
+		if err != nil {
+			return nil, fmt.Errorf("invalid cassette body for %s %s: %w", req.Method, req.URL, err)
+		}
+		return resp, nil
+	}
+
+	return nil, fmt.Errorf("no recorded response for %s %s in the cassette", req.Method, req.URL)
+}
+
+// newRecordedResponse captures a response whose body was already read
+func newRecordedResponse(resp *http.Response, body []byte) RecordedResponse {
+	recorded := RecordedResponse{
+		StatusCode: resp.StatusCode,
+		Header:     resp.Header.Clone(),
+	}
+	recorded.Header.Del("Set-Cookie")
+	if utf8.Valid(body) {
+		recorded.Body = string(body)
+	} else {
+		recorded.Body = base64.StdEncoding.EncodeToString(body)
+		recorded.Encoding = "base64"
+	}
+	return recorded
+}
+
+// httpResponse rebuilds the recorded response as the answer to req
+func (r RecordedResponse) httpResponse(req *http.Request) (*http.Response, error) {
+	body := []byte(r.Body)
+	if r.Encoding == "base64" {
+		var err error
+		if body, err = base64.StdEncoding.DecodeString(r.Body); err != nil {
+			return nil, err
+		}
+	}
+
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", r.StatusCode, http.StatusText(r.StatusCode)),
+		StatusCode:    r.StatusCode,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        r.Header.Clone(),
+		Body:          io.NopCloser(bytes.NewReader(body)),
+		ContentLength: int64(len(body)),
+		Request:       req,
+	}, nil
+}
+
+// requestBody reads the request body without consuming it
+func requestBody(req *http.Request) ([]byte, error) {
+	if req.Body == nil || req.Body == http.NoBody {
+		return nil, nil
+	}
+	if req.GetBody != nil {
+		body, err := req.GetBody()
+		if err != nil {
+			return nil, err
+		}
+		defer body.Close()
+		return io.ReadAll(body)
+	}
+
+	body, err := io.ReadAll(req.Body)
+	req.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	req.Body = io.NopCloser(bytes.NewReader(body))
+	return body, nil
+}
@@ -0,0 +1,189 @@
+package github
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/cli/go-gh/v2/pkg/api"
+)
+
+func newCassetteClient(t *testing.T, transport http.RoundTripper) *Client {
+	t.Helper()
+
+	client, err := newClient(api.ClientOptions{
+		Host:         "github.com",
+		AuthToken:    "test-token",
+		Transport:    transport,
+		LogIgnoreEnv: true,
+	})
+	if err != nil {
+		t.Fatalf("newClient() returned error: %v", err)
+	}
+	return client
+}
+
+func TestCassetteRecordsAndReplaysInteractions(t *testing.T) {
+	issueTitles := []string{"First title", "Renamed title"}
+	calls := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/repos/NethServer/dev/issues/10":
+			json.NewEncoder(w).Encode(map[string]interface{}{"number": 10, "title": issueTitles[calls], "state": "open"})
+			calls++
+		case "/graphql":
+			io.WriteString(w, `{"data":{"repository":{"issue":{"parent":{"number":100}}}}}`)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			io.WriteString(w, `{"message":"Not Found"}`)
+		}
+	}))
+	target, _ := url.Parse(server.URL)
+
+	path := filepath.Join(t.TempDir(), "check.cassette")
+	recorder, err := newRecordingTransport(rewriteTransport{target: target}, path)
+	if err != nil {
+		t.Fatalf("newRecordingTransport() returned error: %v", err)
+	}
+
+	recorded := newCassetteClient(t, recorder)
+	first, _ := recorded.GetIssue("NethServer/dev", 10)
+	second, _ := recorded.GetIssue("NethServer/dev", 10)
+	parent, _ := recorded.GetParentIssueNumber("NethServer/dev", 10)
+	_, recordedErr := recorded.GetPullRequest("NethServer/ns8-mail", 404)
+	server.Close()
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("os.ReadFile() returned error: %v", err)
+	}
+	if strings.Contains(string(content), "test-token") {
+		t.Fatal("cassette contains the auth token")
+	}
+	if lines := strings.Count(string(content), "\n"); lines != 4 {
+		t.Fatalf("cassette has %d interactions, want 4", lines)
+	}
+
+	replay, err := loadReplayTransport(path)
+	if err != nil {
+		t.Fatalf("loadReplayTransport() returned error: %v", err)
+	}
+	if replay.host() != "github.com" {
+		t.Fatalf("replay.host() = %q, want github.com", replay.host())
+	}
+
+	replayed := newCassetteClient(t, replay)
+	gotFirst, err := replayed.GetIssue("NethServer/dev", 10)
+	if err != nil {
+		t.Fatalf("GetIssue() returned error: %v", err)
+	}
+	gotSecond, _ := replayed.GetIssue("NethServer/dev", 10)
+	gotParent, _ := replayed.GetParentIssueNumber("NethServer/dev", 10)
+	_, replayedErr := replayed.GetPullRequest("NethServer/ns8-mail", 404)
+
+	if gotFirst.Title != first.Title || gotSecond.Title != second.Title || gotFirst.Title == gotSecond.Title {
+		t.Fatalf("replayed titles = %q, %q, want %q, %q in recording order", gotFirst.Title, gotSecond.Title, first.Title, second.Title)
+	}
+	if gotParent != parent || gotParent != 100 {
+		t.Fatalf("replayed parent = %d, want %d", gotParent, parent)
+	}
+	if !errors.Is(recordedErr, ErrNotFound) || !errors.Is(replayedErr, ErrNotFound) {
+		t.Fatalf("replayed error = %v, want ErrNotFound as recorded (%v)", replayedErr, recordedErr)
+	}
+}
+
+func TestCassetteRecordsThroughSeveralClients(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{"number": 10, "title": "Issue of " + r.URL.Path, "state": "open"})
+	}))
+	defer server.Close()
+	target, _ := url.Parse(server.URL)
+
+	// A command records through its own client and the configuration one
+	path := filepath.Join(t.TempDir(), "create.cassette")
+	var clients []*Client
+	for i := 0; i < 2; i++ {
+		recorder, err := newRecordingTransport(rewriteTransport{target: target}, path)
+		if err != nil {
+			t.Fatalf("newRecordingTransport() returned error: %v", err)
+		}
+		clients = append(clients, newCassetteClient(t, recorder))
+	}
+	clients[0].GetIssue("NethServer/dev", 10)
+	clients[1].GetIssue("NethServer/dev", 11)
+	clients[0].GetIssue("NethServer/dev", 12)
+
+	replay, err := loadReplayTransport(path)
+	if err != nil {
+		t.Fatalf("loadReplayTransport() returned error: %v", err)
+	}
+	if len(replay.interactions) != 3 {
+		t.Fatalf("cassette has %d interactions, want 3", len(replay.interactions))
+	}
+	replayed := newCassetteClient(t, replay)
+	for _, number := range []int{10, 11, 12} {
+		if _, err := replayed.GetIssue("NethServer/dev", number); err != nil {
+			t.Fatalf("GetIssue(%d) returned error: %v", number, err)
+		}
+	}
+}
+
+func TestReplayTransportFailsOnUnrecordedRequest(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "empty.cassette")
+	if err := os.WriteFile(path, nil, 0o644); err != nil {
+		t.Fatalf("os.WriteFile() returned error: %v", err)
+	}
+
+	replay, err := loadReplayTransport(path)
+	if err != nil {
+		t.Fatalf("loadReplayTransport() returned error: %v", err)
+	}
+
+	_, err = newCassetteClient(t, replay).GetIssue("NethServer/dev", 1)
+	if err == nil || !strings.Contains(err.Error(), "no recorded response for GET https://api.github.com/repos/NethServer/dev/issues/1") {
+		t.Fatalf("GetIssue() error = %v, want missing recording error", err)
+	}
+}
+
+func TestReplayTransportRestoresBinaryBodies(t *testing.T) {
+	body := []byte{0xff, 0x00, 0xfe}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write(body)
+	}))
+	defer server.Close()
+	target, _ := url.Parse(server.URL)
+
+	path := filepath.Join(t.TempDir(), "binary.cassette")
+	recorder, err := newRecordingTransport(rewriteTransport{target: target}, path)
+	if err != nil {
+		t.Fatalf("newRecordingTransport() returned error: %v", err)
+	}
+	req, _ := http.NewRequest(http.MethodGet, "https://api.github.com/asset", nil)
+	resp, err := recorder.RoundTrip(req)
+	if err != nil {
+		t.Fatalf("RoundTrip() returned error: %v", err)
+	}
+	resp.Body.Close()
+
+	replay, err := loadReplayTransport(path)
+	if err != nil {
+		t.Fatalf("loadReplayTransport() returned error: %v", err)
+	}
+	resp, err = replay.RoundTrip(req)
+	if err != nil {
+		t.Fatalf("RoundTrip() returned error: %v", err)
+	}
+	got, _ := io.ReadAll(resp.Body)
+	if string(got) != string(body) {
+		t.Fatalf("replayed body = %v, want %v", got, body)
+	}
+}
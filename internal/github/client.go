@@ -1,55 +1,148 @@
 package github
 
 import (
+	"bytes"
 	"encoding/json"
 	"fmt"
 	"io"
+	"net/http"
+	"net/url"
 	"os"
-	"strings"
 
-	"github.com/cli/go-gh/v2"
 	"github.com/cli/go-gh/v2/pkg/api"
+	"github.com/cli/go-gh/v2/pkg/repository"
 )
 
 // Client provides GitHub API access with both REST and GraphQL
 type Client struct {
 	rest    *api.RESTClient
 	graphql *api.GraphQLClient
+	// http performs the requests with a raw body, e.g. release assets
+	http *http.Client
 }
 
+// Environment variables configuring NewClient. The root command sets them
+// from its persistent flags.
+const (
+	// EnvDebug enables debug diagnostics such as the remaining API quota
+	EnvDebug = "DEBUG"
+	// EnvRecord is the path of a cassette file recording every interaction
+	EnvRecord = "GH_NS8_RECORD"
+	// EnvReplay is the path of a cassette file to serve responses from,
+	// without network access
+	EnvReplay = "GH_NS8_REPLAY"
+	// EnvNoCache disables the on-disk response cache
+	EnvNoCache = "GH_NS8_NO_CACHE"
+	// EnvRefresh ignores cached responses and replaces them with fresh ones
+	EnvRefresh = "GH_NS8_REFRESH"
+)
+
 // NewClient creates a new GitHub API client using default gh configuration
 func NewClient() (*Client, error) {
-	rest, err := api.DefaultRESTClient()
+	if path := os.Getenv(EnvReplay); path != "" {
+		replay, err := loadReplayTransport(path)
+		if err != nil {
+			return nil, err
+		}
+		// Replayed runs are offline: no credentials are needed
+		return newClient(api.ClientOptions{
+			Host:      replay.host(),
+			AuthToken: "replay",
+			Transport: replay,
+		})
+	}
+
+	var transport http.RoundTripper = newRateLimitTransport(nil, os.Stderr, debugWriter())
+	if os.Getenv(EnvNoCache) == "" {
+		transport = newCacheTransport(transport, CacheDir(), Host(), os.Getenv(EnvRefresh) != "", debugWriter())
+	}
+	if path := os.Getenv(EnvRecord); path != "" {
+		recorder, err := newRecordingTransport(transport, path)
+		if err != nil {
+			return nil, err
+		}
+		transport = recorder
+	}
+
+	return newClient(api.ClientOptions{Host: Host(), Transport: transport})
+}
+
+// newClient creates the REST and GraphQL clients sharing opts.Transport
+func newClient(opts api.ClientOptions) (*Client, error) {
+	rest, err := api.NewRESTClient(opts)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create REST client: %w", err)
 	}
 
-	graphql, err := api.DefaultGraphQLClient()
+	// The sub-issues API used by GetParentIssueNumber is behind a feature header
+	graphqlOpts := opts
+	graphqlOpts.Headers = map[string]string{"GraphQL-Features": "sub_issues"}
+	for key, value := range opts.Headers {
+		graphqlOpts.Headers[key] = value
+	}
+
+	graphql, err := api.NewGraphQLClient(graphqlOpts)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create GraphQL client: %w", err)
 	}
 
+	httpClient, err := api.NewHTTPClient(opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create HTTP client: %w", err)
+	}
+
 	return &Client{
 		rest:    rest,
 		graphql: graphql,
+		http:    httpClient,
 	}, nil
 }
 
+// debugWriter returns where debug diagnostics are written, nil when the
+// --debug flag is not set.
+func debugWriter() io.Writer {
+	if os.Getenv(EnvDebug) == "" {
+		return nil
+	}
+	return os.Stderr
+}
+
+// get performs a REST GET request and classifies its error
+func (c *Client) get(path string, response interface{}) error {
+	return apiError(c.rest.Get(path, response))
+}
+
+// do performs a REST request with a JSON encoded body and classifies its error
+func (c *Client) do(method, path string, body interface{}, response interface{}) error {
+	var reader io.Reader
+	if body != nil {
+		payload, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		reader = bytes.NewReader(payload)
+	}
+	return apiError(c.rest.Do(method, path, reader, response))
+}
+
+// query performs a GraphQL request and classifies its error
+func (c *Client) query(query string, variables map[string]interface{}, response interface{}) error {
+	return apiError(c.graphql.Do(query, variables, response))
+}
+
 // Repository represents basic repo info
 type Repository struct {
 	Owner struct {
 		Login string `json:"login"`
 	} `json:"owner"`
-	Name             string `json:"name"`
-	DefaultBranchRef struct {
-		Name string `json:"name"`
-	} `json:"defaultBranchRef"`
+	Name          string `json:"name"`
+	DefaultBranch string `json:"default_branch"`
 }
 
 // GetRepository fetches repository information
 func (c *Client) GetRepository(repo string) (*Repository, error) {
 	var result Repository
-	err := c.rest.Get(fmt.Sprintf("repos/%s", repo), &result)
+	err := c.get(fmt.Sprintf("repos/%s", repo), &result)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get repository: %w", err)
 	}
@@ -61,10 +154,11 @@ type Commit struct {
 	SHA string `json:"sha"`
 }
 
-// GetLatestCommit gets the latest commit SHA from default branch
-func (c *Client) GetLatestCommit(repo string) (string, error) {
+// GetLatestCommit gets the latest commit SHA of a branch
+func (c *Client) GetLatestCommit(repo, branch string) (string, error) {
 	var commits []Commit
-	err := c.rest.Get(fmt.Sprintf("repos/%s/commits", repo), &commits)
+	path := fmt.Sprintf("repos/%s/commits?sha=%s", repo, url.QueryEscape(branch))
+	err := c.get(withPerPage(path, 1), &commits)
 	if err != nil {
 		return "", fmt.Errorf("failed to get commits: %w", err)
 	}
@@ -74,148 +168,397 @@ func (c *Client) GetLatestCommit(repo string) (string, error) {
 	return commits[0].SHA, nil
 }
 
-// GetCommitSHA gets the SHA for a git ref
+// gitObject is the object a git ref or tag points to
+type gitObject struct {
+	SHA  string `json:"sha"`
+	Type string `json:"type"`
+}
+
+// GetCommitSHA gets the commit SHA of a git ref. Annotated tags point to a
+// tag object, which is followed to its commit.
 func (c *Client) GetCommitSHA(repo, ref string) (string, error) {
 	var result struct {
-		Object struct {
-			SHA string `json:"sha"`
-		} `json:"object"`
+		Object gitObject `json:"object"`
 	}
-	err := c.rest.Get(fmt.Sprintf("repos/%s/git/ref/%s", repo, ref), &result)
+	err := c.get(fmt.Sprintf("repos/%s/git/ref/%s", repo, ref), &result)
 	if err != nil {
 		return "", fmt.Errorf("failed to get ref: %w", err)
 	}
-	return result.Object.SHA, nil
+
+	object := result.Object
+	for object.Type == "tag" {
+		var tag struct {
+			Object gitObject `json:"object"`
+		}
+		if err := c.get(fmt.Sprintf("repos/%s/git/tags/%s", repo, object.SHA), &tag); err != nil {
+			return "", fmt.Errorf("failed to get tag object %s: %w", object.SHA, err)
+		}
+		object = tag.Object
+	}
+	return object.SHA, nil
 }
 
-// CompareCommits compares two commits
+// CompareResult holds the commits between two refs
 type CompareResult struct {
 	Commits []struct {
 		SHA string `json:"sha"`
 	} `json:"commits"`
 }
 
+// CompareCommits compares two commits, following pagination so ranges
+// longer than a single page return every commit
 func (c *Client) CompareCommits(repo, base, head string) (*CompareResult, error) {
 	var result CompareResult
-	err := c.rest.Get(fmt.Sprintf("repos/%s/compare/%s...%s", repo, base, head), &result)
+	path := withPerPage(fmt.Sprintf("repos/%s/compare/%s...%s", repo, base, head), perPage)
+	err := c.getPages(path, func(body []byte) error {
+		var page CompareResult
+		if err := json.Unmarshal(body, &page); err != nil {
+			return err
+		}
+		result.Commits = append(result.Commits, page.Commits...)
+		return nil
+	})
 	if err != nil {
 		return nil, fmt.Errorf("failed to compare commits: %w", err)
 	}
 	return &result, nil
 }
 
-// GetMergeBase gets the merge base between two commits
-func (c *Client) GetMergeBase(repo, base, head string) (string, error) {
+// GetCompareStatus tells how head relates to base: "identical", "ahead",
+// "behind" or "diverged". Only the first page of the comparison is fetched.
+func (c *Client) GetCompareStatus(repo, base, head string) (string, error) {
 	var result struct {
-		SHA string `json:"sha"`
+		Status string `json:"status"`
 	}
-	body := strings.NewReader(fmt.Sprintf(`{"base":"%s","head":"%s"}`, base, head))
-	err := c.rest.Post(fmt.Sprintf("repos/%s/merge-base", repo), body, &result)
+	err := c.get(withPerPage(fmt.Sprintf("repos/%s/compare/%s...%s", repo, base, head), 1), &result)
 	if err != nil {
-		return "", fmt.Errorf("failed to get merge base: %w", err)
+		return "", fmt.Errorf("failed to compare commits: %w", err)
 	}
-	return result.SHA, nil
+	return result.Status, nil
+}
+
+// GetFileContent gets the content of a file on the default branch. A missing
+// file returns ErrNotFound.
+func (c *Client) GetFileContent(repo, path string) ([]byte, error) {
+	file, err := c.GetFile(repo, path, "")
+	if err != nil {
+		return nil, err
+	}
+	return file.Content, nil
 }
 
 // Release represents a GitHub release
 type Release struct {
+	DatabaseID   int64  `json:"databaseId"`
 	TagName      string `json:"tagName"`
 	Name         string `json:"name"`
 	IsPrerelease bool   `json:"isPrerelease"`
+	IsDraft      bool   `json:"isDraft"`
 	CreatedAt    string `json:"createdAt"`
+	// Target is the commit or branch a draft will be tagged on, set by
+	// ListDraftReleases and CreateRelease only
+	Target string `json:"-"`
+}
+
+// releaseFields are the GraphQL fields decoded into Release
+const releaseFields = `
+	databaseId
+	tagName
+	name
+	isPrerelease
+	isDraft
+	createdAt
+`
+
+// releasesPage is one page of the releases connection
+type releasesPage struct {
+	Repository struct {
+		Releases struct {
+			Nodes    []Release `json:"nodes"`
+			PageInfo pageInfo  `json:"pageInfo"`
+		} `json:"releases"`
+	} `json:"repository"`
 }
 
-// ListReleases lists releases
+func (p releasesPage) pageInfo() pageInfo {
+	return p.Repository.Releases.PageInfo
+}
+
+// ListReleases lists up to limit published releases, newest first. Drafts
+// are listed by ListDraftReleases.
 func (c *Client) ListReleases(repo string, limit int, excludePreReleases bool) ([]Release, error) {
-	args := []string{"release", "list", "--repo", repo, "--json", "tagName,name,isPrerelease,createdAt", "--limit", fmt.Sprintf("%d", limit)}
-	if excludePreReleases {
-		args = append(args, "--exclude-pre-releases")
+	owner, repoName, err := splitRepo(repo)
+	if err != nil {
+		return nil, err
 	}
 
-	stdout, _, err := gh.Exec(args...)
+	query := `
+		query($owner: String!, $repo: String!, $first: Int!, $after: String) {
+			repository(owner: $owner, name: $repo) {
+				releases(first: $first, after: $after, orderBy: {field: CREATED_AT, direction: DESC}) {
+					nodes {` + releaseFields + `}
+					pageInfo {
+						hasNextPage
+						endCursor
+					}
+				}
+			}
+		}
+	`
+
+	// Pre-releases are filtered client side, so fetch full pages when skipping them
+	pageSize := min(limit, perPage)
+	if excludePreReleases {
+		pageSize = perPage
+	}
+
+	releases := make([]Release, 0, limit)
+	err = paginateGraphQL(c, query, map[string]interface{}{
+		"owner": owner,
+		"repo":  repoName,
+		"first": pageSize,
+	}, func(page releasesPage) bool {
+		for _, release := range page.Repository.Releases.Nodes {
+			if release.IsDraft || (excludePreReleases && release.IsPrerelease) {
+				continue
+			}
+			releases = append(releases, release)
+			if len(releases) == limit {
+				return false
+			}
+		}
+		return true
+	})
 	if err != nil {
 		return nil, fmt.Errorf("failed to list releases: %w", err)
 	}
 
-	var releases []Release
-	if err := json.Unmarshal(stdout.Bytes(), &releases); err != nil {
-		return nil, fmt.Errorf("failed to parse releases: %w", err)
-	}
-
 	return releases, nil
 }
 
 // ViewRelease gets details about a specific release
 func (c *Client) ViewRelease(repo, tag string) (*Release, error) {
-	args := []string{"release", "view", tag, "--repo", repo, "--json", "tagName,name,isPrerelease,createdAt"}
+	owner, repoName, err := splitRepo(repo)
+	if err != nil {
+		return nil, err
+	}
+
+	query := `
+		query($owner: String!, $repo: String!, $tag: String!) {
+			repository(owner: $owner, name: $repo) {
+				release(tagName: $tag) {` + releaseFields + `}
+			}
+		}
+	`
 
-	stdout, _, err := gh.Exec(args...)
+	var response struct {
+		Repository struct {
+			Release *Release `json:"release"`
+		} `json:"repository"`
+	}
+	err = c.query(query, map[string]interface{}{
+		"owner": owner,
+		"repo":  repoName,
+		"tag":   tag,
+	}, &response)
 	if err != nil {
 		return nil, fmt.Errorf("failed to view release: %w", err)
 	}
 
-	var release Release
-	if err := json.Unmarshal(stdout.Bytes(), &release); err != nil {
-		return nil, fmt.Errorf("failed to parse release: %w", err)
+	if response.Repository.Release == nil {
+		return nil, fmt.Errorf("failed to view release: release %s %w", tag, ErrNotFound)
 	}
 
-	return &release, nil
+	return response.Repository.Release, nil
 }
 
-// CreateRelease creates a new release using gh release create
-func (c *Client) CreateRelease(repo, tag, title string, draft, prerelease bool, target string, notesReader io.Reader) error {
-	args := []string{"release", "create", tag, "--repo", repo, "--title", title, "--generate-notes"}
-
-	if draft {
-		args = append(args, "--draft")
-	}
-	if prerelease {
-		args = append(args, "--prerelease")
-	}
-	if target != "" {
-		args = append(args, "--target", target)
+// ListDraftReleases lists the draft releases, newest first
+func (c *Client) ListDraftReleases(repo string) ([]Release, error) {
+	releases, err := getAll[struct {
+		ID              int64  `json:"id"`
+		TagName         string `json:"tag_name"`
+		Name            string `json:"name"`
+		Draft           bool   `json:"draft"`
+		Prerelease      bool   `json:"prerelease"`
+		CreatedAt       string `json:"created_at"`
+		TargetCommitish string `json:"target_commitish"`
+	}](c, fmt.Sprintf("repos/%s/releases", repo))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list draft releases: %w", err)
 	}
 
-	// gh.Exec cannot pipe stdin; write notes to a temp file instead
-	if notesReader != nil {
-		notesBytes, err := io.ReadAll(notesReader)
-		if err != nil {
-			return fmt.Errorf("failed to read notes: %w", err)
+	var drafts []Release
+	for _, release := range releases {
+		if !release.Draft {
+			continue
 		}
+		drafts = append(drafts, Release{
+			DatabaseID:   release.ID,
+			TagName:      release.TagName,
+			Name:         release.Name,
+			IsPrerelease: release.Prerelease,
+			IsDraft:      true,
+			CreatedAt:    release.CreatedAt,
+			Target:       release.TargetCommitish,
+		})
+	}
+	return drafts, nil
+}
 
-		tmpFile, err := os.CreateTemp("", "gh-ns8-release-notes-*.md")
-		if err != nil {
-			return fmt.Errorf("failed to create temp notes file: %w", err)
-		}
-		defer os.Remove(tmpFile.Name())
+// GetReleaseNotes gets the body of a release
+func (c *Client) GetReleaseNotes(repo, tag string) (string, error) {
+	owner, repoName, err := splitRepo(repo)
+	if err != nil {
+		return "", err
+	}
 
-		if _, err := tmpFile.Write(notesBytes); err != nil {
-			tmpFile.Close()
-			return fmt.Errorf("failed to write notes: %w", err)
+	query := `
+		query($owner: String!, $repo: String!, $tag: String!) {
+			repository(owner: $owner, name: $repo) {
+				release(tagName: $tag) {
+					description
+				}
+			}
 		}
-		tmpFile.Close()
+	`
 
-		args = append(args, "--notes-file", tmpFile.Name())
+	var response struct {
+		Repository struct {
+			Release *struct {
+				Description string `json:"description"`
+			} `json:"release"`
+		} `json:"repository"`
+	}
+	err = c.query(query, map[string]interface{}{
+		"owner": owner,
+		"repo":  repoName,
+		"tag":   tag,
+	}, &response)
+	if err != nil {
+		return "", fmt.Errorf("failed to get release notes: %w", err)
 	}
 
-	stdout, stderr, err := gh.Exec(args...)
+	if response.Repository.Release == nil {
+		return "", fmt.Errorf("failed to get release notes: release %s %w", tag, ErrNotFound)
+	}
+
+	return response.Repository.Release.Description, nil
+}
+
+// NewRelease describes a release to create
+type NewRelease struct {
+	Tag    string
+	Title  string
+	Target string
+	Draft  bool
+	// Prerelease marks the release as a pre-release
+	Prerelease bool
+	// Notes is the release body. With GenerateNotes, GitHub appends its
+	// generated notes to it.
+	Notes         string
+	GenerateNotes bool
+}
+
+// CreateRelease creates a new release and returns it
+func (c *Client) CreateRelease(repo string, release NewRelease) (*Release, error) {
+	body := map[string]interface{}{
+		"tag_name":               release.Tag,
+		"name":                   release.Title,
+		"draft":                  release.Draft,
+		"prerelease":             release.Prerelease,
+		"generate_release_notes": release.GenerateNotes,
+	}
+	if release.Target != "" {
+		body["target_commitish"] = release.Target
+	}
+	if release.Notes != "" {
+		body["body"] = release.Notes
+	}
+
+	var created struct {
+		ID         int64  `json:"id"`
+		TagName    string `json:"tag_name"`
+		Name       string `json:"name"`
+		Draft      bool   `json:"draft"`
+		Prerelease bool   `json:"prerelease"`
+		CreatedAt  string `json:"created_at"`
+	}
+	if err := c.do(http.MethodPost, fmt.Sprintf("repos/%s/releases", repo), body, &created); err != nil {
+		return nil, fmt.Errorf("failed to create release: %w", err)
+	}
+
+	return &Release{
+		DatabaseID:   created.ID,
+		TagName:      created.TagName,
+		Name:         created.Name,
+		IsPrerelease: created.Prerelease,
+		IsDraft:      created.Draft,
+		CreatedAt:    created.CreatedAt,
+		Target:       release.Target,
+	}, nil
+}
+
+// ReleaseUpdate describes the changes of an existing release, nil fields
+// are left unchanged
+type ReleaseUpdate struct {
+	Title      *string
+	Notes      *string
+	Target     *string
+	Draft      *bool
+	Prerelease *bool
+}
+
+// UpdateRelease updates a published release
+func (c *Client) UpdateRelease(repo, tag string, update ReleaseUpdate) error {
+	release, err := c.ViewRelease(repo, tag)
 	if err != nil {
-		errMsg := stderr.String()
-		if errMsg == "" {
-			errMsg = stdout.String()
-		}
-		return fmt.Errorf("failed to create release: %s", strings.TrimSpace(errMsg))
+		return fmt.Errorf("failed to update release: %w", err)
+	}
+	return c.UpdateReleaseByID(repo, release.DatabaseID, update)
+}
+
+// UpdateReleaseByID updates a release by its database ID, e.g. a draft
+// listed by ListDraftReleases, whose tag does not exist yet
+func (c *Client) UpdateReleaseByID(repo string, id int64, update ReleaseUpdate) error {
+	body := map[string]interface{}{}
+	if update.Title != nil {
+		body["name"] = *update.Title
+	}
+	if update.Notes != nil {
+		body["body"] = *update.Notes
+	}
+	if update.Target != nil {
+		body["target_commitish"] = *update.Target
+	}
+	if update.Draft != nil {
+		body["draft"] = *update.Draft
+	}
+	if update.Prerelease != nil {
+		body["prerelease"] = *update.Prerelease
 	}
 
+	if err := c.do(http.MethodPatch, fmt.Sprintf("repos/%s/releases/%d", repo, id), body, nil); err != nil {
+		return fmt.Errorf("failed to update release: %w", err)
+	}
 	return nil
 }
 
-// DeleteRelease deletes a release
+// DeleteRelease deletes a release, keeping its tag
 func (c *Client) DeleteRelease(repo, tag string) error {
-	_, _, err := gh.Exec("release", "delete", tag, "--repo", repo, "--yes")
+	release, err := c.ViewRelease(repo, tag)
 	if err != nil {
 		return fmt.Errorf("failed to delete release: %w", err)
 	}
+
+	return c.DeleteReleaseByID(repo, release.DatabaseID)
+}
+
+// DeleteReleaseByID deletes a release by its database ID, e.g. a draft
+// listed by ListDraftReleases
+func (c *Client) DeleteReleaseByID(repo string, id int64) error {
+	if err := c.do(http.MethodDelete, fmt.Sprintf("repos/%s/releases/%d", repo, id), nil, nil); err != nil {
+		return fmt.Errorf("failed to delete release: %w", err)
+	}
 	return nil
 }
 
@@ -236,14 +579,19 @@ type PullRequest struct {
 	Labels []struct {
 		Name string `json:"name"`
 	} `json:"labels"`
+	Head struct {
+		Ref string `json:"ref"`
+	} `json:"head"`
+	// ReviewDecision is not returned by the REST API: callers needing it
+	// fill it with GetPullRequestReviewDecision
+	ReviewDecision string `json:"-"`
 }
 
 // GetPullRequestsForCommit gets PRs associated with a commit
 func (c *Client) GetPullRequestsForCommit(repo, sha string) ([]int, error) {
-	var prs []struct {
+	prs, err := getAll[struct {
 		Number int `json:"number"`
-	}
-	err := c.rest.Get(fmt.Sprintf("repos/%s/commits/%s/pulls", repo, sha), &prs)
+	}](c, fmt.Sprintf("repos/%s/commits/%s/pulls", repo, sha))
 	if err != nil {
 		return nil, fmt.Errorf("failed to get PRs for commit: %w", err)
 	}
@@ -258,7 +606,7 @@ func (c *Client) GetPullRequestsForCommit(repo, sha string) ([]int, error) {
 // GetPullRequest gets PR details
 func (c *Client) GetPullRequest(repo string, number int) (*PullRequest, error) {
 	var pr PullRequest
-	err := c.rest.Get(fmt.Sprintf("repos/%s/pulls/%d", repo, number), &pr)
+	err := c.get(fmt.Sprintf("repos/%s/pulls/%d", repo, number), &pr)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get PR: %w", err)
 	}
@@ -278,7 +626,7 @@ type Issue struct {
 // GetIssue gets issue details
 func (c *Client) GetIssue(repo string, number int) (*Issue, error) {
 	var issue Issue
-	err := c.rest.Get(fmt.Sprintf("repos/%s/issues/%d", repo, number), &issue)
+	err := c.get(fmt.Sprintf("repos/%s/issues/%d", repo, number), &issue)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get issue: %w", err)
 	}
@@ -287,43 +635,22 @@ func (c *Client) GetIssue(repo string, number int) (*Issue, error) {
 
 // CreateIssueComment posts a comment on an issue and returns the comment URL
 func (c *Client) CreateIssueComment(repo string, number int, body string) (string, error) {
-	_, _, err := gh.Exec("issue", "comment", fmt.Sprintf("%d", number), "--repo", repo, "--body", body)
-	if err != nil {
-		return "", fmt.Errorf("failed to create comment: %w", err)
-	}
-
-	// Get the last comment to retrieve its URL
-	parts := strings.Split(repo, "/")
-	if len(parts) != 2 {
-		return "", fmt.Errorf("invalid repo format: %s", repo)
-	}
-	owner, repoName := parts[0], parts[1]
-
-	type Comment struct {
-		ID  int    `json:"id"`
+	var comment struct {
 		URL string `json:"html_url"`
 	}
-	var comments []Comment
-	err = c.rest.Get(fmt.Sprintf("repos/%s/%s/issues/%d/comments", owner, repoName, number), &comments)
+	err := c.do(http.MethodPost, fmt.Sprintf("repos/%s/issues/%d/comments", repo, number), map[string]string{"body": body}, &comment)
 	if err != nil {
-		return "", fmt.Errorf("failed to fetch comment URL: %w", err)
-	}
-
-	if len(comments) == 0 {
-		return "", fmt.Errorf("no comments found after creation")
+		return "", fmt.Errorf("failed to create comment: %w", err)
 	}
-
-	// Return the URL of the last comment (most recent)
-	return comments[len(comments)-1].URL, nil
+	return comment.URL, nil
 }
 
 // GetParentIssueNumber gets the parent issue using GraphQL sub-issues API
 func (c *Client) GetParentIssueNumber(repo string, issueNumber int) (int, error) {
-	parts := strings.Split(repo, "/")
-	if len(parts) != 2 {
-		return 0, fmt.Errorf("invalid repo format: %s", repo)
+	owner, repoName, err := splitRepo(repo)
+	if err != nil {
+		return 0, err
 	}
-	owner, repoName := parts[0], parts[1]
 
 	query := `
 		query($owner: String!, $repo: String!, $issueNumber: Int!) {
@@ -338,80 +665,166 @@ func (c *Client) GetParentIssueNumber(repo string, issueNumber int) (int, error)
 	`
 
 	var response struct {
-		Data struct {
-			Repository struct {
-				Issue struct {
-					Parent *struct {
-						Number int `json:"number"`
-					} `json:"parent"`
-				} `json:"issue"`
-			} `json:"repository"`
-		} `json:"data"`
-	}
-
-	// Use gh api with GraphQL-Features header for sub_issues
-	args := []string{
-		"api", "graphql",
-		"-H", "GraphQL-Features: sub_issues",
-		"-f", fmt.Sprintf("query=%s", query),
-		"-F", fmt.Sprintf("owner=%s", owner),
-		"-F", fmt.Sprintf("repo=%s", repoName),
-		"-F", fmt.Sprintf("issueNumber=%d", issueNumber),
-	}
-
-	stdout, _, err := gh.Exec(args...)
+		Repository struct {
+			Issue struct {
+				Parent *struct {
+					Number int `json:"number"`
+				} `json:"parent"`
+			} `json:"issue"`
+		} `json:"repository"`
+	}
+
+	err = c.query(query, map[string]interface{}{
+		"owner":       owner,
+		"repo":        repoName,
+		"issueNumber": issueNumber,
+	}, &response)
 	if err != nil {
 		return 0, fmt.Errorf("failed to query parent issue: %w", err)
 	}
 
-	if err := json.Unmarshal(stdout.Bytes(), &response); err != nil {
-		return 0, fmt.Errorf("failed to parse GraphQL response: %w", err)
+	if response.Repository.Issue.Parent != nil {
+		return response.Repository.Issue.Parent.Number, nil
 	}
 
-	if response.Data.Repository.Issue.Parent != nil {
-		return response.Data.Repository.Issue.Parent.Number, nil
+	return 0, nil // No parent
+}
+
+// Review decisions of a pull request, as returned by GetPullRequestReviewDecision
+const (
+	ReviewApproved         = "APPROVED"
+	ReviewChangesRequested = "CHANGES_REQUESTED"
+	ReviewRequired         = "REVIEW_REQUIRED"
+)
+
+// GetPullRequestReviewDecision gets the review decision of a pull request.
+// It is empty when the base branch does not require reviews and nobody
+// approved or requested changes.
+func (c *Client) GetPullRequestReviewDecision(repo string, number int) (string, error) {
+	owner, repoName, err := splitRepo(repo)
+	if err != nil {
+		return "", err
 	}
 
-	return 0, nil // No parent
+	query := `
+		query($owner: String!, $repo: String!, $number: Int!) {
+			repository(owner: $owner, name: $repo) {
+				pullRequest(number: $number) {
+					reviewDecision
+				}
+			}
+		}
+	`
+
+	var response struct {
+		Repository struct {
+			PullRequest struct {
+				ReviewDecision *string `json:"reviewDecision"`
+			} `json:"pullRequest"`
+		} `json:"repository"`
+	}
+
+	err = c.query(query, map[string]interface{}{
+		"owner":  owner,
+		"repo":   repoName,
+		"number": number,
+	}, &response)
+	if err != nil {
+		return "", fmt.Errorf("failed to query PR review decision: %w", err)
+	}
+
+	if response.Repository.PullRequest.ReviewDecision == nil {
+		return "", nil
+	}
+	return *response.Repository.PullRequest.ReviewDecision, nil
 }
 
 // GetCurrentRepository gets the current repository from the working directory
 func GetCurrentRepository() (string, error) {
-	stdout, _, err := gh.Exec("repo", "view", "--json", "owner,name", "--jq", ".owner.login + \"/\" + .name")
+	repo, err := repository.Current()
 	if err != nil {
 		return "", fmt.Errorf("failed to get current repository: %w", err)
 	}
-	return strings.TrimSpace(stdout.String()), nil
+	return fmt.Sprintf("%s/%s", repo.Owner, repo.Name), nil
 }
 
 // OpenPullRequest represents a minimal open PR
 type OpenPullRequest struct {
-	Number int    `json:"number"`
-	URL    string `json:"url"`
-	Body   string `json:"body"`
-	Author struct {
+	Number      int    `json:"number"`
+	URL         string `json:"url"`
+	Title       string `json:"title"`
+	Body        string `json:"body"`
+	HeadRefName string `json:"headRefName"`
+	Author      struct {
 		Login string `json:"login"`
 	} `json:"author"`
+	Labels struct {
+		Nodes []struct {
+			Name string `json:"name"`
+		} `json:"nodes"`
+	} `json:"labels"`
 }
 
-// ListOpenPullRequests lists open PRs with enough metadata to detect linked issues.
-func (c *Client) ListOpenPullRequests(repo string) ([]OpenPullRequest, error) {
-	args := []string{
-		"pr", "list",
-		"--repo", repo,
-		"--state", "open",
-		"--limit", "100",
-		"--json", "number,url,body,author",
-	}
+// openPullRequestsPage is one page of the open pull requests connection
+type openPullRequestsPage struct {
+	Repository struct {
+		PullRequests struct {
+			Nodes    []OpenPullRequest `json:"nodes"`
+			PageInfo pageInfo          `json:"pageInfo"`
+		} `json:"pullRequests"`
+	} `json:"repository"`
+}
+
+func (p openPullRequestsPage) pageInfo() pageInfo {
+	return p.Repository.PullRequests.PageInfo
+}
 
-	stdout, _, err := gh.Exec(args...)
+// ListOpenPullRequests lists open PRs with enough metadata to detect linked
+// issues and to categorize them.
+func (c *Client) ListOpenPullRequests(repo string) ([]OpenPullRequest, error) {
+	owner, repoName, err := splitRepo(repo)
 	if err != nil {
-		return nil, fmt.Errorf("failed to list open PRs: %w", err)
+		return nil, err
 	}
 
+	query := `
+		query($owner: String!, $repo: String!, $after: String) {
+			repository(owner: $owner, name: $repo) {
+				pullRequests(states: OPEN, first: 100, after: $after, orderBy: {field: CREATED_AT, direction: DESC}) {
+					nodes {
+						number
+						url
+						title
+						body
+						headRefName
+						author {
+							login
+						}
+						labels(first: 100) {
+							nodes {
+								name
+							}
+						}
+					}
+					pageInfo {
+						hasNextPage
+						endCursor
+					}
+				}
+			}
+		}
+	`
+
 	var prs []OpenPullRequest
-	if err := json.Unmarshal(stdout.Bytes(), &prs); err != nil {
-		return nil, fmt.Errorf("failed to parse open PRs: %w", err)
+	err = paginateGraphQL(c, query, map[string]interface{}{
+		"owner": owner,
+		"repo":  repoName,
+	}, func(page openPullRequestsPage) bool {
+		prs = append(prs, page.Repository.PullRequests.Nodes...)
+		return true
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list open PRs: %w", err)
 	}
 
 	return prs, nil
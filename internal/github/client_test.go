@@ -0,0 +1,553 @@
+package github
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"testing"
+
+	"github.com/cli/go-gh/v2/pkg/api"
+)
+
+// rewriteTransport sends every request to the test server, keeping the path.
+type rewriteTransport struct {
+	target *url.URL
+}
+
+func (t rewriteTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	clone := req.Clone(req.Context())
+	clone.URL.Scheme = t.target.Scheme
+	clone.URL.Host = t.target.Host
+	return http.DefaultTransport.RoundTrip(clone)
+}
+
+func newTestClient(t *testing.T, handler http.Handler) *Client {
+	t.Helper()
+
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+
+	target, err := url.Parse(server.URL)
+	if err != nil {
+		t.Fatalf("url.Parse() returned error: %v", err)
+	}
+
+	client, err := newClient(api.ClientOptions{
+		Host:         "github.com",
+		AuthToken:    "test-token",
+		Transport:    rewriteTransport{target: target},
+		LogIgnoreEnv: true,
+	})
+	if err != nil {
+		t.Fatalf("newClient() returned error: %v", err)
+	}
+	return client
+}
+
+// servePages serves pages of a REST list endpoint, linking each page to the
+// next one with a Link header.
+func servePages(w http.ResponseWriter, r *http.Request, pages ...interface{}) {
+	page := 1
+	fmt.Sscanf(r.URL.Query().Get("page"), "%d", &page)
+	if page < len(pages) {
+		next := *r.URL
+		query := next.Query()
+		query.Set("page", fmt.Sprintf("%d", page+1))
+		next.RawQuery = query.Encode()
+		w.Header().Set("Link", fmt.Sprintf(`<https://api.github.com%s>; rel="next", <https://api.github.com%s>; rel="last"`, next.RequestURI(), next.RequestURI()))
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(pages[page-1])
+}
+
+func shaPage(shas ...string) []map[string]string {
+	page := make([]map[string]string, len(shas))
+	for i, sha := range shas {
+		page[i] = map[string]string{"sha": sha}
+	}
+	return page
+}
+
+func TestCompareCommitsFollowsPagination(t *testing.T) {
+	client := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/repos/NethServer/ns8-mail/compare/1.0.0...main" {
+			t.Errorf("unexpected path %s", r.URL.Path)
+		}
+		if r.URL.Query().Get("per_page") != "100" {
+			t.Errorf("per_page = %q, want 100", r.URL.Query().Get("per_page"))
+		}
+		servePages(w, r,
+			map[string]interface{}{"commits": shaPage("a", "b")},
+			map[string]interface{}{"commits": shaPage("c")},
+		)
+	}))
+
+	result, err := client.CompareCommits("NethServer/ns8-mail", "1.0.0", "main")
+	if err != nil {
+		t.Fatalf("CompareCommits() returned error: %v", err)
+	}
+
+	var got []string
+	for _, commit := range result.Commits {
+		got = append(got, commit.SHA)
+	}
+	if want := []string{"a", "b", "c"}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("CompareCommits() commits = %v, want %v", got, want)
+	}
+}
+
+func TestGetPullRequestsForCommitFollowsPagination(t *testing.T) {
+	client := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		servePages(w, r,
+			[]map[string]int{{"number": 1}, {"number": 2}},
+			[]map[string]int{{"number": 3}},
+		)
+	}))
+
+	got, err := client.GetPullRequestsForCommit("NethServer/ns8-mail", "abc")
+	if err != nil {
+		t.Fatalf("GetPullRequestsForCommit() returned error: %v", err)
+	}
+	if want := []int{1, 2, 3}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("GetPullRequestsForCommit() = %v, want %v", got, want)
+	}
+}
+
+func TestGetLatestCommitRequestsSingleCommit(t *testing.T) {
+	client := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("per_page") != "1" {
+			t.Errorf("per_page = %q, want 1", r.URL.Query().Get("per_page"))
+		}
+		if r.URL.Query().Get("sha") != "stable-1.4" {
+			t.Errorf("sha = %q, want stable-1.4", r.URL.Query().Get("sha"))
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(shaPage("head-sha"))
+	}))
+
+	got, err := client.GetLatestCommit("NethServer/ns8-mail", "stable-1.4")
+	if err != nil {
+		t.Fatalf("GetLatestCommit() returned error: %v", err)
+	}
+	if got != "head-sha" {
+		t.Fatalf("GetLatestCommit() = %q, want %q", got, "head-sha")
+	}
+}
+
+func TestGetCommitSHAFollowsAnnotatedTags(t *testing.T) {
+	client := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/repos/NethServer/ns8-mail/git/ref/tags/1.2.0":
+			io.WriteString(w, `{"object":{"sha":"tag-sha","type":"tag"}}`)
+		case "/repos/NethServer/ns8-mail/git/ref/tags/1.1.0":
+			io.WriteString(w, `{"object":{"sha":"commit-1","type":"commit"}}`)
+		case "/repos/NethServer/ns8-mail/git/tags/tag-sha":
+			io.WriteString(w, `{"sha":"tag-sha","object":{"sha":"commit-2","type":"commit"}}`)
+		default:
+			t.Errorf("unexpected request %s", r.URL.Path)
+		}
+	}))
+
+	for ref, want := range map[string]string{"tags/1.2.0": "commit-2", "tags/1.1.0": "commit-1"} {
+		got, err := client.GetCommitSHA("NethServer/ns8-mail", ref)
+		if err != nil {
+			t.Fatalf("GetCommitSHA(%s) returned error: %v", ref, err)
+		}
+		if got != want {
+			t.Fatalf("GetCommitSHA(%s) = %q, want %q", ref, got, want)
+		}
+	}
+}
+
+func TestGetCompareStatusFetchesSingleCommit(t *testing.T) {
+	client := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/repos/NethServer/ns8-mail/compare/stable-1.4...1.5.0" {
+			t.Errorf("path = %q, want the compare endpoint", r.URL.Path)
+		}
+		if r.URL.Query().Get("per_page") != "1" {
+			t.Errorf("per_page = %q, want 1", r.URL.Query().Get("per_page"))
+		}
+		w.Header().Set("Content-Type", "application/json")
+		io.WriteString(w, `{"status":"diverged","commits":[{"sha":"a"}]}`)
+	}))
+
+	got, err := client.GetCompareStatus("NethServer/ns8-mail", "stable-1.4", "1.5.0")
+	if err != nil {
+		t.Fatalf("GetCompareStatus() returned error: %v", err)
+	}
+	if got != "diverged" {
+		t.Fatalf("GetCompareStatus() = %q, want %q", got, "diverged")
+	}
+}
+
+func TestGetFileContentDecodesBase64(t *testing.T) {
+	client := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/repos/NethServer/ns8-mail/contents/.github/ns8-release.yml" {
+			t.Errorf("path = %q, want the contents endpoint", r.URL.Path)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		io.WriteString(w, `{"encoding":"base64","content":"YnJhbmNoOiBz\ndGFibGUK"}`)
+	}))
+
+	got, err := client.GetFileContent("NethServer/ns8-mail", ".github/ns8-release.yml")
+	if err != nil {
+		t.Fatalf("GetFileContent() returned error: %v", err)
+	}
+	if string(got) != "branch: stable\n" {
+		t.Fatalf("GetFileContent() = %q, want %q", got, "branch: stable\n")
+	}
+}
+
+func TestGetFileContentReturnsNotFound(t *testing.T) {
+	client := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusNotFound)
+		io.WriteString(w, `{"message":"Not Found"}`)
+	}))
+
+	_, err := client.GetFileContent("NethServer/ns8-mail", ".github/ns8-release.yml")
+	if !errors.Is(err, ErrNotFound) {
+		t.Fatalf("GetFileContent() error = %v, want ErrNotFound", err)
+	}
+}
+
+func TestListOpenPullRequestsFollowsGraphQLCursor(t *testing.T) {
+	var cursors []interface{}
+	client := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/graphql" {
+			t.Errorf("unexpected path %s", r.URL.Path)
+		}
+		body, _ := io.ReadAll(r.Body)
+		var request struct {
+			Variables map[string]interface{} `json:"variables"`
+		}
+		json.Unmarshal(body, &request)
+		cursors = append(cursors, request.Variables["after"])
+
+		w.Header().Set("Content-Type", "application/json")
+		if request.Variables["after"] == nil {
+			io.WriteString(w, `{"data":{"repository":{"pullRequests":{
+				"nodes":[{"number":1,"url":"u1","body":"b1","author":{"login":"weblate"}}],
+				"pageInfo":{"hasNextPage":true,"endCursor":"cursor-1"}}}}}`)
+			return
+		}
+		io.WriteString(w, `{"data":{"repository":{"pullRequests":{
+			"nodes":[{"number":2,"url":"u2","body":"b2","author":{"login":"octocat"}}],
+			"pageInfo":{"hasNextPage":false,"endCursor":"cursor-2"}}}}}`)
+	}))
+
+	got, err := client.ListOpenPullRequests("NethServer/ns8-mail")
+	if err != nil {
+		t.Fatalf("ListOpenPullRequests() returned error: %v", err)
+	}
+
+	if len(got) != 2 || got[0].Number != 1 || got[1].Number != 2 || got[0].Author.Login != "weblate" {
+		t.Fatalf("ListOpenPullRequests() = %+v, want PRs from both pages", got)
+	}
+	if want := []interface{}{nil, "cursor-1"}; !reflect.DeepEqual(cursors, want) {
+		t.Fatalf("cursors = %v, want %v", cursors, want)
+	}
+}
+
+func TestNextPageURL(t *testing.T) {
+	link := `<https://api.github.com/repositories/1/pulls?page=2>; rel="next", <https://api.github.com/repositories/1/pulls?page=5>; rel="last"`
+	if got := nextPageURL(link); got != "https://api.github.com/repositories/1/pulls?page=2" {
+		t.Fatalf("nextPageURL() = %q, want page 2 URL", got)
+	}
+
+	last := `<https://api.github.com/repositories/1/pulls?page=1>; rel="first", <https://api.github.com/repositories/1/pulls?page=4>; rel="prev"`
+	if got := nextPageURL(last); got != "" {
+		t.Fatalf("nextPageURL() = %q, want empty string on last page", got)
+	}
+}
+
+func TestListReleasesFiltersPreReleasesAcrossPages(t *testing.T) {
+	var pageSizes []interface{}
+	client := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var request struct {
+			Variables map[string]interface{} `json:"variables"`
+		}
+		json.NewDecoder(r.Body).Decode(&request)
+		pageSizes = append(pageSizes, request.Variables["first"])
+
+		w.Header().Set("Content-Type", "application/json")
+		if request.Variables["after"] == nil {
+			io.WriteString(w, `{"data":{"repository":{"releases":{
+				"nodes":[{"tagName":"1.1.0-testing.1","isPrerelease":true}],
+				"pageInfo":{"hasNextPage":true,"endCursor":"cursor-1"}}}}}`)
+			return
+		}
+		io.WriteString(w, `{"data":{"repository":{"releases":{
+			"nodes":[{"databaseId":7,"tagName":"1.0.0","name":"1.0.0","isPrerelease":false,"createdAt":"2024-01-01T00:00:00Z"},{"tagName":"0.9.0"}],
+			"pageInfo":{"hasNextPage":true,"endCursor":"cursor-2"}}}}}`)
+	}))
+
+	got, err := client.ListReleases("NethServer/ns8-mail", 1, true)
+	if err != nil {
+		t.Fatalf("ListReleases() returned error: %v", err)
+	}
+
+	want := []Release{{DatabaseID: 7, TagName: "1.0.0", Name: "1.0.0", CreatedAt: "2024-01-01T00:00:00Z"}}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("ListReleases() = %+v, want %+v", got, want)
+	}
+	if len(pageSizes) != 2 || pageSizes[0] != float64(perPage) {
+		t.Fatalf("page sizes = %v, want two full pages", pageSizes)
+	}
+}
+
+func TestListReleasesSkipsDrafts(t *testing.T) {
+	client := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		io.WriteString(w, `{"data":{"repository":{"releases":{
+			"nodes":[{"tagName":"1.1.0","isDraft":true},{"tagName":"1.0.1-testing.1","isPrerelease":true},{"tagName":"1.0.0"}],
+			"pageInfo":{"hasNextPage":false,"endCursor":"cursor-1"}}}}}`)
+	}))
+
+	got, err := client.ListReleases("NethServer/ns8-mail", 10, false)
+	if err != nil {
+		t.Fatalf("ListReleases() returned error: %v", err)
+	}
+	want := []Release{{TagName: "1.0.1-testing.1", IsPrerelease: true}, {TagName: "1.0.0"}}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("ListReleases() = %+v, want %+v", got, want)
+	}
+}
+
+func TestListDraftReleasesKeepsDrafts(t *testing.T) {
+	client := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/repos/NethServer/ns8-mail/releases" {
+			t.Errorf("path = %q, want the releases endpoint", r.URL.Path)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		io.WriteString(w, `[
+			{"id":9,"tag_name":"1.1.0","name":"1.1.0","draft":true,"created_at":"2024-02-01T00:00:00Z","target_commitish":"abc123"},
+			{"id":8,"tag_name":"1.0.0","name":"1.0.0","draft":false}
+		]`)
+	}))
+
+	got, err := client.ListDraftReleases("NethServer/ns8-mail")
+	if err != nil {
+		t.Fatalf("ListDraftReleases() returned error: %v", err)
+	}
+	want := []Release{{DatabaseID: 9, TagName: "1.1.0", Name: "1.1.0", IsDraft: true, CreatedAt: "2024-02-01T00:00:00Z", Target: "abc123"}}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("ListDraftReleases() = %+v, want %+v", got, want)
+	}
+}
+
+func TestViewReleaseReturnsNotFound(t *testing.T) {
+	client := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		io.WriteString(w, `{"data":{"repository":{"release":null}}}`)
+	}))
+
+	_, err := client.ViewRelease("NethServer/ns8-mail", "9.9.9")
+	if !errors.Is(err, ErrNotFound) {
+		t.Fatalf("ViewRelease() error = %v, want ErrNotFound", err)
+	}
+}
+
+func TestCreateReleasePostsNotesAndTarget(t *testing.T) {
+	var got map[string]interface{}
+	client := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.URL.Path != "/repos/NethServer/ns8-mail/releases" {
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		}
+		json.NewDecoder(r.Body).Decode(&got)
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusCreated)
+		io.WriteString(w, `{"id":1,"tag_name":"1.2.0","prerelease":true}`)
+	}))
+
+	release, err := client.CreateRelease("NethServer/ns8-mail", NewRelease{
+		Tag:           "1.2.0",
+		Title:         "1.2.0",
+		Target:        "abc123",
+		Prerelease:    true,
+		Notes:         "## Linked Issues\n",
+		GenerateNotes: true,
+	})
+	if err != nil {
+		t.Fatalf("CreateRelease() returned error: %v", err)
+	}
+	if release.DatabaseID != 1 || release.TagName != "1.2.0" || !release.IsPrerelease || release.Target != "abc123" {
+		t.Fatalf("CreateRelease() = %+v, want release 1 of 1.2.0", release)
+	}
+
+	want := map[string]interface{}{
+		"tag_name":               "1.2.0",
+		"name":                   "1.2.0",
+		"draft":                  false,
+		"prerelease":             true,
+		"generate_release_notes": true,
+		"target_commitish":       "abc123",
+		"body":                   "## Linked Issues\n",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("CreateRelease() body = %v, want %v", got, want)
+	}
+}
+
+func TestCreateReleaseReturnsValidationFailed(t *testing.T) {
+	client := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusUnprocessableEntity)
+		io.WriteString(w, `{"message":"Validation Failed","errors":[{"resource":"Release","code":"already_exists","field":"tag_name"}]}`)
+	}))
+
+	_, err := client.CreateRelease("NethServer/ns8-mail", NewRelease{Tag: "1.2.0", Title: "1.2.0"})
+	if !errors.Is(err, ErrValidationFailed) {
+		t.Fatalf("CreateRelease() error = %v, want ErrValidationFailed", err)
+	}
+
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusUnprocessableEntity {
+		t.Fatalf("CreateRelease() error = %v, want APIError with status 422", err)
+	}
+}
+
+func TestGetReleaseNotesReadsDescription(t *testing.T) {
+	client := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		io.WriteString(w, `{"data":{"repository":{"release":{"description":"## Linked Issues\n"}}}}`)
+	}))
+
+	got, err := client.GetReleaseNotes("NethServer/ns8-mail", "1.2.0")
+	if err != nil {
+		t.Fatalf("GetReleaseNotes() returned error: %v", err)
+	}
+	if got != "## Linked Issues\n" {
+		t.Fatalf("GetReleaseNotes() = %q, want the release description", got)
+	}
+}
+
+func TestGetReleaseNotesReturnsNotFound(t *testing.T) {
+	client := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		io.WriteString(w, `{"data":{"repository":{"release":null}}}`)
+	}))
+
+	if _, err := client.GetReleaseNotes("NethServer/ns8-mail", "9.9.9"); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("GetReleaseNotes() error = %v, want ErrNotFound", err)
+	}
+}
+
+func TestUpdateReleasePatchesSetFields(t *testing.T) {
+	var request string
+	var got map[string]interface{}
+	client := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if r.URL.Path == "/graphql" {
+			io.WriteString(w, `{"data":{"repository":{"release":{"databaseId":42,"tagName":"1.2.0"}}}}`)
+			return
+		}
+		request = r.Method + " " + r.URL.Path
+		json.NewDecoder(r.Body).Decode(&got)
+		io.WriteString(w, `{"id":42}`)
+	}))
+
+	notes := "Updated notes"
+	if err := client.UpdateRelease("NethServer/ns8-mail", "1.2.0", ReleaseUpdate{Notes: &notes}); err != nil {
+		t.Fatalf("UpdateRelease() returned error: %v", err)
+	}
+	if request != "PATCH /repos/NethServer/ns8-mail/releases/42" {
+		t.Fatalf("UpdateRelease() request = %q, want release 42 patched", request)
+	}
+	if want := map[string]interface{}{"body": "Updated notes"}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("UpdateRelease() body = %v, want only %v", got, want)
+	}
+}
+
+func TestDeleteReleaseDeletesByDatabaseID(t *testing.T) {
+	var deleted string
+	client := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/graphql" {
+			w.Header().Set("Content-Type", "application/json")
+			io.WriteString(w, `{"data":{"repository":{"release":{"databaseId":42,"tagName":"1.2.0-testing.1"}}}}`)
+			return
+		}
+		deleted = r.Method + " " + r.URL.Path
+		w.WriteHeader(http.StatusNoContent)
+	}))
+
+	if err := client.DeleteRelease("NethServer/ns8-mail", "1.2.0-testing.1"); err != nil {
+		t.Fatalf("DeleteRelease() returned error: %v", err)
+	}
+	if deleted != "DELETE /repos/NethServer/ns8-mail/releases/42" {
+		t.Fatalf("DeleteRelease() request = %q, want release 42 deleted", deleted)
+	}
+}
+
+func TestCreateIssueCommentReturnsCommentURL(t *testing.T) {
+	client := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]string
+		json.NewDecoder(r.Body).Decode(&body)
+		if r.URL.Path != "/repos/NethServer/dev/issues/10/comments" || body["body"] != "Release" {
+			t.Errorf("unexpected request %s %v", r.URL.Path, body)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusCreated)
+		io.WriteString(w, `{"html_url":"https://github.com/NethServer/dev/issues/10#issuecomment-1"}`)
+	}))
+
+	got, err := client.CreateIssueComment("NethServer/dev", 10, "Release")
+	if err != nil {
+		t.Fatalf("CreateIssueComment() returned error: %v", err)
+	}
+	if got != "https://github.com/NethServer/dev/issues/10#issuecomment-1" {
+		t.Fatalf("CreateIssueComment() = %q, want comment URL", got)
+	}
+}
+
+func TestGetParentIssueNumberSendsSubIssuesFeatureHeader(t *testing.T) {
+	client := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("GraphQL-Features") != "sub_issues" {
+			t.Errorf("GraphQL-Features = %q, want sub_issues", r.Header.Get("GraphQL-Features"))
+		}
+		w.Header().Set("Content-Type", "application/json")
+		io.WriteString(w, `{"data":{"repository":{"issue":{"parent":{"number":100}}}}}`)
+	}))
+
+	got, err := client.GetParentIssueNumber("NethServer/dev", 10)
+	if err != nil {
+		t.Fatalf("GetParentIssueNumber() returned error: %v", err)
+	}
+	if got != 100 {
+		t.Fatalf("GetParentIssueNumber() = %d, want 100", got)
+	}
+}
+
+func TestGetPullRequestReviewDecision(t *testing.T) {
+	tests := []struct {
+		name     string
+		response string
+		want     string
+	}{
+		{name: "approved", response: `{"data":{"repository":{"pullRequest":{"reviewDecision":"APPROVED"}}}}`, want: ReviewApproved},
+		{name: "no reviews required", response: `{"data":{"repository":{"pullRequest":{"reviewDecision":null}}}}`, want: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", "application/json")
+				io.WriteString(w, tt.response)
+			}))
+
+			got, err := client.GetPullRequestReviewDecision("NethServer/ns8-test", 12)
+			if err != nil {
+				t.Fatalf("GetPullRequestReviewDecision() returned error: %v", err)
+			}
+			if got != tt.want {
+				t.Fatalf("GetPullRequestReviewDecision() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
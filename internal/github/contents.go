@@ -0,0 +1,174 @@
+package github
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// File is a file of a repository read through the contents API
+type File struct {
+	Path string
+	// SHA is the blob SHA, needed to update the file
+	SHA     string
+	Content []byte
+}
+
+// GetFile gets a file at ref, a branch, tag or commit SHA, or on the default
+// branch when ref is empty. A missing file returns ErrNotFound.
+func (c *Client) GetFile(repo, path, ref string) (*File, error) {
+	endpoint := fmt.Sprintf("repos/%s/contents/%s", repo, path)
+	if ref != "" {
+		endpoint += "?ref=" + url.QueryEscape(ref)
+	}
+
+	var result struct {
+		Path     string `json:"path"`
+		SHA      string `json:"sha"`
+		Content  string `json:"content"`
+		Encoding string `json:"encoding"`
+	}
+	if err := c.get(endpoint, &result); err != nil {
+		return nil, fmt.Errorf("failed to get %s: %w", path, err)
+	}
+	if result.Encoding != "base64" {
+		return nil, fmt.Errorf("unsupported encoding of %s: %q", path, result.Encoding)
+	}
+	content, err := base64.StdEncoding.DecodeString(strings.ReplaceAll(result.Content, "\n", ""))
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode %s: %w", path, err)
+	}
+	return &File{Path: result.Path, SHA: result.SHA, Content: content}, nil
+}
+
+// FileCommit is a change of a file committed through the contents API
+type FileCommit struct {
+	Path    string
+	Branch  string
+	Message string
+	Content []byte
+	// SHA is the blob SHA of the file being replaced, empty to create it
+	SHA string
+	// Parent is the commit the change is based on, empty for the head of
+	// the branch. With a parent the branch only moves if it is still its
+	// head, and SHA is not needed.
+	Parent string
+}
+
+// CommitFile creates or updates a file on a branch and returns the SHA of
+// the new commit. A stale SHA, i.e. a file changed meanwhile, or a branch
+// that moved past the parent returns ErrConflict.
+func (c *Client) CommitFile(repo string, commit FileCommit) (string, error) {
+	if commit.Parent != "" {
+		return c.commitFileOnParent(repo, commit)
+	}
+
+	body := map[string]interface{}{
+		"message": commit.Message,
+		"content": base64.StdEncoding.EncodeToString(commit.Content),
+		"branch":  commit.Branch,
+	}
+	if commit.SHA != "" {
+		body["sha"] = commit.SHA
+	}
+
+	var result struct {
+		Commit struct {
+			SHA string `json:"sha"`
+		} `json:"commit"`
+	}
+	if err := c.do(http.MethodPut, fmt.Sprintf("repos/%s/contents/%s", repo, commit.Path), body, &result); err != nil {
+		return "", fmt.Errorf("failed to commit %s: %w", commit.Path, err)
+	}
+	return result.Commit.SHA, nil
+}
+
+// commitFileOnParent commits a file on the parent of commit through the Git
+// data API, then moves the branch to the new commit without forcing it, so
+// that commits pushed meanwhile are never part of the new commit
+func (c *Client) commitFileOnParent(repo string, commit FileCommit) (string, error) {
+	var parent struct {
+		Tree struct {
+			SHA string `json:"sha"`
+		} `json:"tree"`
+	}
+	if err := c.get(fmt.Sprintf("repos/%s/git/commits/%s", repo, commit.Parent), &parent); err != nil {
+		return "", fmt.Errorf("failed to get commit %s: %w", commit.Parent, err)
+	}
+
+	var blob struct {
+		SHA string `json:"sha"`
+	}
+	if err := c.do(http.MethodPost, fmt.Sprintf("repos/%s/git/blobs", repo), map[string]interface{}{
+		"content":  base64.StdEncoding.EncodeToString(commit.Content),
+		"encoding": "base64",
+	}, &blob); err != nil {
+		return "", fmt.Errorf("failed to commit %s: %w", commit.Path, err)
+	}
+
+	var tree struct {
+		SHA string `json:"sha"`
+	}
+	if err := c.do(http.MethodPost, fmt.Sprintf("repos/%s/git/trees", repo), map[string]interface{}{
+		"base_tree": parent.Tree.SHA,
+		"tree": []map[string]interface{}{
+			{"path": commit.Path, "mode": "100644", "type": "blob", "sha": blob.SHA},
+		},
+	}, &tree); err != nil {
+		return "", fmt.Errorf("failed to commit %s: %w", commit.Path, err)
+	}
+
+	var created struct {
+		SHA string `json:"sha"`
+	}
+	if err := c.do(http.MethodPost, fmt.Sprintf("repos/%s/git/commits", repo), map[string]interface{}{
+		"message": commit.Message,
+		"tree":    tree.SHA,
+		"parents": []string{commit.Parent},
+	}, &created); err != nil {
+		return "", fmt.Errorf("failed to commit %s: %w", commit.Path, err)
+	}
+
+	// Without force, the update is refused unless it is a fast forward of
+	// the branch, i.e. the branch head is still the parent
+	err := c.do(http.MethodPatch, fmt.Sprintf("repos/%s/git/refs/heads/%s", repo, commit.Branch), map[string]interface{}{
+		"sha":   created.SHA,
+		"force": false,
+	}, nil)
+	if errors.Is(err, ErrValidationFailed) {
+		return "", fmt.Errorf("failed to update branch %s: its head is no longer %s: %w", commit.Branch, commit.Parent, ErrConflict)
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to update branch %s: %w", commit.Branch, err)
+	}
+	return created.SHA, nil
+}
+
+// ListFiles lists the paths of the files of the tree at ref, a branch, tag
+// or commit SHA
+func (c *Client) ListFiles(repo, ref string) ([]string, error) {
+	var result struct {
+		Tree []struct {
+			Path string `json:"path"`
+			Type string `json:"type"`
+		} `json:"tree"`
+		Truncated bool `json:"truncated"`
+	}
+	if err := c.get(fmt.Sprintf("repos/%s/git/trees/%s?recursive=1", repo, url.PathEscape(ref)), &result); err != nil {
+		return nil, fmt.Errorf("failed to list the files of %s: %w", ref, err)
+	}
+	if result.Truncated {
+		return nil, fmt.Errorf("failed to list the files of %s: the tree is too large", ref)
+	}
+
+	var paths []string
+	for _, entry := range result.Tree {
+		if entry.Type == "blob" {
+			paths = append(paths, entry.Path)
+		}
+	}
+	return paths, nil
+}
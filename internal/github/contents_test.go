@@ -0,0 +1,154 @@
+package github
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"reflect"
+	"testing"
+)
+
+func TestGetFileReadsRef(t *testing.T) {
+	client := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/repos/NethServer/ns8-mail/contents/CHANGELOG.md" || r.URL.Query().Get("ref") != "stable-1.4" {
+			t.Errorf("request = %s, want the contents of stable-1.4", r.URL)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		io.WriteString(w, `{"path":"CHANGELOG.md","sha":"blob1","encoding":"base64","content":"IyBDaGFu\nZ2Vsb2cK"}`)
+	}))
+
+	got, err := client.GetFile("NethServer/ns8-mail", "CHANGELOG.md", "stable-1.4")
+	if err != nil {
+		t.Fatalf("GetFile() returned error: %v", err)
+	}
+	want := &File{Path: "CHANGELOG.md", SHA: "blob1", Content: []byte("# Changelog\n")}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("GetFile() = %+v, want %+v", got, want)
+	}
+}
+
+func TestCommitFileUpdatesBranch(t *testing.T) {
+	var got map[string]interface{}
+	client := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPut || r.URL.Path != "/repos/NethServer/ns8-mail/contents/CHANGELOG.md" {
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		}
+		json.NewDecoder(r.Body).Decode(&got)
+		w.Header().Set("Content-Type", "application/json")
+		io.WriteString(w, `{"content":{"sha":"blob2"},"commit":{"sha":"commit2"}}`)
+	}))
+
+	sha, err := client.CommitFile("NethServer/ns8-mail", FileCommit{
+		Path:    "CHANGELOG.md",
+		Branch:  "main",
+		Message: "Update CHANGELOG.md for 1.2.0",
+		Content: []byte("# Changelog\n"),
+		SHA:     "blob1",
+	})
+	if err != nil {
+		t.Fatalf("CommitFile() returned error: %v", err)
+	}
+	if sha != "commit2" {
+		t.Fatalf("CommitFile() = %q, want the new commit SHA", sha)
+	}
+
+	want := map[string]interface{}{
+		"message": "Update CHANGELOG.md for 1.2.0",
+		"content": "IyBDaGFuZ2Vsb2cK",
+		"branch":  "main",
+		"sha":     "blob1",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("CommitFile() body = %v, want %v", got, want)
+	}
+}
+
+func TestCommitFileReturnsConflict(t *testing.T) {
+	client := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusConflict)
+		io.WriteString(w, `{"message":"CHANGELOG.md does not match blob1"}`)
+	}))
+
+	_, err := client.CommitFile("NethServer/ns8-mail", FileCommit{Path: "CHANGELOG.md", Branch: "main", SHA: "blob1"})
+	if !errors.Is(err, ErrConflict) {
+		t.Fatalf("CommitFile() error = %v, want ErrConflict", err)
+	}
+}
+
+func TestCommitFileOnParentUpdatesBranchWithoutForce(t *testing.T) {
+	var requests []string
+	var refUpdate map[string]interface{}
+	client := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests = append(requests, r.Method+" "+r.URL.Path)
+		w.Header().Set("Content-Type", "application/json")
+		switch r.Method + " " + r.URL.Path {
+		case "GET /repos/NethServer/ns8-mail/git/commits/head1":
+			io.WriteString(w, `{"sha":"head1","tree":{"sha":"tree1"}}`)
+		case "POST /repos/NethServer/ns8-mail/git/blobs":
+			io.WriteString(w, `{"sha":"blob2"}`)
+		case "POST /repos/NethServer/ns8-mail/git/trees":
+			io.WriteString(w, `{"sha":"tree2"}`)
+		case "POST /repos/NethServer/ns8-mail/git/commits":
+			io.WriteString(w, `{"sha":"commit2"}`)
+		case "PATCH /repos/NethServer/ns8-mail/git/refs/heads/main":
+			json.NewDecoder(r.Body).Decode(&refUpdate)
+			io.WriteString(w, `{"object":{"sha":"commit2"}}`)
+		default:
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		}
+	}))
+
+	sha, err := client.CommitFile("NethServer/ns8-mail", FileCommit{
+		Path:    "CHANGELOG.md",
+		Branch:  "main",
+		Message: "Update CHANGELOG.md for 1.2.0",
+		Content: []byte("# Changelog\n"),
+		Parent:  "head1",
+	})
+	if err != nil {
+		t.Fatalf("CommitFile() returned error: %v", err)
+	}
+	if sha != "commit2" || len(requests) != 5 {
+		t.Fatalf("CommitFile() = %q with requests %v, want commit2 through the Git data API", sha, requests)
+	}
+	if want := map[string]interface{}{"sha": "commit2", "force": false}; !reflect.DeepEqual(refUpdate, want) {
+		t.Fatalf("branch update = %v, want %v", refUpdate, want)
+	}
+}
+
+func TestCommitFileOnParentReturnsConflictWhenBranchMoved(t *testing.T) {
+	client := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if r.Method == http.MethodPatch {
+			w.WriteHeader(http.StatusUnprocessableEntity)
+			io.WriteString(w, `{"message":"Update is not a fast forward"}`)
+			return
+		}
+		io.WriteString(w, `{"sha":"sha1","tree":{"sha":"tree1"}}`)
+	}))
+
+	_, err := client.CommitFile("NethServer/ns8-mail", FileCommit{Path: "CHANGELOG.md", Branch: "main", Parent: "head1"})
+	if !errors.Is(err, ErrConflict) {
+		t.Fatalf("CommitFile() error = %v, want ErrConflict", err)
+	}
+}
+
+func TestListFilesKeepsBlobs(t *testing.T) {
+	client := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/repos/NethServer/ns8-mail/git/trees/abc123" || r.URL.Query().Get("recursive") != "1" {
+			t.Errorf("request = %s, want the recursive tree of abc123", r.URL)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		io.WriteString(w, `{"tree":[{"path":"ui","type":"tree"},{"path":"ui/package.json","type":"blob"},{"path":"go.mod","type":"blob"}],"truncated":false}`)
+	}))
+
+	got, err := client.ListFiles("NethServer/ns8-mail", "abc123")
+	if err != nil {
+		t.Fatalf("ListFiles() returned error: %v", err)
+	}
+	if want := []string{"ui/package.json", "go.mod"}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("ListFiles() = %v, want %v", got, want)
+	}
+}
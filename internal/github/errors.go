@@ -0,0 +1,167 @@
+package github
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/cli/go-gh/v2/pkg/api"
+)
+
+// Errors classifying failed GitHub API calls. Client methods wrap them so
+// callers can test the failure kind with errors.Is.
+var (
+	ErrNotFound         = errors.New("not found")
+	ErrUnauthorized     = errors.New("unauthorized")
+	ErrForbidden        = errors.New("forbidden")
+	ErrRateLimited      = errors.New("rate limited")
+	ErrConflict         = errors.New("conflict")
+	ErrValidationFailed = errors.New("validation failed")
+)
+
+// APIError is a failed GitHub API call classified by kind. The original
+// go-gh error stays reachable through errors.As.
+type APIError struct {
+	StatusCode int
+	// AcceptedScopes are the OAuth scopes the endpoint accepts, as reported
+	// by the X-Accepted-OAuth-Scopes header
+	AcceptedScopes []string
+	// TokenScopes are the OAuth scopes granted to the token, as reported by
+	// the X-OAuth-Scopes header
+	TokenScopes []string
+	kind        error
+	err         error
+}
+
+// Error implements error
+func (e *APIError) Error() string {
+	return e.err.Error()
+}
+
+// Unwrap exposes both the error kind and the original error
+func (e *APIError) Unwrap() []error {
+	return []error{e.kind, e.err}
+}
+
+// MissingScopes returns the accepted scopes the token does not have. It is
+// empty when GitHub did not report scopes, e.g. for fine-grained tokens.
+func (e *APIError) MissingScopes() []string {
+	if len(e.AcceptedScopes) == 0 {
+		return nil
+	}
+
+	granted := make(map[string]bool, len(e.TokenScopes))
+	for _, scope := range e.TokenScopes {
+		granted[scope] = true
+	}
+	// The repo scope includes public_repo
+	if granted["repo"] {
+		granted["public_repo"] = true
+	}
+
+	var missing []string
+	for _, scope := range e.AcceptedScopes {
+		if granted[scope] {
+			// Any accepted scope is enough
+			return nil
+		}
+		missing = append(missing, scope)
+	}
+	return missing
+}
+
+// apiError classifies a go-gh REST or GraphQL error. Errors that do not map
+// to a known kind are returned unchanged.
+func apiError(err error) error {
+	if err == nil {
+		return nil
+	}
+
+	var httpErr *api.HTTPError
+	if errors.As(err, &httpErr) {
+		kind := httpErrorKind(httpErr)
+		if kind == nil {
+			return err
+		}
+		return &APIError{
+			StatusCode:     httpErr.StatusCode,
+			AcceptedScopes: scopesHeader(httpErr.Headers, "X-Accepted-OAuth-Scopes"),
+			TokenScopes:    scopesHeader(httpErr.Headers, "X-OAuth-Scopes"),
+			kind:           kind,
+			err:            err,
+		}
+	}
+
+	var graphQLErr *api.GraphQLError
+	if errors.As(err, &graphQLErr) {
+		kind := graphQLErrorKind(graphQLErr)
+		if kind == nil {
+			return err
+		}
+		return &APIError{kind: kind, err: err}
+	}
+
+	return err
+}
+
+func httpErrorKind(err *api.HTTPError) error {
+	switch err.StatusCode {
+	case http.StatusNotFound:
+		return ErrNotFound
+	case http.StatusUnauthorized:
+		return ErrUnauthorized
+	case http.StatusForbidden:
+		if isRateLimitError(err) {
+			return ErrRateLimited
+		}
+		return ErrForbidden
+	case http.StatusTooManyRequests:
+		return ErrRateLimited
+	case http.StatusConflict:
+		return ErrConflict
+	case http.StatusUnprocessableEntity:
+		return ErrValidationFailed
+	default:
+		return nil
+	}
+}
+
+func graphQLErrorKind(err *api.GraphQLError) error {
+	for _, item := range err.Errors {
+		switch item.Type {
+		case "NOT_FOUND":
+			return ErrNotFound
+		case "FORBIDDEN", "INSUFFICIENT_SCOPES":
+			return ErrForbidden
+		case "RATE_LIMITED":
+			return ErrRateLimited
+		case "UNPROCESSABLE":
+			return ErrValidationFailed
+		}
+	}
+	return nil
+}
+
+// isRateLimitError tells apart a 403 caused by a rate limit from a
+// permission error, as the transport does before retrying.
+func isRateLimitError(err *api.HTTPError) bool {
+	if err.Headers.Get("Retry-After") != "" || err.Headers.Get("X-RateLimit-Remaining") == "0" {
+		return true
+	}
+	return strings.Contains(strings.ToLower(err.Message), "rate limit")
+}
+
+func scopesHeader(header http.Header, name string) []string {
+	value := header.Get(name)
+	if value == "" {
+		return nil
+	}
+
+	var scopes []string
+	for _, scope := range strings.Split(value, ",") {
+		if scope = strings.TrimSpace(scope); scope != "" {
+			scopes = append(scopes, scope)
+		}
+	}
+	return scopes
+}
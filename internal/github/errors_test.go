@@ -0,0 +1,97 @@
+package github
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"testing"
+
+	"github.com/cli/go-gh/v2/pkg/api"
+)
+
+func TestAPIErrorClassifiesHTTPErrors(t *testing.T) {
+	requestURL, _ := url.Parse("https://api.github.com/repos/NethServer/ns8-mail")
+
+	tests := []struct {
+		status int
+		want   error
+	}{
+		{status: http.StatusNotFound, want: ErrNotFound},
+		{status: http.StatusUnauthorized, want: ErrUnauthorized},
+		{status: http.StatusForbidden, want: ErrForbidden},
+		{status: http.StatusTooManyRequests, want: ErrRateLimited},
+		{status: http.StatusConflict, want: ErrConflict},
+		{status: http.StatusUnprocessableEntity, want: ErrValidationFailed},
+	}
+
+	for _, tt := range tests {
+		t.Run(http.StatusText(tt.status), func(t *testing.T) {
+			httpErr := &api.HTTPError{StatusCode: tt.status, RequestURL: requestURL}
+			err := fmt.Errorf("failed to get repository: %w", apiError(httpErr))
+
+			if !errors.Is(err, tt.want) {
+				t.Fatalf("errors.Is(%v, %v) = false, want true", err, tt.want)
+			}
+
+			var original *api.HTTPError
+			if !errors.As(err, &original) || original != httpErr {
+				t.Fatalf("errors.As() did not expose the original HTTP error")
+			}
+		})
+	}
+}
+
+func TestAPIErrorClassifiesGraphQLErrors(t *testing.T) {
+	err := apiError(&api.GraphQLError{Errors: []api.GraphQLErrorItem{{Type: "NOT_FOUND", Message: "Could not resolve"}}})
+	if !errors.Is(err, ErrNotFound) {
+		t.Fatalf("apiError() = %v, want ErrNotFound", err)
+	}
+}
+
+func TestAPIErrorKeepsUnclassifiedErrors(t *testing.T) {
+	original := &api.HTTPError{StatusCode: http.StatusInternalServerError, RequestURL: &url.URL{}}
+	if got := apiError(original); got != original {
+		t.Fatalf("apiError() = %v, want original error", got)
+	}
+
+	plain := errors.New("boom")
+	if got := apiError(plain); got != plain {
+		t.Fatalf("apiError() = %v, want original error", got)
+	}
+}
+
+func TestAPIErrorDetectsRateLimitedForbidden(t *testing.T) {
+	headers := http.Header{}
+	headers.Set("X-RateLimit-Remaining", "0")
+	err := apiError(&api.HTTPError{StatusCode: http.StatusForbidden, Headers: headers, RequestURL: &url.URL{}})
+	if !errors.Is(err, ErrRateLimited) || errors.Is(err, ErrForbidden) {
+		t.Fatalf("apiError() = %v, want ErrRateLimited only", err)
+	}
+
+	err = apiError(&api.HTTPError{StatusCode: http.StatusForbidden, Message: "You have exceeded a secondary rate limit", RequestURL: &url.URL{}})
+	if !errors.Is(err, ErrRateLimited) {
+		t.Fatalf("apiError() = %v, want ErrRateLimited", err)
+	}
+}
+
+func TestAPIErrorReportsMissingScopes(t *testing.T) {
+	headers := http.Header{}
+	headers.Set("X-Accepted-OAuth-Scopes", "repo, public_repo")
+	headers.Set("X-OAuth-Scopes", "read:org, gist")
+
+	err := apiError(&api.HTTPError{StatusCode: http.StatusForbidden, Headers: headers, RequestURL: &url.URL{}})
+
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) {
+		t.Fatalf("apiError() = %v, want APIError", err)
+	}
+	if got := apiErr.MissingScopes(); len(got) != 2 || got[0] != "repo" || got[1] != "public_repo" {
+		t.Fatalf("MissingScopes() = %v, want [repo public_repo]", got)
+	}
+
+	apiErr.TokenScopes = []string{"repo"}
+	if got := apiErr.MissingScopes(); got != nil {
+		t.Fatalf("MissingScopes() = %v, want none when an accepted scope is granted", got)
+	}
+}
@@ -0,0 +1,25 @@
+package github
+
+import (
+	"strings"
+
+	"github.com/cli/go-gh/v2/pkg/auth"
+)
+
+// EnvHost selects the GitHub host, as for gh itself. The root command sets
+// it from the --hostname flag.
+const EnvHost = "GH_HOST"
+
+// Host returns the GitHub host commands work against: GH_HOST when set,
+// otherwise the default host of the gh configuration, github.com unless gh
+// is logged in to a single GitHub Enterprise Server instance.
+func Host() string {
+	host, _ := auth.DefaultHost()
+	return auth.NormalizeHostname(host)
+}
+
+// WebURL returns the web URL of a path on the GitHub host, such as
+// "NethServer/dev/issues/1"
+func WebURL(path string) string {
+	return "https://" + Host() + "/" + strings.TrimPrefix(path, "/")
+}
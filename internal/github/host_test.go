@@ -0,0 +1,35 @@
+package github
+
+import "testing"
+
+func TestWebURL(t *testing.T) {
+	testCases := []struct {
+		name string
+		host string
+		path string
+		want string
+	}{
+		{
+			name: "github.com",
+			host: "github.com",
+			path: "NethServer/dev/issues/1",
+			want: "https://github.com/NethServer/dev/issues/1",
+		},
+		{
+			name: "enterprise server",
+			host: "Git.Example.com",
+			path: "/NethServer/ns8-mail/pull/2",
+			want: "https://git.example.com/NethServer/ns8-mail/pull/2",
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			t.Setenv(EnvHost, testCase.host)
+
+			if got := WebURL(testCase.path); got != testCase.want {
+				t.Fatalf("WebURL() = %q, want %q", got, testCase.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,120 @@
+package github
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"regexp"
+	"strings"
+)
+
+// perPage is the largest page size accepted by the GitHub REST API.
+const perPage = 100
+
+var linkNextPattern = regexp.MustCompile(`<([^>]+)>;\s*rel="next"`)
+
+// pageInfo is the GraphQL connection cursor information.
+type pageInfo struct {
+	HasNextPage bool   `json:"hasNextPage"`
+	EndCursor   string `json:"endCursor"`
+}
+
+// graphQLPage is implemented by GraphQL responses of a paginated connection.
+type graphQLPage interface {
+	pageInfo() pageInfo
+}
+
+// withPerPage adds the per_page query parameter to a REST path.
+func withPerPage(path string, size int) string {
+	separator := "?"
+	if strings.Contains(path, "?") {
+		separator = "&"
+	}
+	return fmt.Sprintf("%s%sper_page=%d", path, separator, size)
+}
+
+// nextPageURL returns the rel="next" URL of a Link header, or "" on the last page.
+func nextPageURL(link string) string {
+	matches := linkNextPattern.FindStringSubmatch(link)
+	if len(matches) != 2 {
+		return ""
+	}
+	return matches[1]
+}
+
+// getPages fetches a REST path and follows the Link header, calling handle
+// with the body of every page.
+func (c *Client) getPages(path string, handle func(body []byte) error) error {
+	for path != "" {
+		resp, err := c.rest.Request(http.MethodGet, path, nil)
+		if err != nil {
+			return apiError(err)
+		}
+
+		body, err := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		if err != nil {
+			return err
+		}
+
+		if err := handle(body); err != nil {
+			return err
+		}
+
+		path = nextPageURL(resp.Header.Get("Link"))
+	}
+	return nil
+}
+
+// getAll fetches every page of a REST list endpoint.
+func getAll[T any](c *Client, path string) ([]T, error) {
+	var items []T
+	err := c.getPages(withPerPage(path, perPage), func(body []byte) error {
+		var page []T
+		if err := json.Unmarshal(body, &page); err != nil {
+			return err
+		}
+		items = append(items, page...)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+// paginateGraphQL runs a query declaring an $after: String variable until the
+// connection has no next page or handle returns false.
+func paginateGraphQL[P graphQLPage](c *Client, query string, variables map[string]interface{}, handle func(page P) bool) error {
+	vars := make(map[string]interface{}, len(variables)+1)
+	for key, value := range variables {
+		vars[key] = value
+	}
+	vars["after"] = nil
+
+	for {
+		var page P
+		if err := c.query(query, vars, &page); err != nil {
+			return err
+		}
+		if !handle(page) {
+			return nil
+		}
+
+		info := page.pageInfo()
+		if !info.HasNextPage || info.EndCursor == "" {
+			return nil
+		}
+		vars["after"] = info.EndCursor
+	}
+}
+
+// splitRepo splits an owner/name repository string.
+func splitRepo(repo string) (string, string, error) {
+	parts := strings.Split(repo, "/")
+	if len(parts) != 2 {
+		return "", "", fmt.Errorf("invalid repo format: %s", repo)
+	}
+	return parts[0], parts[1], nil
+}
@@ -0,0 +1,310 @@
+package github
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Data types of the Projects v2 fields UpdateProjectItemField can set
+const (
+	ProjectFieldSingleSelect = "SINGLE_SELECT"
+	ProjectFieldText         = "TEXT"
+	ProjectFieldDate         = "DATE"
+	ProjectFieldNumber       = "NUMBER"
+)
+
+// Project is a Projects v2 board
+type Project struct {
+	ID     string
+	Title  string
+	Fields []ProjectField
+}
+
+// ProjectField is a field of a Projects v2 board
+type ProjectField struct {
+	ID       string `json:"id"`
+	Name     string `json:"name"`
+	DataType string `json:"dataType"`
+	// Options are the options of a single-select field
+	Options []struct {
+		ID   string `json:"id"`
+		Name string `json:"name"`
+	} `json:"options"`
+}
+
+// Field returns the field named name, matched case-insensitively
+func (p *Project) Field(name string) (ProjectField, bool) {
+	for _, field := range p.Fields {
+		if strings.EqualFold(field.Name, name) {
+			return field, true
+		}
+	}
+	return ProjectField{}, false
+}
+
+// ProjectItem is the item of an issue in a Projects v2 board
+type ProjectItem struct {
+	ID string
+	// Values are the text of the single-select, text, date and number field
+	// values, by field name
+	Values map[string]string
+}
+
+// GetProject gets the Projects v2 board number of an organization or user
+func (c *Client) GetProject(owner string, number int) (*Project, error) {
+	query := `
+		query($owner: String!, $number: Int!) {
+			repositoryOwner(login: $owner) {
+				... on ProjectV2Owner {
+					projectV2(number: $number) {
+						id
+						title
+						fields(first: 100) {
+							nodes {
+								... on ProjectV2FieldCommon { id name dataType }
+								... on ProjectV2SingleSelectField { options { id name } }
+							}
+						}
+					}
+				}
+			}
+		}
+	`
+
+	var response struct {
+		RepositoryOwner *struct {
+			ProjectV2 *struct {
+				ID     string `json:"id"`
+				Title  string `json:"title"`
+				Fields struct {
+					Nodes []ProjectField `json:"nodes"`
+				} `json:"fields"`
+			} `json:"projectV2"`
+		} `json:"repositoryOwner"`
+	}
+
+	err := c.query(query, map[string]interface{}{
+		"owner":  owner,
+		"number": number,
+	}, &response)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get project %s/%d: %w", owner, number, err)
+	}
+	if response.RepositoryOwner == nil || response.RepositoryOwner.ProjectV2 == nil {
+		return nil, fmt.Errorf("failed to get project %s/%d: %w", owner, number, ErrNotFound)
+	}
+
+	project := response.RepositoryOwner.ProjectV2
+	return &Project{ID: project.ID, Title: project.Title, Fields: project.Fields.Nodes}, nil
+}
+
+// GetIssueProjectItem gets the item of an issue in the project with the
+// given node ID. It returns ErrNotFound when the issue is not in the project.
+func (c *Client) GetIssueProjectItem(repo string, issueNumber int, projectID string) (*ProjectItem, error) {
+	owner, repoName, err := splitRepo(repo)
+	if err != nil {
+		return nil, err
+	}
+
+	query := `
+		query($owner: String!, $repo: String!, $issueNumber: Int!) {
+			repository(owner: $owner, name: $repo) {
+				issue(number: $issueNumber) {
+					projectItems(first: 100) {
+						nodes {
+							id
+							project { id }
+							fieldValues(first: 100) {
+								nodes {
+									... on ProjectV2ItemFieldSingleSelectValue { name field { ... on ProjectV2FieldCommon { name } } }
+									... on ProjectV2ItemFieldTextValue { text field { ... on ProjectV2FieldCommon { name } } }
+									... on ProjectV2ItemFieldDateValue { date field { ... on ProjectV2FieldCommon { name } } }
+									... on ProjectV2ItemFieldNumberValue { number field { ... on ProjectV2FieldCommon { name } } }
+								}
+							}
+						}
+					}
+				}
+			}
+		}
+	`
+
+	var response struct {
+		Repository struct {
+			Issue struct {
+				ProjectItems struct {
+					Nodes []struct {
+						ID      string `json:"id"`
+						Project struct {
+							ID string `json:"id"`
+						} `json:"project"`
+						FieldValues struct {
+							Nodes []struct {
+								Name   string   `json:"name"`
+								Text   string   `json:"text"`
+								Date   string   `json:"date"`
+								Number *float64 `json:"number"`
+								Field  struct {
+									Name string `json:"name"`
+								} `json:"field"`
+							} `json:"nodes"`
+						} `json:"fieldValues"`
+					} `json:"nodes"`
+				} `json:"projectItems"`
+			} `json:"issue"`
+		} `json:"repository"`
+	}
+
+	err = c.query(query, map[string]interface{}{
+		"owner":       owner,
+		"repo":        repoName,
+		"issueNumber": issueNumber,
+	}, &response)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get project item of issue %d: %w", issueNumber, err)
+	}
+
+	for _, node := range response.Repository.Issue.ProjectItems.Nodes {
+		if node.Project.ID != projectID {
+			continue
+		}
+		item := &ProjectItem{ID: node.ID, Values: make(map[string]string)}
+		for _, value := range node.FieldValues.Nodes {
+			switch {
+			case value.Field.Name == "":
+				// A value of a field type without a fragment above
+			case value.Number != nil:
+				item.Values[value.Field.Name] = strconv.FormatFloat(*value.Number, 'f', -1, 64)
+			default:
+				item.Values[value.Field.Name] = value.Name + value.Text + value.Date
+			}
+		}
+		return item, nil
+	}
+
+	return nil, fmt.Errorf("issue %d is not in the project: %w", issueNumber, ErrNotFound)
+}
+
+// UpdateProjectItemField sets a field of a project item. The value is an
+// option name for single-select fields, a YYYY-MM-DD date for date fields.
+func (c *Client) UpdateProjectItemField(projectID, itemID string, field ProjectField, value string) error {
+	fieldValue := map[string]interface{}{}
+	switch field.DataType {
+	case ProjectFieldSingleSelect:
+		for _, option := range field.Options {
+			if strings.EqualFold(option.Name, value) {
+				fieldValue["singleSelectOptionId"] = option.ID
+			}
+		}
+		if len(fieldValue) == 0 {
+			return fmt.Errorf("field %s has no option %q", field.Name, value)
+		}
+	case ProjectFieldText:
+		fieldValue["text"] = value
+	case ProjectFieldDate:
+		fieldValue["date"] = value
+	case ProjectFieldNumber:
+		number, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return fmt.Errorf("field %s needs a number, got %q", field.Name, value)
+		}
+		fieldValue["number"] = number
+	default:
+		return fmt.Errorf("field %s has the unsupported type %s", field.Name, field.DataType)
+	}
+
+	query := `
+		mutation($project: ID!, $item: ID!, $field: ID!, $value: ProjectV2FieldValue!) {
+			updateProjectV2ItemFieldValue(input: {projectId: $project, itemId: $item, fieldId: $field, value: $value}) {
+				projectV2Item { id }
+			}
+		}
+	`
+
+	err := c.query(query, map[string]interface{}{
+		"project": projectID,
+		"item":    itemID,
+		"field":   field.ID,
+		"value":   fieldValue,
+	}, nil)
+	if err != nil {
+		return fmt.Errorf("failed to update field %s: %w", field.Name, err)
+	}
+	return nil
+}
+
+// GetIssueProjectFieldValue gets the value of a single-select field of an
+// issue in the Projects v2 board number of owner. It is empty when the issue
+// is not in the project or the field is not set.
+func (c *Client) GetIssueProjectFieldValue(repo string, issueNumber int, owner string, number int, field string) (string, error) {
+	repoOwner, repoName, err := splitRepo(repo)
+	if err != nil {
+		return "", err
+	}
+
+	query := `
+		query($owner: String!, $repo: String!, $issueNumber: Int!, $field: String!) {
+			repository(owner: $owner, name: $repo) {
+				issue(number: $issueNumber) {
+					projectItems(first: 100) {
+						nodes {
+							project {
+								number
+								owner {
+									... on Organization { login }
+									... on User { login }
+								}
+							}
+							fieldValueByName(name: $field) {
+								... on ProjectV2ItemFieldSingleSelectValue { name }
+							}
+						}
+					}
+				}
+			}
+		}
+	`
+
+	var response struct {
+		Repository struct {
+			Issue struct {
+				ProjectItems struct {
+					Nodes []struct {
+						Project struct {
+							Number int `json:"number"`
+							Owner  struct {
+								Login string `json:"login"`
+							} `json:"owner"`
+						} `json:"project"`
+						FieldValueByName *struct {
+							Name string `json:"name"`
+						} `json:"fieldValueByName"`
+					} `json:"nodes"`
+				} `json:"projectItems"`
+			} `json:"issue"`
+		} `json:"repository"`
+	}
+
+	err = c.query(query, map[string]interface{}{
+		"owner":       repoOwner,
+		"repo":        repoName,
+		"issueNumber": issueNumber,
+		"field":       field,
+	}, &response)
+	if err != nil {
+		return "", fmt.Errorf("failed to query issue project field: %w", err)
+	}
+
+	for _, item := range response.Repository.Issue.ProjectItems.Nodes {
+		if item.Project.Number != number || !strings.EqualFold(item.Project.Owner.Login, owner) {
+			continue
+		}
+		if item.FieldValueByName == nil {
+			return "", nil
+		}
+		return item.FieldValueByName.Name, nil
+	}
+
+	return "", nil // Not in the project
+}
@@ -0,0 +1,158 @@
+package github
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"testing"
+)
+
+func TestGetIssueProjectFieldValueSelectsTheProject(t *testing.T) {
+	client := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		io.WriteString(w, `{"data":{"repository":{"issue":{"projectItems":{"nodes":[
+			{"project":{"number":12,"owner":{"login":"someone"}},"fieldValueByName":{"name":"Done"}},
+			{"project":{"number":12,"owner":{"login":"NethServer"}},"fieldValueByName":{"name":"Testing"}}
+		]}}}}}`)
+	}))
+
+	got, err := client.GetIssueProjectFieldValue("NethServer/dev", 10, "nethserver", 12, "Status")
+	if err != nil {
+		t.Fatalf("GetIssueProjectFieldValue() returned error: %v", err)
+	}
+	if got != "Testing" {
+		t.Fatalf("GetIssueProjectFieldValue() = %q, want Testing", got)
+	}
+
+	got, err = client.GetIssueProjectFieldValue("NethServer/dev", 10, "NethServer", 7, "Status")
+	if err != nil {
+		t.Fatalf("GetIssueProjectFieldValue() returned error: %v", err)
+	}
+	if got != "" {
+		t.Fatalf("GetIssueProjectFieldValue() = %q, want empty for an issue outside the project", got)
+	}
+}
+
+func TestGetIssueProjectItemReadsFieldValues(t *testing.T) {
+	client := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		io.WriteString(w, `{"data":{"repository":{"issue":{"projectItems":{"nodes":[
+			{"id":"ITEM_other","project":{"id":"PROJECT_other"},"fieldValues":{"nodes":[]}},
+			{"id":"ITEM_1","project":{"id":"PROJECT_1"},"fieldValues":{"nodes":[
+				{"name":"Testing","field":{"name":"Status"}},
+				{"text":"1.2.0","field":{"name":"Released in"}},
+				{"date":"2026-10-18","field":{"name":"Release date"}},
+				{"number":3,"field":{"name":"Points"}},
+				{}
+			]}}
+		]}}}}}`)
+	}))
+
+	item, err := client.GetIssueProjectItem("NethServer/dev", 10, "PROJECT_1")
+	if err != nil {
+		t.Fatalf("GetIssueProjectItem() returned error: %v", err)
+	}
+	want := map[string]string{"Status": "Testing", "Released in": "1.2.0", "Release date": "2026-10-18", "Points": "3"}
+	if item.ID != "ITEM_1" || len(item.Values) != len(want) {
+		t.Fatalf("GetIssueProjectItem() = %+v, want ITEM_1 with %v", item, want)
+	}
+	for name, value := range want {
+		if item.Values[name] != value {
+			t.Errorf("Values[%s] = %q, want %q", name, item.Values[name], value)
+		}
+	}
+
+	_, err = client.GetIssueProjectItem("NethServer/dev", 10, "PROJECT_2")
+	if !errors.Is(err, ErrNotFound) {
+		t.Fatalf("GetIssueProjectItem() error = %v, want ErrNotFound", err)
+	}
+}
+
+func TestUpdateProjectItemFieldSendsTypedValue(t *testing.T) {
+	status := ProjectField{ID: "FIELD_status", Name: "Status", DataType: ProjectFieldSingleSelect}
+	status.Options = append(status.Options, struct {
+		ID   string `json:"id"`
+		Name string `json:"name"`
+	}{ID: "OPTION_testing", Name: "Testing"})
+
+	tests := []struct {
+		name  string
+		field ProjectField
+		value string
+		want  string
+	}{
+		{name: "single select", field: status, value: "testing", want: `{"singleSelectOptionId":"OPTION_testing"}`},
+		{name: "text", field: ProjectField{ID: "FIELD_text", Name: "Released in", DataType: ProjectFieldText}, value: "1.2.0", want: `{"text":"1.2.0"}`},
+		{name: "date", field: ProjectField{ID: "FIELD_date", Name: "Release date", DataType: ProjectFieldDate}, value: "2026-10-18", want: `{"date":"2026-10-18"}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				var request struct {
+					Variables struct {
+						Field string          `json:"field"`
+						Value json.RawMessage `json:"value"`
+					} `json:"variables"`
+				}
+				if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+					t.Errorf("failed to decode the request: %v", err)
+				}
+				if request.Variables.Field != tt.field.ID || string(request.Variables.Value) != tt.want {
+					t.Errorf("variables = %s %s, want %s %s", request.Variables.Field, request.Variables.Value, tt.field.ID, tt.want)
+				}
+				w.Header().Set("Content-Type", "application/json")
+				io.WriteString(w, `{"data":{"updateProjectV2ItemFieldValue":{"projectV2Item":{"id":"ITEM_1"}}}}`)
+			}))
+
+			if err := client.UpdateProjectItemField("PROJECT_1", "ITEM_1", tt.field, tt.value); err != nil {
+				t.Fatalf("UpdateProjectItemField() returned error: %v", err)
+			}
+		})
+	}
+}
+
+func TestUpdateProjectItemFieldRejectsUnknownOption(t *testing.T) {
+	client := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Error("unexpected request for an unknown option")
+	}))
+
+	field := ProjectField{ID: "FIELD_status", Name: "Status", DataType: ProjectFieldSingleSelect}
+	if err := client.UpdateProjectItemField("PROJECT_1", "ITEM_1", field, "Released"); err == nil {
+		t.Fatal("UpdateProjectItemField() returned nil error, want an unknown option error")
+	}
+}
+
+func TestGetProjectReadsFields(t *testing.T) {
+	client := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		io.WriteString(w, `{"data":{"repositoryOwner":{"projectV2":{"id":"PROJECT_1","title":"NethServer","fields":{"nodes":[
+			{"id":"FIELD_status","name":"Status","dataType":"SINGLE_SELECT","options":[{"id":"OPTION_testing","name":"Testing"}]},
+			{"id":"FIELD_text","name":"Released in","dataType":"TEXT"}
+		]}}}}}`)
+	}))
+
+	project, err := client.GetProject("NethServer", 12)
+	if err != nil {
+		t.Fatalf("GetProject() returned error: %v", err)
+	}
+	field, ok := project.Field("released in")
+	if project.ID != "PROJECT_1" || !ok || field.ID != "FIELD_text" {
+		t.Fatalf("GetProject() = %+v, want PROJECT_1 with the Released in field", project)
+	}
+	if status, _ := project.Field("Status"); len(status.Options) != 1 || status.Options[0].ID != "OPTION_testing" {
+		t.Fatalf("Status options = %+v, want the Testing option", status.Options)
+	}
+}
+
+func TestGetProjectReturnsNotFound(t *testing.T) {
+	client := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		io.WriteString(w, `{"data":{"repositoryOwner":{"projectV2":null}}}`)
+	}))
+
+	if _, err := client.GetProject("NethServer", 99); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("GetProject() error = %v, want ErrNotFound", err)
+	}
+}
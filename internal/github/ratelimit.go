@@ -0,0 +1,320 @@
+package github
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Retry policy for idempotent requests
+const (
+	maxRetries            = 3
+	baseRetryDelay        = time.Second
+	maxRetryDelay         = time.Minute
+	secondaryLimitDelay   = time.Minute
+	rateLimitResetPadding = time.Second
+)
+
+// rateLimitState is the last quota reported by GitHub for one API resource.
+type rateLimitState struct {
+	limit     int
+	remaining int
+	reset     time.Time
+}
+
+// rateLimitTransport is an http.RoundTripper that keeps track of the GitHub
+// rate limit headers, pauses when the primary limit is exhausted and retries
+// idempotent requests hitting secondary rate limits or transient failures.
+type rateLimitTransport struct {
+	next  http.RoundTripper
+	warn  io.Writer
+	debug io.Writer
+	now   func() time.Time
+	sleep func(ctx context.Context, d time.Duration) error
+
+	mu     sync.Mutex
+	limits map[string]rateLimitState
+}
+
+func newRateLimitTransport(next http.RoundTripper, warn, debug io.Writer) *rateLimitTransport {
+	if next == nil {
+		next = http.DefaultTransport
+	}
+	return &rateLimitTransport{
+		next:   next,
+		warn:   warn,
+		debug:  debug,
+		now:    time.Now,
+		sleep:  sleepContext,
+		limits: make(map[string]rateLimitState),
+	}
+}
+
+func sleepContext(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// RoundTrip implements http.RoundTripper
+func (t *rateLimitTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	retryable := isIdempotent(req)
+
+	for attempt := 0; ; attempt++ {
+		if err := t.waitForQuota(req); err != nil {
+			return nil, err
+		}
+
+		attemptReq, err := rewindRequest(req, attempt)
+		if err != nil {
+			return nil, err
+		}
+
+		resp, err := t.next.RoundTrip(attemptReq)
+		if err != nil {
+			if !retryable || attempt >= maxRetries || req.Context().Err() != nil {
+				return nil, err
+			}
+			delay := backoffDelay(baseRetryDelay, attempt)
+			t.logf(t.debug, "%s %s failed (%v), retrying in %s\n", req.Method, req.URL, err, delay)
+			if err := t.sleep(req.Context(), delay); err != nil {
+				return nil, err
+			}
+			continue
+		}
+
+		t.recordQuota(req, resp)
+
+		delay, retry := t.retryDelay(resp, attempt)
+		if !retry || !retryable || attempt >= maxRetries {
+			return resp, nil
+		}
+
+		drainBody(resp)
+		t.logf(t.warn, "GitHub API %s for %s %s, retrying in %s\n", resp.Status, req.Method, req.URL.Path, delay.Round(time.Second))
+		if err := t.sleep(req.Context(), delay); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// waitForQuota pauses until the reset time when the last response for the
+// request resource reported an exhausted primary rate limit.
+func (t *rateLimitTransport) waitForQuota(req *http.Request) error {
+	resource := requestResource(req)
+
+	t.mu.Lock()
+	state, ok := t.limits[resource]
+	t.mu.Unlock()
+
+	if !ok || state.remaining > 0 {
+		return nil
+	}
+
+	wait := state.reset.Sub(t.now())
+	if wait <= 0 {
+		return nil
+	}
+	wait += rateLimitResetPadding
+
+	t.logf(t.warn, "GitHub API %s rate limit exhausted, waiting %s until reset\n", resource, wait.Round(time.Second))
+	if err := t.sleep(req.Context(), wait); err != nil {
+		return err
+	}
+
+	t.mu.Lock()
+	delete(t.limits, resource)
+	t.mu.Unlock()
+	return nil
+}
+
+// recordQuota stores the rate limit headers of a response and reports the
+// remaining quota in debug mode.
+func (t *rateLimitTransport) recordQuota(req *http.Request, resp *http.Response) {
+	remaining, err := strconv.Atoi(resp.Header.Get("X-RateLimit-Remaining"))
+	if err != nil {
+		return
+	}
+	limit, _ := strconv.Atoi(resp.Header.Get("X-RateLimit-Limit"))
+
+	var reset time.Time
+	if epoch, err := strconv.ParseInt(resp.Header.Get("X-RateLimit-Reset"), 10, 64); err == nil {
+		reset = time.Unix(epoch, 0)
+	}
+
+	resource := resp.Header.Get("X-RateLimit-Resource")
+	if resource == "" {
+		resource = requestResource(req)
+	}
+
+	t.mu.Lock()
+	t.limits[resource] = rateLimitState{limit: limit, remaining: remaining, reset: reset}
+	t.mu.Unlock()
+
+	t.logf(t.debug, "GitHub API %s quota: %d/%d remaining, resets at %s\n",
+		resource, remaining, limit, reset.Format(time.TimeOnly))
+}
+
+// retryDelay reports whether a response should be retried and how long to
+// wait before the next attempt.
+func (t *rateLimitTransport) retryDelay(resp *http.Response, attempt int) (time.Duration, bool) {
+	switch {
+	case resp.StatusCode == http.StatusTooManyRequests ||
+		(resp.StatusCode == http.StatusForbidden && isRateLimited(resp)):
+		if delay, ok := retryAfter(resp.Header, t.now()); ok {
+			return delay, true
+		}
+		if resp.Header.Get("X-RateLimit-Remaining") == "0" {
+			if epoch, err := strconv.ParseInt(resp.Header.Get("X-RateLimit-Reset"), 10, 64); err == nil {
+				if wait := time.Unix(epoch, 0).Sub(t.now()); wait > 0 {
+					return wait + rateLimitResetPadding, true
+				}
+			}
+		}
+		return backoffDelay(secondaryLimitDelay, attempt), true
+	case resp.StatusCode == http.StatusBadGateway ||
+		resp.StatusCode == http.StatusServiceUnavailable ||
+		resp.StatusCode == http.StatusGatewayTimeout ||
+		resp.StatusCode == http.StatusInternalServerError:
+		if delay, ok := retryAfter(resp.Header, t.now()); ok {
+			return delay, true
+		}
+		return backoffDelay(baseRetryDelay, attempt), true
+	default:
+		return 0, false
+	}
+}
+
+func (t *rateLimitTransport) logf(w io.Writer, format string, args ...interface{}) {
+	if w == nil {
+		return
+	}
+	fmt.Fprintf(w, format, args...)
+}
+
+// isRateLimited tells apart a 403 caused by a rate limit from a permission
+// error. GitHub signals the former through headers or the error message.
+func isRateLimited(resp *http.Response) bool {
+	if resp.Header.Get("Retry-After") != "" || resp.Header.Get("X-RateLimit-Remaining") == "0" {
+		return true
+	}
+	if resp.Body == nil {
+		return false
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+	if err != nil {
+		return false
+	}
+	return bytes.Contains(bytes.ToLower(body), []byte("rate limit"))
+}
+
+// retryAfter parses the Retry-After header, either in seconds or as a date.
+func retryAfter(header http.Header, now time.Time) (time.Duration, bool) {
+	value := strings.TrimSpace(header.Get("Retry-After"))
+	if value == "" {
+		return 0, false
+	}
+	if seconds, err := strconv.Atoi(value); err == nil && seconds >= 0 {
+		return time.Duration(seconds) * time.Second, true
+	}
+	if date, err := http.ParseTime(value); err == nil {
+		if wait := date.Sub(now); wait > 0 {
+			return wait, true
+		}
+		return 0, true
+	}
+	return 0, false
+}
+
+// backoffDelay doubles base at every attempt, up to maxRetryDelay
+func backoffDelay(base time.Duration, attempt int) time.Duration {
+	delay := base << attempt
+	if delay > maxRetryDelay {
+		return maxRetryDelay
+	}
+	return delay
+}
+
+// isIdempotent reports whether a request can be safely sent again: only
+// reads are. Writes such as PUT and DELETE are not retried, since a failed
+// attempt may have been applied anyway. GraphQL queries are POST requests
+// but only mutations change state.
+func isIdempotent(req *http.Request) bool {
+	switch req.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+		return true
+	case http.MethodPost:
+		if !strings.HasSuffix(req.URL.Path, "/graphql") || req.GetBody == nil {
+			return false
+		}
+		body, err := req.GetBody()
+		if err != nil {
+			return false
+		}
+		defer body.Close()
+		var payload struct {
+			Query string `json:"query"`
+		}
+		if err := json.NewDecoder(body).Decode(&payload); err != nil {
+			return false
+		}
+		return !strings.HasPrefix(strings.TrimSpace(payload.Query), "mutation")
+	default:
+		return false
+	}
+}
+
+// rewindRequest returns the request to send for the given attempt, with a
+// fresh copy of the body for retries.
+func rewindRequest(req *http.Request, attempt int) (*http.Request, error) {
+	if attempt == 0 || req.Body == nil || req.Body == http.NoBody {
+		return req, nil
+	}
+	if req.GetBody == nil {
+		return nil, fmt.Errorf("cannot retry %s %s: request body is not rewindable", req.Method, req.URL)
+	}
+	body, err := req.GetBody()
+	if err != nil {
+		return nil, err
+	}
+	clone := req.Clone(req.Context())
+	clone.Body = body
+	return clone, nil
+}
+
+// requestResource guesses the rate limit resource a request is counted
+// against, used until GitHub reports it in X-RateLimit-Resource.
+func requestResource(req *http.Request) string {
+	path := req.URL.Path
+	switch {
+	case strings.HasSuffix(path, "/graphql"):
+		return "graphql"
+	case strings.Contains(path, "/search/"):
+		return "search"
+	default:
+		return "core"
+	}
+}
+
+func drainBody(resp *http.Response) {
+	if resp.Body == nil {
+		return
+	}
+	io.Copy(io.Discard, resp.Body)
+	resp.Body.Close()
+}
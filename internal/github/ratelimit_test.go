@@ -0,0 +1,243 @@
+package github
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+type fakeSleeper struct {
+	waits []time.Duration
+}
+
+func (f *fakeSleeper) sleep(_ context.Context, d time.Duration) error {
+	f.waits = append(f.waits, d)
+	return nil
+}
+
+func newTestRateLimitTransport(debug io.Writer) (*rateLimitTransport, *fakeSleeper) {
+	sleeper := &fakeSleeper{}
+	transport := newRateLimitTransport(http.DefaultTransport, io.Discard, debug)
+	transport.sleep = sleeper.sleep
+	transport.now = func() time.Time { return time.Unix(1700000000, 0) }
+	return transport, sleeper
+}
+
+func TestRateLimitTransportRetriesSecondaryRateLimit(t *testing.T) {
+	calls := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		if calls == 1 {
+			w.Header().Set("Retry-After", "7")
+			w.WriteHeader(http.StatusForbidden)
+			io.WriteString(w, `{"message":"You have exceeded a secondary rate limit"}`)
+			return
+		}
+		io.WriteString(w, `{}`)
+	}))
+	defer server.Close()
+
+	transport, sleeper := newTestRateLimitTransport(nil)
+	client := &http.Client{Transport: transport}
+
+	resp, err := client.Get(server.URL + "/repos/NethServer/ns8-mail/pulls/1")
+	if err != nil {
+		t.Fatalf("Get() returned error: %v", err)
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("StatusCode = %d, want %d", resp.StatusCode, http.StatusOK)
+	}
+	if calls != 2 {
+		t.Fatalf("calls = %d, want 2", calls)
+	}
+	if len(sleeper.waits) != 1 || sleeper.waits[0] != 7*time.Second {
+		t.Fatalf("waits = %v, want [7s]", sleeper.waits)
+	}
+}
+
+func TestRateLimitTransportRetriesServerErrorsWithBackoff(t *testing.T) {
+	calls := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer server.Close()
+
+	transport, sleeper := newTestRateLimitTransport(nil)
+	client := &http.Client{Transport: transport}
+
+	resp, err := client.Get(server.URL + "/repos/NethServer/ns8-mail")
+	if err != nil {
+		t.Fatalf("Get() returned error: %v", err)
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode != http.StatusBadGateway {
+		t.Fatalf("StatusCode = %d, want %d", resp.StatusCode, http.StatusBadGateway)
+	}
+	if calls != maxRetries+1 {
+		t.Fatalf("calls = %d, want %d", calls, maxRetries+1)
+	}
+	want := []time.Duration{time.Second, 2 * time.Second, 4 * time.Second}
+	if len(sleeper.waits) != len(want) {
+		t.Fatalf("waits = %v, want %v", sleeper.waits, want)
+	}
+	for i := range want {
+		if sleeper.waits[i] != want[i] {
+			t.Fatalf("waits = %v, want %v", sleeper.waits, want)
+		}
+	}
+}
+
+func TestRateLimitTransportDoesNotRetryPermissionErrors(t *testing.T) {
+	calls := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.WriteHeader(http.StatusForbidden)
+		io.WriteString(w, `{"message":"Resource not accessible by integration"}`)
+	}))
+	defer server.Close()
+
+	transport, sleeper := newTestRateLimitTransport(nil)
+	client := &http.Client{Transport: transport}
+
+	resp, err := client.Get(server.URL + "/repos/NethServer/ns8-mail")
+	if err != nil {
+		t.Fatalf("Get() returned error: %v", err)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+
+	if calls != 1 || len(sleeper.waits) != 0 {
+		t.Fatalf("calls = %d, waits = %v, want a single attempt", calls, sleeper.waits)
+	}
+	if !strings.Contains(string(body), "Resource not accessible") {
+		t.Fatalf("body = %q, want original error body preserved", body)
+	}
+}
+
+func TestRateLimitTransportRetriesOnlyIdempotentRequests(t *testing.T) {
+	calls := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.WriteHeader(http.StatusTooManyRequests)
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		name      string
+		method    string
+		path      string
+		body      string
+		wantCalls int
+	}{
+		{name: "rest post", method: http.MethodPost, path: "/repos/NethServer/dev/issues/1/comments", body: `{"body":"hi"}`, wantCalls: 1},
+		{name: "contents put", method: http.MethodPut, path: "/repos/NethServer/ns8-mail/contents/CHANGELOG.md", body: `{"message":"Release 1.2.0"}`, wantCalls: 1},
+		{name: "release delete", method: http.MethodDelete, path: "/repos/NethServer/ns8-mail/releases/1", wantCalls: 1},
+		{name: "graphql mutation", method: http.MethodPost, path: "/graphql", body: `{"query":"mutation { x }"}`, wantCalls: 1},
+		{name: "graphql query", method: http.MethodPost, path: "/graphql", body: `{"query":"\n query { x }"}`, wantCalls: maxRetries + 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			calls = 0
+			transport, _ := newTestRateLimitTransport(nil)
+			client := &http.Client{Transport: transport}
+
+			req, _ := http.NewRequest(tt.method, server.URL+tt.path, strings.NewReader(tt.body))
+			resp, err := client.Do(req)
+			if err != nil {
+				t.Fatalf("Do() returned error: %v", err)
+			}
+			resp.Body.Close()
+
+			if calls != tt.wantCalls {
+				t.Fatalf("calls = %d, want %d", calls, tt.wantCalls)
+			}
+		})
+	}
+}
+
+func TestRateLimitTransportCapsSecondaryRateLimitDelay(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusTooManyRequests)
+	}))
+	defer server.Close()
+
+	transport, sleeper := newTestRateLimitTransport(nil)
+	client := &http.Client{Transport: transport}
+
+	resp, err := client.Get(server.URL + "/repos/NethServer/ns8-mail")
+	if err != nil {
+		t.Fatalf("Get() returned error: %v", err)
+	}
+	resp.Body.Close()
+
+	if len(sleeper.waits) != maxRetries {
+		t.Fatalf("waits = %v, want %d retries", sleeper.waits, maxRetries)
+	}
+	for _, wait := range sleeper.waits {
+		if wait > maxRetryDelay {
+			t.Fatalf("waits = %v, want none above %s", sleeper.waits, maxRetryDelay)
+		}
+	}
+}
+
+func TestRateLimitTransportPausesWhenPrimaryLimitIsExhausted(t *testing.T) {
+	reset := time.Unix(1700000000, 0).Add(90 * time.Second)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-RateLimit-Limit", "5000")
+		w.Header().Set("X-RateLimit-Remaining", "0")
+		w.Header().Set("X-RateLimit-Reset", strconv.FormatInt(reset.Unix(), 10))
+		w.Header().Set("X-RateLimit-Resource", "core")
+		io.WriteString(w, `{}`)
+	}))
+	defer server.Close()
+
+	var debug bytes.Buffer
+	transport, sleeper := newTestRateLimitTransport(&debug)
+	client := &http.Client{Transport: transport}
+
+	for i := 0; i < 2; i++ {
+		resp, err := client.Get(server.URL + "/repos/NethServer/ns8-mail")
+		if err != nil {
+			t.Fatalf("Get() returned error: %v", err)
+		}
+		resp.Body.Close()
+	}
+
+	if len(sleeper.waits) != 1 || sleeper.waits[0] != 91*time.Second {
+		t.Fatalf("waits = %v, want a single pause until reset", sleeper.waits)
+	}
+	if !strings.Contains(debug.String(), "GitHub API core quota: 0/5000 remaining") {
+		t.Fatalf("debug output = %q, want remaining quota report", debug.String())
+	}
+}
+
+func TestRetryAfterParsesSecondsAndDates(t *testing.T) {
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	header := http.Header{}
+	header.Set("Retry-After", "30")
+	if got, ok := retryAfter(header, now); !ok || got != 30*time.Second {
+		t.Fatalf("retryAfter(seconds) = %v, %v, want 30s, true", got, ok)
+	}
+
+	header.Set("Retry-After", now.Add(time.Minute).Format(http.TimeFormat))
+	if got, ok := retryAfter(header, now); !ok || got != time.Minute {
+		t.Fatalf("retryAfter(date) = %v, %v, want 1m, true", got, ok)
+	}
+
+	header.Del("Retry-After")
+	if _, ok := retryAfter(header, now); ok {
+		t.Fatal("retryAfter(missing) ok = true, want false")
+	}
+}
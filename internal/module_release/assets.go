@@ -0,0 +1,113 @@
+package module_release
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// ChecksumsAsset is the asset listing the SHA-256 checksums of the other
+// assets of a release, in the sha256sum format
+const ChecksumsAsset = "SHA256SUMS"
+
+// Asset is a file to attach to a release
+type Asset struct {
+	Name    string
+	Content []byte
+}
+
+// ReadAssets reads the files matching the glob patterns. A pattern matching
+// no file, a directory or two files with the same name are errors, since
+// assets are named after their file.
+func ReadAssets(patterns []string) ([]Asset, error) {
+	var assets []Asset
+	names := map[string]string{}
+	for _, pattern := range patterns {
+		paths, err := filepath.Glob(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid asset pattern %q: %w", pattern, err)
+		}
+		if len(paths) == 0 {
+			return nil, fmt.Errorf("no file matches the asset pattern %q", pattern)
+		}
+
+		for _, path := range paths {
+			name := filepath.Base(path)
+			if previous, exists := names[name]; exists {
+				if previous == path {
+					continue
+				}
+				return nil, fmt.Errorf("assets %s and %s have the same name", previous, path)
+			}
+			if name == ChecksumsAsset {
+				return nil, fmt.Errorf("asset %s is reserved for the checksums", path)
+			}
+
+			info, err := os.Stat(path)
+			if err != nil {
+				return nil, err
+			}
+			if info.IsDir() {
+				return nil, fmt.Errorf("asset %s is a directory", path)
+			}
+			content, err := os.ReadFile(path)
+			if err != nil {
+				return nil, err
+			}
+
+			names[name] = path
+			assets = append(assets, Asset{Name: name, Content: content})
+		}
+	}
+	return assets, nil
+}
+
+// SHA256Sum returns the hex encoded SHA-256 checksum of content
+func SHA256Sum(content []byte) string {
+	sum := sha256.Sum256(content)
+	return hex.EncodeToString(sum[:])
+}
+
+// ChecksumsFile returns the SHA256SUMS asset of assets, sorted by name so
+// that it can be checked with sha256sum -c
+func ChecksumsFile(assets []Asset) Asset {
+	sorted := append([]Asset(nil), assets...)
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].Name < sorted[j].Name
+	})
+
+	var content strings.Builder
+	for _, asset := range sorted {
+		fmt.Fprintf(&content, "%s  %s\n", SHA256Sum(asset.Content), asset.Name)
+	}
+	return Asset{Name: ChecksumsAsset, Content: []byte(content.String())}
+}
+
+// ParseChecksums parses a SHA256SUMS file into the checksums by file name.
+// Binary mode entries ("checksum *name") are accepted too.
+func ParseChecksums(content []byte) (map[string]string, error) {
+	checksums := map[string]string{}
+	scanner := bufio.NewScanner(strings.NewReader(string(content)))
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" {
+			continue
+		}
+
+		checksum, name, found := strings.Cut(text, " ")
+		name = strings.TrimPrefix(strings.TrimLeft(name, " "), "*")
+		if !found || name == "" || len(checksum) != sha256.Size*2 {
+			return nil, fmt.Errorf("invalid %s line %d: %q", ChecksumsAsset, line, text)
+		}
+		if _, err := hex.DecodeString(checksum); err != nil {
+			return nil, fmt.Errorf("invalid %s line %d: %q", ChecksumsAsset, line, text)
+		}
+		checksums[name] = strings.ToLower(checksum)
+	}
+	return checksums, scanner.Err()
+}
@@ -0,0 +1,95 @@
+package module_release
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestReadAssetsExpandsPatterns(t *testing.T) {
+	dir := t.TempDir()
+	for name, content := range map[string]string{"ui.tar.gz": "ui", "sbom.json": "{}", "notes.txt": "x"} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+			t.Fatalf("WriteFile() returned error: %v", err)
+		}
+	}
+
+	assets, err := ReadAssets([]string{filepath.Join(dir, "*.json"), filepath.Join(dir, "ui.*"), filepath.Join(dir, "*.json")})
+	if err != nil {
+		t.Fatalf("ReadAssets() returned error: %v", err)
+	}
+	want := []Asset{{Name: "sbom.json", Content: []byte("{}")}, {Name: "ui.tar.gz", Content: []byte("ui")}}
+	if !reflect.DeepEqual(assets, want) {
+		t.Fatalf("ReadAssets() = %+v, want %+v", assets, want)
+	}
+}
+
+func TestReadAssetsRejectsInvalidPatterns(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(dir, "a", "sub"), 0o755); err != nil {
+		t.Fatalf("MkdirAll() returned error: %v", err)
+	}
+	for _, path := range []string{filepath.Join(dir, "a", "ui.tar.gz"), filepath.Join(dir, "ui.tar.gz"), filepath.Join(dir, ChecksumsAsset)} {
+		if err := os.WriteFile(path, []byte("x"), 0o644); err != nil {
+			t.Fatalf("WriteFile() returned error: %v", err)
+		}
+	}
+
+	testCases := []struct {
+		name     string
+		patterns []string
+		wantErr  string
+	}{
+		{name: "no match", patterns: []string{filepath.Join(dir, "*.zip")}, wantErr: "no file matches"},
+		{name: "same name", patterns: []string{filepath.Join(dir, "ui.tar.gz"), filepath.Join(dir, "a", "ui.tar.gz")}, wantErr: "have the same name"},
+		{name: "checksums", patterns: []string{filepath.Join(dir, ChecksumsAsset)}, wantErr: "reserved for the checksums"},
+		{name: "directory", patterns: []string{filepath.Join(dir, "a", "sub")}, wantErr: "is a directory"},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			_, err := ReadAssets(testCase.patterns)
+			if err == nil || !strings.Contains(err.Error(), testCase.wantErr) {
+				t.Fatalf("ReadAssets() error = %v, want %q", err, testCase.wantErr)
+			}
+		})
+	}
+}
+
+func TestChecksumsFileRoundTrip(t *testing.T) {
+	file := ChecksumsFile([]Asset{{Name: "b.json", Content: []byte("b")}, {Name: "a.tar.gz", Content: []byte("a")}})
+
+	want := "ca978112ca1bbdcafac231b39a23dc4da786eff8147c4e72b9807785afee48bb  a.tar.gz\n" +
+		"3e23e8160039594a33894f6564e1b1348bbd7a0088d42c4acb73eeaed59c009d  b.json\n"
+	if file.Name != ChecksumsAsset || string(file.Content) != want {
+		t.Fatalf("ChecksumsFile() = %s %q, want %q", file.Name, file.Content, want)
+	}
+
+	checksums, err := ParseChecksums(file.Content)
+	if err != nil {
+		t.Fatalf("ParseChecksums() returned error: %v", err)
+	}
+	if checksums["a.tar.gz"] != SHA256Sum([]byte("a")) || checksums["b.json"] != SHA256Sum([]byte("b")) {
+		t.Fatalf("ParseChecksums() = %v, want the checksums of a.tar.gz and b.json", checksums)
+	}
+}
+
+func TestParseChecksums(t *testing.T) {
+	sum := SHA256Sum([]byte("a"))
+
+	checksums, err := ParseChecksums([]byte(strings.ToUpper(sum) + " *a.bin\n\n"))
+	if err != nil {
+		t.Fatalf("ParseChecksums() returned error: %v", err)
+	}
+	if !reflect.DeepEqual(checksums, map[string]string{"a.bin": sum}) {
+		t.Fatalf("ParseChecksums() = %v, want a.bin", checksums)
+	}
+
+	for _, content := range []string{"abc  a.bin", sum, strings.Repeat("z", 64) + "  a.bin"} {
+		if _, err := ParseChecksums([]byte(content)); err == nil {
+			t.Fatalf("ParseChecksums(%q) returned no error", content)
+		}
+	}
+}
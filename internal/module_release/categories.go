@@ -0,0 +1,167 @@
+package module_release
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/NethServer/gh-ns8/internal/config"
+	"github.com/NethServer/gh-ns8/internal/github"
+)
+
+// PRCategoryInfo describes how a PR category is displayed
+type PRCategoryInfo struct {
+	Name  PRCategory
+	Title string
+	Emoji string
+	// Warning lists the open PRs of the category before the summary
+	Warning string
+}
+
+// builtinPRCategories collect the PRs no rule matches: open PRs are
+// generic, the others merged
+var builtinPRCategories = []PRCategoryInfo{
+	{Name: PRCategoryGeneric, Title: "Generic"},
+	{Name: PRCategoryMerged, Title: "Merged", Emoji: EmojiMerged},
+}
+
+// pullRequestFacts are the PR attributes matched by the category rules
+type pullRequestFacts struct {
+	author string
+	title  string
+	branch string
+	state  string
+	labels []string
+}
+
+func newPullRequestFacts(pr *github.PullRequest) pullRequestFacts {
+	facts := pullRequestFacts{
+		author: pr.User.Login,
+		title:  pr.Title,
+		branch: pr.Head.Ref,
+		state:  config.StateClosed,
+	}
+	switch {
+	case pr.Merged:
+		facts.state = config.StateMerged
+	case strings.EqualFold(pr.State, "open"):
+		facts.state = config.StateOpen
+	}
+	for _, label := range pr.Labels {
+		facts.labels = append(facts.labels, label.Name)
+	}
+	return facts
+}
+
+func newOpenPullRequestFacts(pr github.OpenPullRequest) pullRequestFacts {
+	facts := pullRequestFacts{
+		author: pr.Author.Login,
+		title:  pr.Title,
+		branch: pr.HeadRefName,
+		state:  config.StateOpen,
+	}
+	for _, label := range pr.Labels.Nodes {
+		facts.labels = append(facts.labels, label.Name)
+	}
+	return facts
+}
+
+type prRule struct {
+	category     PRCategory
+	authors      []string
+	labels       []string
+	title        *regexp.Regexp
+	branchPrefix string
+	state        string
+}
+
+func (r prRule) matches(facts pullRequestFacts) bool {
+	if len(r.authors) > 0 && !slices.Contains(r.authors, facts.author) {
+		return false
+	}
+	if len(r.labels) > 0 && !slices.ContainsFunc(facts.labels, func(label string) bool {
+		return slices.Contains(r.labels, label)
+	}) {
+		return false
+	}
+	if r.title != nil && !r.title.MatchString(facts.title) {
+		return false
+	}
+	if r.branchPrefix != "" && !strings.HasPrefix(facts.branch, r.branchPrefix) {
+		return false
+	}
+	return r.state == "" || r.state == facts.state
+}
+
+// PRClassifier assigns PRs to the configured categories
+type PRClassifier struct {
+	categories []PRCategoryInfo
+	rules      []prRule
+}
+
+// NewPRClassifier builds the classifier of the configured categories. The
+// rules are tried in order, so the first category with a matching rule wins.
+func NewPRClassifier(categories []config.Category) (*PRClassifier, error) {
+	classifier := &PRClassifier{}
+	for _, category := range categories {
+		title := category.Title
+		if title == "" {
+			title = category.Name
+		}
+		classifier.categories = append(classifier.categories, PRCategoryInfo{
+			Name:    PRCategory(category.Name),
+			Title:   title,
+			Emoji:   category.Emoji,
+			Warning: category.Warning,
+		})
+
+		for _, rule := range category.Rules {
+			compiled := prRule{
+				category:     PRCategory(category.Name),
+				authors:      rule.Authors,
+				labels:       rule.Labels,
+				branchPrefix: rule.BranchPrefix,
+				state:        rule.State,
+			}
+			if rule.Title != "" {
+				title, err := regexp.Compile(rule.Title)
+				if err != nil {
+					return nil, fmt.Errorf("invalid title rule of category %s: %w", category.Name, err)
+				}
+				compiled.title = title
+			}
+			classifier.rules = append(classifier.rules, compiled)
+		}
+	}
+	classifier.categories = append(classifier.categories, builtinPRCategories...)
+	return classifier, nil
+}
+
+// Categories returns the categories in display order, the built-in generic
+// and merged categories last
+func (c *PRClassifier) Categories() []PRCategoryInfo {
+	return c.categories
+}
+
+// Categorize returns the category of a PR
+func (c *PRClassifier) Categorize(pr *github.PullRequest) PRCategory {
+	return c.categorize(newPullRequestFacts(pr))
+}
+
+// CategorizeOpen returns the category of an open PR
+func (c *PRClassifier) CategorizeOpen(pr github.OpenPullRequest) PRCategory {
+	return c.categorize(newOpenPullRequestFacts(pr))
+}
+
+func (c *PRClassifier) categorize(facts pullRequestFacts) PRCategory {
+	for _, rule := range c.rules {
+		if rule.matches(facts) {
+			return rule.category
+		}
+	}
+	if facts.state == config.StateOpen {
+		return PRCategoryGeneric
+	}
+	return PRCategoryMerged
+}
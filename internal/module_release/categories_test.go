@@ -0,0 +1,122 @@
+package module_release
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/NethServer/gh-ns8/internal/config"
+	ghgithub "github.com/NethServer/gh-ns8/internal/github"
+)
+
+func makeRulePullRequest(author, title, branch, state string, merged bool, labels ...string) *ghgithub.PullRequest {
+	pr := makeDisplayPullRequest(1, state, merged, nil, "", false, labels...)
+	pr.User.Login = author
+	pr.Title = title
+	pr.Head.Ref = branch
+	return pr
+}
+
+func TestPRClassifierMatchesRules(t *testing.T) {
+	classifier, err := NewPRClassifier([]config.Category{
+		{
+			Name:  "dependencies",
+			Emoji: "📦",
+			Rules: []config.Rule{
+				{Authors: []string{"dependabot[bot]", "renovate-app[bot]"}, State: config.StateMerged},
+				{Labels: []string{"dependencies"}, State: config.StateMerged},
+				{BranchPrefix: "renovate/", State: config.StateMerged},
+			},
+		},
+		{
+			Name:  "sync",
+			Emoji: "🔄",
+			Rules: []config.Rule{{Authors: []string{"github-actions[bot]"}, Title: `(?i)^sync\b`}},
+		},
+	})
+	if err != nil {
+		t.Fatalf("NewPRClassifier() returned error: %v", err)
+	}
+
+	tests := []struct {
+		name string
+		pr   *ghgithub.PullRequest
+		want PRCategory
+	}{
+		{name: "author", pr: makeRulePullRequest("dependabot[bot]", "Bump x", "", "closed", true), want: "dependencies"},
+		{name: "label", pr: makeRulePullRequest("alice", "Bump x", "", "closed", true, "dependencies"), want: "dependencies"},
+		{name: "branch prefix", pr: makeRulePullRequest("alice", "Bump x", "renovate/x-1.x", "closed", true), want: "dependencies"},
+		{name: "state", pr: makeRulePullRequest("dependabot[bot]", "Bump x", "", "open", false), want: PRCategoryGeneric},
+		{name: "all conditions", pr: makeRulePullRequest("github-actions[bot]", "Sync translations", "", "closed", true), want: "sync"},
+		{name: "title mismatch", pr: makeRulePullRequest("github-actions[bot]", "Release notes", "", "closed", true), want: PRCategoryMerged},
+		{name: "closed unmerged", pr: makeRulePullRequest("alice", "Fix", "", "closed", false), want: PRCategoryMerged},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := classifier.Categorize(tt.pr); got != tt.want {
+				t.Fatalf("Categorize() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPRClassifierCategorizesOpenPullRequests(t *testing.T) {
+	classifier, err := NewPRClassifier(config.Default().Categories)
+	if err != nil {
+		t.Fatalf("NewPRClassifier() returned error: %v", err)
+	}
+
+	pr := ghgithub.OpenPullRequest{Number: 1}
+	pr.Author.Login = "weblate"
+	if got := classifier.CategorizeOpen(pr); got != PRCategoryTranslation {
+		t.Fatalf("CategorizeOpen(weblate) = %q, want %q", got, PRCategoryTranslation)
+	}
+
+	pr.Author.Login = "renovate[bot]"
+	if got := classifier.CategorizeOpen(pr); got != PRCategoryGeneric {
+		t.Fatalf("CategorizeOpen(renovate) = %q, want %q", got, PRCategoryGeneric)
+	}
+}
+
+func TestPRClassifierCategoriesEndWithBuiltins(t *testing.T) {
+	classifier, err := NewPRClassifier([]config.Category{
+		{Name: "docs", Emoji: "📝", Rules: []config.Rule{{Labels: []string{"documentation"}}}},
+	})
+	if err != nil {
+		t.Fatalf("NewPRClassifier() returned error: %v", err)
+	}
+
+	var names []PRCategory
+	for _, category := range classifier.Categories() {
+		names = append(names, category.Name)
+	}
+	if want := []PRCategory{"docs", PRCategoryGeneric, PRCategoryMerged}; !reflect.DeepEqual(names, want) {
+		t.Fatalf("Categories() = %v, want %v", names, want)
+	}
+	if title := classifier.Categories()[0].Title; title != "docs" {
+		t.Fatalf("Title = %q, want the name when no title is set", title)
+	}
+}
+
+func TestDisplayShowsConfiguredCategoriesInLegend(t *testing.T) {
+	classifier, err := NewPRClassifier([]config.Category{
+		{Name: "dependencies", Title: "Dependencies", Emoji: "📦", Rules: []config.Rule{{Labels: []string{"dependencies"}}}},
+	})
+	if err != nil {
+		t.Fatalf("NewPRClassifier() returned error: %v", err)
+	}
+
+	summary := NewCheckSummary("NethServer/dev")
+	summary.Categories = classifier.Categories()
+	pr := makeDisplayPullRequest(12, "closed", true, nil, "", false, "dependencies")
+	summary.AddPullRequest("NethServer/ns8-test", pr, classifier.Categorize(pr))
+
+	output := captureStdout(t, summary.Display)
+	if !strings.Contains(output, "PR type:         📦 Dependencies    🔀 Merged\n") {
+		t.Fatalf("missing configured legend in output:\n%s", output)
+	}
+	if !strings.Contains(output, "🟪   📦 ") {
+		t.Fatalf("missing category emoji on the PR in output:\n%s", output)
+	}
+}
@@ -0,0 +1,260 @@
+package module_release
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/NethServer/gh-ns8/internal/github"
+)
+
+// ChangelogFile is the changelog of a module repository
+const ChangelogFile = "CHANGELOG.md"
+
+// changelogHeader starts a new changelog
+const changelogHeader = `# Changelog
+
+All notable changes to this project will be documented in this file.
+
+The format is based on [Keep a Changelog](https://keepachangelog.com/en/1.1.0/),
+and this project adheres to [Semantic Versioning](https://semver.org/spec/v2.0.0.html).`
+
+// Keep a Changelog types of changes
+const (
+	ChangeAdded    = "Added"
+	ChangeChanged  = "Changed"
+	ChangeFixed    = "Fixed"
+	ChangeSecurity = "Security"
+)
+
+// changeTypes are the types of changes in section order
+var changeTypes = []string{ChangeAdded, ChangeChanged, ChangeFixed, ChangeSecurity}
+
+var (
+	changelogVersionRegex = regexp.MustCompile(`^## \[?([^\]\s]+)\]?`)
+	changelogLinkRegex    = regexp.MustCompile(`^\[([^\]]+)\]: \S+`)
+	stableVersionRegex    = regexp.MustCompile(`^(\d+)\.(\d+)\.(\d+)$`)
+)
+
+// ChangelogRelease is the section of a release in the changelog
+type ChangelogRelease struct {
+	Version string
+	// Date is the release date, YYYY-MM-DD
+	Date string
+	// Previous is the previous release, compared in the version link; the
+	// first release links its tag
+	Previous string
+	// Changes are the entries of each type of change
+	Changes map[string][]string
+}
+
+// NewChangelogRelease sorts the notes of a release by type of change. Issues
+// with PRs and the PRs not linked to issues are the entries, classified by
+// their labels (security, bug, enhancement or feature) and else by
+// conventional title prefixes (fix, feat); the other changes are Changed.
+func NewChangelogRelease(version, previous, date string, notes *ReleaseNotes) ChangelogRelease {
+	release := ChangelogRelease{Version: version, Date: date, Previous: previous, Changes: map[string][]string{}}
+	if notes == nil {
+		return release
+	}
+
+	var addIssues func(issues []NotesIssue)
+	addIssues = func(issues []NotesIssue) {
+		for _, issue := range issues {
+			if len(issue.PullRequests) > 0 {
+				kind := changeType(issue.Labels, issue.Title)
+				release.Changes[kind] = append(release.Changes[kind], fmt.Sprintf("%s ([%s](%s))", issue.Title, issue.Ref, issue.URL))
+			}
+			addIssues(issue.Children)
+		}
+	}
+	addPullRequests := func(prs []NotesPullRequest) {
+		for _, pr := range prs {
+			kind := changeType(pr.Labels, pr.Title)
+			release.Changes[kind] = append(release.Changes[kind], fmt.Sprintf("%s ([#%d](%s))", pr.Title, pr.Number, pr.URL))
+		}
+	}
+
+	addIssues(notes.Issues)
+	for _, category := range notes.Categories {
+		addPullRequests(category.PullRequests)
+	}
+	addPullRequests(notes.Other)
+	return release
+}
+
+func changeType(labels []string, title string) string {
+	kind := ""
+	for _, label := range labels {
+		switch strings.ToLower(label) {
+		case "security":
+			return ChangeSecurity
+		case "bug":
+			kind = ChangeFixed
+		case "enhancement", "feature":
+			if kind == "" {
+				kind = ChangeAdded
+			}
+		}
+	}
+	if kind != "" {
+		return kind
+	}
+
+	title = strings.ToLower(title)
+	switch {
+	case strings.HasPrefix(title, "fix"):
+		return ChangeFixed
+	case strings.HasPrefix(title, "feat"):
+		return ChangeAdded
+	}
+	return ChangeChanged
+}
+
+// section renders the release section
+func (r ChangelogRelease) section() string {
+	var section strings.Builder
+	fmt.Fprintf(&section, "## [%s] - %s", r.Version, r.Date)
+	for _, kind := range changeTypes {
+		if len(r.Changes[kind]) == 0 {
+			continue
+		}
+		fmt.Fprintf(&section, "\n\n### %s\n", kind)
+		for _, entry := range r.Changes[kind] {
+			fmt.Fprintf(&section, "\n- %s", entry)
+		}
+	}
+	return section.String()
+}
+
+// link renders the version link reference of the release
+func (r ChangelogRelease) link(repo string) string {
+	if r.Previous == "" {
+		return fmt.Sprintf("[%s]: %s", r.Version, github.WebURL(fmt.Sprintf("%s/releases/tag/%s", repo, r.Version)))
+	}
+	return fmt.Sprintf("[%s]: %s", r.Version, github.WebURL(fmt.Sprintf("%s/compare/%s...%s", repo, r.Previous, r.Version)))
+}
+
+// changelogBlock is a version section or a link reference of a changelog
+type changelogBlock struct {
+	version string
+	text    string
+}
+
+// Changelog is a Keep a Changelog file. Unknown content is kept as is, new
+// releases are inserted in version order.
+type Changelog struct {
+	header   string
+	sections []changelogBlock
+	links    []changelogBlock
+}
+
+// ParseChangelog parses a changelog, a new one when text is empty
+func ParseChangelog(text string) *Changelog {
+	changelog := &Changelog{}
+	if strings.TrimSpace(text) == "" {
+		changelog.header = changelogHeader
+		return changelog
+	}
+
+	lines := strings.Split(strings.TrimRight(text, "\n"), "\n")
+
+	// Link references close the file
+	end := len(lines)
+	for i := len(lines) - 1; i >= 0; i-- {
+		line := strings.TrimSpace(lines[i])
+		if line == "" {
+			continue
+		}
+		matches := changelogLinkRegex.FindStringSubmatch(line)
+		if matches == nil {
+			break
+		}
+		changelog.links = append([]changelogBlock{{version: matches[1], text: line}}, changelog.links...)
+		end = i
+	}
+
+	var header []string
+	var current *changelogBlock
+	for _, line := range lines[:end] {
+		if matches := changelogVersionRegex.FindStringSubmatch(line); matches != nil {
+			changelog.sections = append(changelog.sections, changelogBlock{version: matches[1], text: line})
+			current = &changelog.sections[len(changelog.sections)-1]
+			continue
+		}
+		if current == nil {
+			header = append(header, line)
+			continue
+		}
+		current.text += "\n" + line
+	}
+	changelog.header = strings.TrimSpace(strings.Join(header, "\n"))
+	for i := range changelog.sections {
+		changelog.sections[i].text = strings.TrimSpace(changelog.sections[i].text)
+	}
+
+	return changelog
+}
+
+// Has reports whether the changelog has a section for version
+func (c *Changelog) Has(version string) bool {
+	for _, section := range c.sections {
+		if section.version == version {
+			return true
+		}
+	}
+	return false
+}
+
+// Add inserts the section and the link reference of a release
+func (c *Changelog) Add(repo string, release ChangelogRelease) {
+	c.sections = insertChangelogBlock(c.sections, changelogBlock{version: release.Version, text: release.section()})
+	c.links = insertChangelogBlock(c.links, changelogBlock{version: release.Version, text: release.link(repo)})
+}
+
+// insertChangelogBlock inserts block before the first older version, so
+// that entries such as Unreleased stay first
+func insertChangelogBlock(blocks []changelogBlock, block changelogBlock) []changelogBlock {
+	for i, existing := range blocks {
+		if older, ok := olderVersion(existing.version, block.version); ok && older {
+			blocks = append(blocks[:i], append([]changelogBlock{block}, blocks[i:]...)...)
+			return blocks
+		}
+	}
+	return append(blocks, block)
+}
+
+// olderVersion reports whether the stable version a precedes b, and whether
+// both are stable versions
+func olderVersion(a, b string) (bool, bool) {
+	aMatches := stableVersionRegex.FindStringSubmatch(a)
+	bMatches := stableVersionRegex.FindStringSubmatch(b)
+	if aMatches == nil || bMatches == nil {
+		return false, false
+	}
+	for i := 1; i <= 3; i++ {
+		aPart, _ := strconv.Atoi(aMatches[i])
+		bPart, _ := strconv.Atoi(bMatches[i])
+		if aPart != bPart {
+			return aPart < bPart, true
+		}
+	}
+	return false, true
+}
+
+// String renders the changelog
+func (c *Changelog) String() string {
+	blocks := []string{c.header}
+	for _, section := range c.sections {
+		blocks = append(blocks, section.text)
+	}
+	if len(c.links) > 0 {
+		links := make([]string, 0, len(c.links))
+		for _, link := range c.links {
+			links = append(links, link.text)
+		}
+		blocks = append(blocks, strings.Join(links, "\n"))
+	}
+	return strings.TrimLeft(strings.Join(blocks, "\n\n"), "\n") + "\n"
+}
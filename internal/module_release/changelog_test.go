@@ -0,0 +1,161 @@
+package module_release
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestNewChangelogReleaseClassifiesChanges(t *testing.T) {
+	notes := &ReleaseNotes{
+		Issues: []NotesIssue{
+			{
+				Ref: "NethServer/dev#100", Title: "Phone support", URL: "https://github.com/NethServer/dev/issues/100", Labels: []string{"enhancement"},
+				Children: []NotesIssue{{
+					Ref: "NethServer/dev#11", Title: "Phone model", URL: "https://github.com/NethServer/dev/issues/11", Labels: []string{"Feature"},
+					PullRequests: []NotesPullRequest{{Number: 2}},
+				}},
+			},
+			{
+				Ref: "NethServer/dev#10", Title: "Login loop", URL: "https://github.com/NethServer/dev/issues/10", Labels: []string{"enhancement", "bug"},
+				PullRequests: []NotesPullRequest{{Number: 1}},
+			},
+			{
+				Ref: "NethServer/dev#12", Title: "Weak cipher", URL: "https://github.com/NethServer/dev/issues/12", Labels: []string{"bug", "security"},
+				PullRequests: []NotesPullRequest{{Number: 6}},
+			},
+		},
+		Categories: []NotesCategory{{Name: "renovate", PullRequests: []NotesPullRequest{
+			{Number: 3, Title: "Update dependency foo", URL: "https://github.com/NethServer/ns8-test/pull/3"},
+		}}},
+		Other: []NotesPullRequest{
+			{Number: 4, Title: "fix(ui): wrong label", URL: "https://github.com/NethServer/ns8-test/pull/4"},
+			{Number: 5, Title: "feat: add backup", URL: "https://github.com/NethServer/ns8-test/pull/5"},
+		},
+	}
+
+	got := NewChangelogRelease("1.2.0", "1.1.0", "2026-10-18", notes)
+	want := map[string][]string{
+		ChangeAdded: {
+			"Phone model ([NethServer/dev#11](https://github.com/NethServer/dev/issues/11))",
+			"feat: add backup ([#5](https://github.com/NethServer/ns8-test/pull/5))",
+		},
+		ChangeChanged: {
+			"Update dependency foo ([#3](https://github.com/NethServer/ns8-test/pull/3))",
+		},
+		ChangeFixed: {
+			"Login loop ([NethServer/dev#10](https://github.com/NethServer/dev/issues/10))",
+			"fix(ui): wrong label ([#4](https://github.com/NethServer/ns8-test/pull/4))",
+		},
+		ChangeSecurity: {
+			"Weak cipher ([NethServer/dev#12](https://github.com/NethServer/dev/issues/12))",
+		},
+	}
+	if !reflect.DeepEqual(got.Changes, want) {
+		t.Fatalf("NewChangelogRelease().Changes = %v, want %v", got.Changes, want)
+	}
+}
+
+func TestChangelogAddCreatesFile(t *testing.T) {
+	changelog := ParseChangelog("")
+	changelog.Add("NethServer/ns8-test", ChangelogRelease{Version: "1.0.0", Date: "2026-09-01"})
+	changelog.Add("NethServer/ns8-test", ChangelogRelease{
+		Version:  "1.1.0",
+		Date:     "2026-10-18",
+		Previous: "1.0.0",
+		Changes: map[string][]string{
+			ChangeFixed: {"Login loop ([NethServer/dev#10](https://github.com/NethServer/dev/issues/10))"},
+			ChangeAdded: {"Backup ([#5](https://github.com/NethServer/ns8-test/pull/5))"},
+		},
+	})
+
+	want := `# Changelog
+
+All notable changes to this project will be documented in this file.
+
+The format is based on [Keep a Changelog](https://keepachangelog.com/en/1.1.0/),
+and this project adheres to [Semantic Versioning](https://semver.org/spec/v2.0.0.html).
+
+## [1.1.0] - 2026-10-18
+
+### Added
+
+- Backup ([#5](https://github.com/NethServer/ns8-test/pull/5))
+
+### Fixed
+
+- Login loop ([NethServer/dev#10](https://github.com/NethServer/dev/issues/10))
+
+## [1.0.0] - 2026-09-01
+
+[1.1.0]: https://github.com/NethServer/ns8-test/compare/1.0.0...1.1.0
+[1.0.0]: https://github.com/NethServer/ns8-test/releases/tag/1.0.0
+`
+	if got := changelog.String(); got != want {
+		t.Fatalf("String() =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestChangelogAddKeepsExistingContent(t *testing.T) {
+	text := `# Changelog
+
+Hand-written introduction.
+
+## [Unreleased]
+
+- Work in progress
+
+## [1.1.0] - 2026-09-01
+
+### Fixed
+
+- Edited by hand
+
+## [1.0.0] - 2026-08-01
+
+[Unreleased]: https://github.com/NethServer/ns8-test/compare/1.1.0...HEAD
+[1.1.0]: https://github.com/NethServer/ns8-test/compare/1.0.0...1.1.0
+[1.0.0]: https://github.com/NethServer/ns8-test/releases/tag/1.0.0
+`
+	changelog := ParseChangelog(text)
+	if !changelog.Has("1.1.0") || !changelog.Has("Unreleased") || changelog.Has("1.0.1") {
+		t.Fatal("Has() does not match the parsed sections")
+	}
+
+	changelog.Add("NethServer/ns8-test", ChangelogRelease{
+		Version:  "1.0.1",
+		Date:     "2026-08-15",
+		Previous: "1.0.0",
+		Changes:  map[string][]string{ChangeChanged: {"Update foo"}},
+	})
+
+	want := `# Changelog
+
+Hand-written introduction.
+
+## [Unreleased]
+
+- Work in progress
+
+## [1.1.0] - 2026-09-01
+
+### Fixed
+
+- Edited by hand
+
+## [1.0.1] - 2026-08-15
+
+### Changed
+
+- Update foo
+
+## [1.0.0] - 2026-08-01
+
+[Unreleased]: https://github.com/NethServer/ns8-test/compare/1.1.0...HEAD
+[1.1.0]: https://github.com/NethServer/ns8-test/compare/1.0.0...1.1.0
+[1.0.1]: https://github.com/NethServer/ns8-test/compare/1.0.0...1.0.1
+[1.0.0]: https://github.com/NethServer/ns8-test/releases/tag/1.0.0
+`
+	if got := changelog.String(); got != want {
+		t.Fatalf("String() =\n%s\nwant\n%s", got, want)
+	}
+}
@@ -2,10 +2,12 @@ package module_release
 
 import (
 	"fmt"
+	"slices"
 	"sort"
 	"strconv"
 	"strings"
 
+	"github.com/NethServer/gh-ns8/internal/config"
 	"github.com/NethServer/gh-ns8/internal/github"
 )
 
@@ -89,20 +91,23 @@ type IssueInfo struct {
 	Status       string // Open/Closed emoji
 	Progress     string // Progress emoji
 	Labels       string // Filtered labels (without testing/verified)
+	State        string // config.StateOpen or config.StateClosed
 	RefCount     int    // Number of PRs referencing this issue
 	ParentNumber int    // Parent issue number (0 if none)
 	Children     []int  // Child issue numbers
 	LinkedPRs    []PRInfo
+	labelNames   []string // All labels, matched by the readiness policy
 }
 
-// PRCategory identifies the display bucket for a PR.
-type PRCategory int
+// PRCategory identifies the display bucket for a PR: a configured category
+// name or one of the built-in generic and merged categories.
+type PRCategory string
 
 const (
-	PRCategoryRenovate PRCategory = iota
-	PRCategoryTranslation
-	PRCategoryGeneric
-	PRCategoryMerged
+	PRCategoryRenovate    PRCategory = "renovate"
+	PRCategoryTranslation PRCategory = "translation"
+	PRCategoryGeneric     PRCategory = config.CategoryGeneric
+	PRCategoryMerged      PRCategory = config.CategoryMerged
 )
 
 // PRInfo holds display information about a pull request.
@@ -115,19 +120,30 @@ type PRInfo struct {
 	Progress     string
 	Mergeability string
 	Labels       string
+	// ReviewDecision is the GitHub review decision, when it was fetched
+	ReviewDecision string
 }
 
 // CheckSummary holds all information for the check command display
 type CheckSummary struct {
-	RenovatePRs    []PRInfo
-	TranslationPRs []PRInfo
-	GenericPRs     []PRInfo
-	MergedPRs      []PRInfo
-	OpenWeblatePRs []string
-	OrphanCommits  []string
-	Issues         map[int]*IssueInfo
-	IssuesRepo     string
-	issueOrder     []int
+	// PullRequests are the PRs not linked to issues, by category
+	PullRequests map[PRCategory][]PRInfo
+	// Categories are the PR categories in display order
+	Categories []PRCategoryInfo
+	// OpenWarnings are the URLs of the open PRs of categories with a warning
+	OpenWarnings  map[PRCategory][]string
+	OrphanCommits []string
+	Issues        map[int]*IssueInfo
+	IssuesRepo    string
+	// TestingLabel and VerifiedLabel are the labels tracking the QA progress
+	// of issues and PRs
+	TestingLabel  string
+	VerifiedLabel string
+	// ProgressSource, when set, gives the issue progress instead of the labels
+	ProgressSource ProgressSource
+	// Policy decides which issues are ready to release
+	Policy     *ReadinessPolicy
+	issueOrder []int
 }
 
 type issueProvider interface {
@@ -138,36 +154,58 @@ type issueProvider interface {
 // NewCheckSummary creates a new CheckSummary
 func NewCheckSummary(issuesRepo string) *CheckSummary {
 	return &CheckSummary{
-		Issues:     make(map[int]*IssueInfo),
-		IssuesRepo: issuesRepo,
+		PullRequests:  make(map[PRCategory][]PRInfo),
+		Categories:    defaultPRCategories(),
+		OpenWarnings:  make(map[PRCategory][]string),
+		Issues:        make(map[int]*IssueInfo),
+		IssuesRepo:    issuesRepo,
+		TestingLabel:  "testing",
+		VerifiedLabel: "verified",
+		Policy:        NewReadinessPolicy(config.Default().Readiness),
 	}
 }
 
+func defaultPRCategories() []PRCategoryInfo {
+	classifier, err := NewPRClassifier(config.Default().Categories)
+	if err != nil {
+		panic(err)
+	}
+	return classifier.Categories()
+}
+
 // AddPullRequest adds a pull request to the requested display category.
 func (cs *CheckSummary) AddPullRequest(repo string, pr *github.PullRequest, category PRCategory) {
-	info := newPRInfo(repo, pr, category)
-	switch category {
-	case PRCategoryRenovate:
-		cs.RenovatePRs = append(cs.RenovatePRs, info)
-	case PRCategoryTranslation:
-		cs.TranslationPRs = append(cs.TranslationPRs, info)
-	case PRCategoryGeneric:
-		cs.GenericPRs = append(cs.GenericPRs, info)
-	default:
-		cs.MergedPRs = append(cs.MergedPRs, info)
+	cs.PullRequests[category] = append(cs.PullRequests[category], cs.newPRInfo(repo, pr, category))
+}
+
+// AddOpenWarning lists an open PR under the warning of its category, if the
+// category has one
+func (cs *CheckSummary) AddOpenWarning(category PRCategory, url string) {
+	if info, ok := cs.category(category); ok && info.Warning != "" {
+		cs.OpenWarnings[category] = append(cs.OpenWarnings[category], url)
+	}
+}
+
+func (cs *CheckSummary) category(name PRCategory) (PRCategoryInfo, bool) {
+	for _, info := range cs.Categories {
+		if info.Name == name {
+			return info, true
+		}
 	}
+	return PRCategoryInfo{}, false
 }
 
-func newPRInfo(repo string, pr *github.PullRequest, category PRCategory) PRInfo {
+func (cs *CheckSummary) newPRInfo(repo string, pr *github.PullRequest, category PRCategory) PRInfo {
 	return PRInfo{
-		Number:       pr.Number,
-		Category:     category,
-		URL:          pullRequestURL(repo, pr),
-		Title:        pr.Title,
-		Status:       pullRequestStatus(pr),
-		Progress:     pullRequestProgress(category),
-		Mergeability: pullRequestMergeability(pr),
-		Labels:       pullRequestLabels(pr),
+		Number:         pr.Number,
+		Category:       category,
+		URL:            pullRequestURL(repo, pr),
+		Title:          pr.Title,
+		Status:         pullRequestStatus(pr),
+		Progress:       cs.pullRequestProgress(category),
+		Mergeability:   pullRequestMergeability(pr),
+		Labels:         cs.pullRequestLabels(pr),
+		ReviewDecision: pr.ReviewDecision,
 	}
 }
 
@@ -175,7 +213,7 @@ func pullRequestURL(repo string, pr *github.PullRequest) string {
 	if pr.HTMLURL != "" {
 		return pr.HTMLURL
 	}
-	return fmt.Sprintf("https://github.com/%s/pull/%d", repo, pr.Number)
+	return github.WebURL(fmt.Sprintf("%s/pull/%d", repo, pr.Number))
 }
 
 func pullRequestStatus(pr *github.PullRequest) string {
@@ -188,17 +226,9 @@ func pullRequestStatus(pr *github.PullRequest) string {
 	return EmojiClosedPR
 }
 
-func pullRequestProgress(category PRCategory) string {
-	switch category {
-	case PRCategoryRenovate:
-		return EmojiRenovate
-	case PRCategoryTranslation:
-		return EmojiTranslation
-	case PRCategoryGeneric:
-		return ""
-	default:
-		return EmojiMerged
-	}
+func (cs *CheckSummary) pullRequestProgress(category PRCategory) string {
+	info, _ := cs.category(category)
+	return info.Emoji
 }
 
 func pullRequestMergeability(pr *github.PullRequest) string {
@@ -227,10 +257,10 @@ func pullRequestMergeability(pr *github.PullRequest) string {
 	}
 }
 
-func pullRequestLabels(pr *github.PullRequest) string {
+func (cs *CheckSummary) pullRequestLabels(pr *github.PullRequest) string {
 	var labelNames []string
 	for _, label := range pr.Labels {
-		if label.Name == "verified" || label.Name == "testing" {
+		if label.Name == cs.VerifiedLabel || label.Name == cs.TestingLabel {
 			continue
 		}
 		labelNames = append(labelNames, label.Name)
@@ -245,7 +275,7 @@ func (cs *CheckSummary) AddIssuePullRequest(repo string, issueNumber int, pr *gi
 		return
 	}
 
-	prInfo := newPRInfo(repo, pr, category)
+	prInfo := cs.newPRInfo(repo, pr, category)
 	for _, existing := range info.LinkedPRs {
 		if existing.Number == prInfo.Number {
 			return
@@ -307,8 +337,10 @@ func (cs *CheckSummary) loadIssueInfo(client issueProvider, issueNumber, refCoun
 
 	if issue.State == "CLOSED" || issue.State == "closed" {
 		info.Status = EmojiClosedIssue
+		info.State = config.StateClosed
 	} else {
 		info.Status = EmojiOpenIssue
+		info.State = config.StateOpen
 	}
 
 	var labelNames []string
@@ -316,9 +348,10 @@ func (cs *CheckSummary) loadIssueInfo(client issueProvider, issueNumber, refCoun
 	hasTesting := false
 
 	for _, label := range issue.Labels {
-		if label.Name == "verified" {
+		info.labelNames = append(info.labelNames, label.Name)
+		if label.Name == cs.VerifiedLabel {
 			hasVerified = true
-		} else if label.Name == "testing" {
+		} else if label.Name == cs.TestingLabel {
 			hasTesting = true
 		} else {
 			labelNames = append(labelNames, label.Name)
@@ -336,6 +369,13 @@ func (cs *CheckSummary) loadIssueInfo(client issueProvider, issueNumber, refCoun
 		info.Progress = EmojiInProgress
 	}
 
+	if cs.ProgressSource != nil {
+		info.Progress, err = cs.ProgressSource.IssueProgress(cs.IssuesRepo, issueNumber)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get the progress of issue %d: %w", issueNumber, err)
+		}
+	}
+
 	return info, nil
 }
 
@@ -450,11 +490,15 @@ func bashHashString(key string) uint32 {
 
 // Display prints the check summary
 func (cs *CheckSummary) Display() {
-	// Open Weblate PRs warning
-	if len(cs.OpenWeblatePRs) > 0 {
-		fmt.Printf("%s⚠️  Open Weblate PRs detected:%s\n", ColorYellow, ColorReset)
-		for _, pr := range cs.OpenWeblatePRs {
-			fmt.Println(pr)
+	// Open PRs warnings, e.g. open Weblate PRs
+	for _, category := range cs.Categories {
+		urls := cs.OpenWarnings[category.Name]
+		if len(urls) == 0 {
+			continue
+		}
+		fmt.Printf("%s⚠️  %s:%s\n", ColorYellow, category.Warning, ColorReset)
+		for _, url := range urls {
+			fmt.Println(url)
 		}
 		fmt.Println()
 	}
@@ -479,7 +523,7 @@ func (cs *CheckSummary) Display() {
 		}
 	}
 
-	if len(cs.MergedPRs) == 0 && !cs.hasBlockedOpenPullRequests() && cs.allIssuesReadyToRelease() {
+	if len(cs.PullRequests[PRCategoryMerged]) == 0 && !cs.hasBlockedOpenPullRequests() && cs.allIssuesReadyToRelease() {
 		fmt.Println()
 		fmt.Printf("%s✅ All checks passed! Ready to release.%s\n", ColorGreen, ColorReset)
 	}
@@ -522,10 +566,22 @@ func displayedPullRequestProgress(info PRInfo) string {
 }
 
 func (cs *CheckSummary) hasPullRequests() bool {
-	return len(cs.RenovatePRs) > 0 ||
-		len(cs.TranslationPRs) > 0 ||
-		len(cs.GenericPRs) > 0 ||
-		len(cs.MergedPRs) > 0
+	for _, prs := range cs.PullRequests {
+		if len(prs) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// HasOpenWarnings tells whether open PRs are listed under a warning
+func (cs *CheckSummary) HasOpenWarnings() bool {
+	for _, urls := range cs.OpenWarnings {
+		if len(urls) > 0 {
+			return true
+		}
+	}
+	return false
 }
 
 func (cs *CheckSummary) hasBlockedOpenPullRequests() bool {
@@ -538,12 +594,7 @@ func (cs *CheckSummary) hasBlockedOpenPullRequests() bool {
 }
 
 func (cs *CheckSummary) allPullRequests() []PRInfo {
-	prs := make([]PRInfo, 0,
-		len(cs.RenovatePRs)+
-			len(cs.TranslationPRs)+
-			len(cs.GenericPRs)+
-			len(cs.MergedPRs))
-	prs = append(prs, cs.allTopLevelPullRequests()...)
+	prs := cs.allTopLevelPullRequests()
 	for _, issue := range cs.Issues {
 		prs = append(prs, issue.LinkedPRs...)
 	}
@@ -551,34 +602,24 @@ func (cs *CheckSummary) allPullRequests() []PRInfo {
 }
 
 func (cs *CheckSummary) allTopLevelPullRequests() []PRInfo {
-	prs := make([]PRInfo, 0,
-		len(cs.RenovatePRs)+
-			len(cs.TranslationPRs)+
-			len(cs.GenericPRs)+
-			len(cs.MergedPRs))
-	prs = append(prs, cs.RenovatePRs...)
-	prs = append(prs, cs.TranslationPRs...)
-	prs = append(prs, cs.GenericPRs...)
-	prs = append(prs, cs.MergedPRs...)
+	var prs []PRInfo
+	for _, category := range cs.Categories {
+		prs = append(prs, cs.PullRequests[category.Name]...)
+	}
 	return prs
 }
 
 func (cs *CheckSummary) orderedPullRequests() []PRInfo {
-	return orderedPullRequestInfos(cs.allTopLevelPullRequests())
+	return cs.orderedPullRequestInfos(cs.allTopLevelPullRequests())
 }
 
-func orderedPullRequestInfos(prs []PRInfo) []PRInfo {
+func (cs *CheckSummary) orderedPullRequestInfos(prs []PRInfo) []PRInfo {
 	ordered := make([]PRInfo, 0,
 		len(prs))
 	for _, status := range []string{EmojiOpenPR, EmojiMergedPR, EmojiClosedPR} {
-		for _, category := range []PRCategory{
-			PRCategoryRenovate,
-			PRCategoryTranslation,
-			PRCategoryGeneric,
-			PRCategoryMerged,
-		} {
+		for _, category := range cs.Categories {
 			for _, pr := range sortPullRequestGroup(prs) {
-				if pr.Status == status && pr.Category == category {
+				if pr.Status == status && pr.Category == category.Name {
 					ordered = append(ordered, pr)
 				}
 			}
@@ -599,7 +640,13 @@ func sortPullRequestGroup(prs []PRInfo) []PRInfo {
 func (cs *CheckSummary) displayPullRequestLegend() {
 	fmt.Println("---")
 	fmt.Printf("PR status:       %s Open    %s Merged    %s Closed\n", EmojiOpenPR, EmojiMergedPR, EmojiClosedPR)
-	fmt.Printf("PR type:         %s Renovate    %s Translation    %s Merged\n", EmojiRenovate, EmojiTranslation, EmojiMerged)
+	var types []string
+	for _, category := range cs.Categories {
+		if category.Emoji != "" {
+			types = append(types, category.Emoji+" "+category.Title)
+		}
+	}
+	fmt.Printf("PR type:         %s\n", strings.Join(types, "    "))
 }
 
 func (cs *CheckSummary) displayIssueLegend() {
@@ -610,10 +657,10 @@ func (cs *CheckSummary) displayIssueLegend() {
 
 func (cs *CheckSummary) allIssuesReadyToRelease() bool {
 	for _, info := range cs.Issues {
-		if len(info.Children) > 0 {
+		if len(info.Children) > 0 && !cs.Policy.parentsSelf {
 			continue
 		}
-		if !issueReadyToRelease(info) {
+		if !cs.issueReadyToRelease(info) {
 			return false
 		}
 	}
@@ -663,14 +710,17 @@ func (cs *CheckSummary) hasIssuesInGroup(group issueReleaseGroup) bool {
 	return false
 }
 
+// issueTreeMatchesGroup tells if an issue belongs to a group: a parent
+// issue does when any child does, unless the policy evaluates parents by
+// themselves
 func (cs *CheckSummary) issueTreeMatchesGroup(info *IssueInfo, group issueReleaseGroup) bool {
-	if len(info.Children) == 0 {
-		return issueMatchesGroup(info, group)
+	if len(info.Children) == 0 || cs.Policy.parentsSelf {
+		return cs.issueMatchesGroup(info, group)
 	}
 
 	for _, childNum := range info.Children {
 		childInfo, exists := cs.Issues[childNum]
-		if exists && issueMatchesGroup(childInfo, group) {
+		if exists && cs.issueMatchesGroup(childInfo, group) {
 			return true
 		}
 	}
@@ -678,33 +728,58 @@ func (cs *CheckSummary) issueTreeMatchesGroup(info *IssueInfo, group issueReleas
 	return false
 }
 
-func issueMatchesGroup(info *IssueInfo, group issueReleaseGroup) bool {
+func (cs *CheckSummary) issueMatchesGroup(info *IssueInfo, group issueReleaseGroup) bool {
 	switch group {
 	case issueReleaseGroupReady:
-		return issueReadyToRelease(info)
+		return cs.issueReadyToRelease(info)
 	case issueReleaseGroupToBeReleased:
-		return issueToBeReleased(info)
+		return cs.issueToBeReleased(info)
 	case issueReleaseGroupBlocker:
-		return issueBlocksRelease(info)
+		return cs.issueBlocksRelease(info)
 	default:
-		return !issueReadyToRelease(info) && !issueToBeReleased(info) && !issueBlocksRelease(info)
+		return !cs.issueReadyToRelease(info) && !cs.issueToBeReleased(info) && !cs.issueBlocksRelease(info)
 	}
 }
 
-func issueReadyToRelease(info *IssueInfo) bool {
-	return len(info.LinkedPRs) > 0 && allIssuePullRequestsMerged(info) && info.Progress == EmojiVerified
+// issueReadyToRelease tells if the policy accepts an issue whose PRs are
+// all merged
+func (cs *CheckSummary) issueReadyToRelease(info *IssueInfo) bool {
+	prs := cs.issuePullRequests(info)
+	return len(prs) > 0 && allPullRequestsMerged(prs) && cs.Policy.accepts(info, prs)
 }
 
-func issueToBeReleased(info *IssueInfo) bool {
-	return len(info.LinkedPRs) > 0 && !allIssuePullRequestsMerged(info) && info.Progress == EmojiVerified
+// issueToBeReleased tells if the policy accepts an issue with PRs still open
+func (cs *CheckSummary) issueToBeReleased(info *IssueInfo) bool {
+	prs := cs.issuePullRequests(info)
+	return len(prs) > 0 && !allPullRequestsMerged(prs) && cs.Policy.accepts(info, prs)
 }
 
-func issueBlocksRelease(info *IssueInfo) bool {
-	return issueHasMergedPullRequest(info) && info.Progress != EmojiVerified
+// issueBlocksRelease tells if an issue the policy does not accept has
+// merged PRs
+func (cs *CheckSummary) issueBlocksRelease(info *IssueInfo) bool {
+	prs := cs.issuePullRequests(info)
+	return hasMergedPullRequest(prs) && !cs.Policy.accepts(info, prs)
 }
 
-func allIssuePullRequestsMerged(info *IssueInfo) bool {
-	for _, pr := range info.LinkedPRs {
+// issuePullRequests returns the PRs deciding the readiness of an issue.
+// When the policy evaluates parents by themselves, a parent issue also
+// counts the PRs of its children.
+func (cs *CheckSummary) issuePullRequests(info *IssueInfo) []PRInfo {
+	if len(info.Children) == 0 || !cs.Policy.parentsSelf {
+		return info.LinkedPRs
+	}
+
+	prs := slices.Clone(info.LinkedPRs)
+	for _, childNum := range info.Children {
+		if childInfo, exists := cs.Issues[childNum]; exists {
+			prs = append(prs, childInfo.LinkedPRs...)
+		}
+	}
+	return prs
+}
+
+func allPullRequestsMerged(prs []PRInfo) bool {
+	for _, pr := range prs {
 		if pr.Status != EmojiMergedPR {
 			return false
 		}
@@ -712,8 +787,8 @@ func allIssuePullRequestsMerged(info *IssueInfo) bool {
 	return true
 }
 
-func issueHasMergedPullRequest(info *IssueInfo) bool {
-	for _, pr := range info.LinkedPRs {
+func hasMergedPullRequest(prs []PRInfo) bool {
+	for _, pr := range prs {
 		if pr.Status == EmojiMergedPR {
 			return true
 		}
@@ -722,7 +797,7 @@ func issueHasMergedPullRequest(info *IssueInfo) bool {
 }
 
 func (cs *CheckSummary) displayIssueInGroup(info *IssueInfo, group issueReleaseGroup) {
-	if len(info.Children) == 0 {
+	if len(info.Children) == 0 || cs.Policy.parentsSelf {
 		cs.displayIssue(info)
 		return
 	}
@@ -730,14 +805,14 @@ func (cs *CheckSummary) displayIssueInGroup(info *IssueInfo, group issueReleaseG
 	cs.displayIssueHeader(info)
 	for _, childNum := range info.Children {
 		childInfo, exists := cs.Issues[childNum]
-		if exists && issueMatchesGroup(childInfo, group) {
+		if exists && cs.issueMatchesGroup(childInfo, group) {
 			cs.displayChildIssue(childInfo)
 		}
 	}
 }
 
 func (cs *CheckSummary) displayIssueHeader(info *IssueInfo) {
-	issueURL := fmt.Sprintf("https://github.com/%s/issues/%d", cs.IssuesRepo, info.Number)
+	issueURL := github.WebURL(fmt.Sprintf("%s/issues/%d", cs.IssuesRepo, info.Number))
 	connector := "  "
 	if len(info.Children) == 0 {
 		connector = "──"
@@ -748,7 +823,7 @@ func (cs *CheckSummary) displayIssueHeader(info *IssueInfo) {
 		info.Progress,
 		titleLink(info.Number, info.Title, issueURL))
 
-	for _, pr := range orderedPullRequestInfos(info.LinkedPRs) {
+	for _, pr := range cs.orderedPullRequestInfos(info.LinkedPRs) {
 		displayNestedPullRequest(pr)
 	}
 }
@@ -767,13 +842,13 @@ func (cs *CheckSummary) displayIssue(info *IssueInfo) {
 
 // displayChildIssue displays a child issue with proper indentation
 func (cs *CheckSummary) displayChildIssue(info *IssueInfo) {
-	issueURL := fmt.Sprintf("https://github.com/%s/issues/%d", cs.IssuesRepo, info.Number)
+	issueURL := github.WebURL(fmt.Sprintf("%s/issues/%d", cs.IssuesRepo, info.Number))
 	fmt.Printf("└─%s %s %s\n",
 		info.Status,
 		info.Progress,
 		titleLink(info.Number, info.Title, issueURL))
 
-	for _, pr := range orderedPullRequestInfos(info.LinkedPRs) {
+	for _, pr := range cs.orderedPullRequestInfos(info.LinkedPRs) {
 		displayNestedPullRequest(pr)
 	}
 }
@@ -74,6 +74,48 @@ func TestProcessIssueKeepsParentRefsAtZeroUntilDirectlyLinked(t *testing.T) {
 	}
 }
 
+func TestProcessIssueUsesConfiguredProgressLabels(t *testing.T) {
+	summary := NewCheckSummary("NethServer/dev")
+	summary.TestingLabel = "qa"
+	summary.VerifiedLabel = "qa-passed"
+	provider := stubIssueProvider{
+		issues: map[int]*ghgithub.Issue{
+			1: {
+				Number: 1,
+				State:  "OPEN",
+				Labels: []struct {
+					Name string "json:\"name\""
+				}{
+					{Name: "qa-passed"},
+					{Name: "verified"},
+				},
+			},
+			2: {
+				Number: 2,
+				State:  "OPEN",
+				Labels: []struct {
+					Name string "json:\"name\""
+				}{
+					{Name: "qa"},
+				},
+			},
+		},
+	}
+
+	for _, number := range []int{1, 2} {
+		if err := summary.ProcessIssue(provider, number); err != nil {
+			t.Fatalf("ProcessIssue(%d) returned error: %v", number, err)
+		}
+	}
+
+	if got := summary.Issues[1]; got.Progress != EmojiVerified || got.Labels != "verified" {
+		t.Errorf("issue 1 progress = %s, labels = %q, want verified progress and the default label kept", got.Progress, got.Labels)
+	}
+	if got := summary.Issues[2]; got.Progress != EmojiTesting || got.Labels != "" {
+		t.Errorf("issue 2 progress = %s, labels = %q, want testing progress and no labels", got.Progress, got.Labels)
+	}
+}
+
 func TestBashAssocKeyOrderMatchesLegacyOrdering(t *testing.T) {
 	got := bashAssocKeyOrder([]int{7692, 7691, 7953, 7833, 7840, 7958, 7927, 7764, 7959, 7478, 7332, 7964, 7310})
 	want := []int{7927, 7953, 7958, 7959, 7833, 7332, 7840, 7764, 7310, 7478, 7691, 7692, 7964}
@@ -188,8 +230,8 @@ func TestDisplayShowsLinkedPullRequestsUnderIssues(t *testing.T) {
 		RefCount: 2,
 	}
 	issue.LinkedPRs = []PRInfo{
-		newPRInfo("NethServer/ns8-test", makeDisplayPullRequest(11, "closed", true, nil, "", false), PRCategoryMerged),
-		newPRInfo("NethServer/ns8-test", makeDisplayPullRequest(10, "open", false, &mergeable, "clean", false), PRCategoryGeneric),
+		summary.newPRInfo("NethServer/ns8-test", makeDisplayPullRequest(11, "closed", true, nil, "", false), PRCategoryMerged),
+		summary.newPRInfo("NethServer/ns8-test", makeDisplayPullRequest(10, "open", false, &mergeable, "clean", false), PRCategoryGeneric),
 	}
 	summary.Issues[100] = issue
 	summary.issueOrder = []int{100}
@@ -373,7 +415,7 @@ func TestDisplayReadyRequiresNoRemainingOrBlockedPRs(t *testing.T) {
 			{Number: 10, Status: EmojiMergedPR, URL: "https://github.com/NethServer/ns8-test/pull/10"},
 		},
 	}
-	withRemaining.MergedPRs = []PRInfo{{URL: "https://github.com/NethServer/ns8-test/pull/20"}}
+	withRemaining.PullRequests[PRCategoryMerged] = []PRInfo{{URL: "https://github.com/NethServer/ns8-test/pull/20"}}
 	output = captureStdout(t, withRemaining.Display)
 	if strings.Contains(output, "All checks passed! Ready to release.") {
 		t.Fatalf("ready message should be hidden with remaining PRs:\n%s", output)
@@ -398,9 +440,8 @@ func TestDisplayReadyRequiresNoRemainingOrBlockedPRs(t *testing.T) {
 
 func TestDisplayShowsOpenWeblateWarning(t *testing.T) {
 	summary := NewCheckSummary("NethServer/dev")
-	summary.OpenWeblatePRs = []string{
-		"https://github.com/NethServer/ns8-test/pull/30",
-	}
+	summary.AddOpenWarning(PRCategoryTranslation, "https://github.com/NethServer/ns8-test/pull/30")
+	summary.AddOpenWarning(PRCategoryGeneric, "https://github.com/NethServer/ns8-test/pull/31")
 
 	output := captureStdout(t, summary.Display)
 	if !strings.Contains(output, "Open Weblate PRs detected:") {
@@ -409,6 +450,9 @@ func TestDisplayShowsOpenWeblateWarning(t *testing.T) {
 	if !strings.Contains(output, "pull/30") {
 		t.Fatalf("missing open Weblate PR URL in output:\n%s", output)
 	}
+	if strings.Contains(output, "pull/31") {
+		t.Fatalf("open PR of a category without warning in output:\n%s", output)
+	}
 }
 
 func TestDisplayHidesEmptySections(t *testing.T) {
@@ -0,0 +1,88 @@
+package module_release
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+
+	"github.com/NethServer/gh-ns8/internal/github"
+)
+
+// lineReleaseScanLimit bounds how many releases are listed to find those of
+// a version line
+const lineReleaseScanLimit = 1000
+
+var (
+	versionLineRegex   = regexp.MustCompile(`^(0|[1-9][0-9]*)\.(0|[1-9][0-9]*)$`)
+	versionPrefixRegex = regexp.MustCompile(`^(\d+)\.(\d+)\.\d+`)
+)
+
+// VersionLine is a MAJOR.MINOR release series, such as 1.4 for the 1.4.x
+// releases and their pre-releases
+type VersionLine struct {
+	Major int
+	Minor int
+}
+
+// ParseVersionLine parses a MAJOR.MINOR version line
+func ParseVersionLine(line string) (*VersionLine, error) {
+	matches := versionLineRegex.FindStringSubmatch(line)
+	if matches == nil {
+		return nil, fmt.Errorf("invalid version line: %s (must be MAJOR.MINOR, e.g. 1.4)", line)
+	}
+
+	major, _ := strconv.Atoi(matches[1])
+	minor, _ := strconv.Atoi(matches[2])
+	return &VersionLine{Major: major, Minor: minor}, nil
+}
+
+// VersionLineOf returns the version line of a version, e.g. 1.4 for 1.4.3
+func VersionLineOf(version string) (*VersionLine, error) {
+	matches := versionPrefixRegex.FindStringSubmatch(version)
+	if matches == nil {
+		return nil, fmt.Errorf("invalid semver format: %s", version)
+	}
+
+	major, _ := strconv.Atoi(matches[1])
+	minor, _ := strconv.Atoi(matches[2])
+	return &VersionLine{Major: major, Minor: minor}, nil
+}
+
+// String implements fmt.Stringer
+func (l VersionLine) String() string {
+	return fmt.Sprintf("%d.%d", l.Major, l.Minor)
+}
+
+// Contains reports whether a version belongs to the line
+func (l VersionLine) Contains(version string) bool {
+	matches := versionPrefixRegex.FindStringSubmatch(version)
+	if matches == nil {
+		return false
+	}
+	return matches[1] == strconv.Itoa(l.Major) && matches[2] == strconv.Itoa(l.Minor)
+}
+
+// ListLineReleases lists releases like ListReleases, restricted to a version
+// line. A nil line lists every release.
+func ListLineReleases(client releaseClient, repo string, line *VersionLine, limit int, excludePreReleases bool) ([]github.Release, error) {
+	if line == nil {
+		return client.ListReleases(repo, limit, excludePreReleases)
+	}
+
+	releases, err := client.ListReleases(repo, lineReleaseScanLimit, excludePreReleases)
+	if err != nil {
+		return nil, err
+	}
+
+	var lineReleases []github.Release
+	for _, release := range releases {
+		if !line.Contains(release.TagName) {
+			continue
+		}
+		lineReleases = append(lineReleases, release)
+		if limit > 0 && len(lineReleases) == limit {
+			break
+		}
+	}
+	return lineReleases, nil
+}
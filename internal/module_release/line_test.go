@@ -0,0 +1,116 @@
+package module_release
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	ghgithub "github.com/NethServer/gh-ns8/internal/github"
+)
+
+func TestParseVersionLine(t *testing.T) {
+	testCases := []struct {
+		name    string
+		line    string
+		want    *VersionLine
+		wantErr bool
+	}{
+		{name: "major and minor", line: "1.4", want: &VersionLine{Major: 1, Minor: 4}},
+		{name: "zero major", line: "0.12", want: &VersionLine{Major: 0, Minor: 12}},
+		{name: "full version", line: "1.4.2", wantErr: true},
+		{name: "leading zero", line: "1.04", wantErr: true},
+		{name: "missing minor", line: "1", wantErr: true},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			got, err := ParseVersionLine(testCase.line)
+			if testCase.wantErr {
+				if err == nil || !strings.Contains(err.Error(), "invalid version line") {
+					t.Fatalf("ParseVersionLine(%q) error = %v, want invalid version line", testCase.line, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseVersionLine(%q) returned error: %v", testCase.line, err)
+			}
+			if !reflect.DeepEqual(got, testCase.want) {
+				t.Fatalf("ParseVersionLine(%q) = %+v, want %+v", testCase.line, got, testCase.want)
+			}
+		})
+	}
+}
+
+func TestVersionLineOf(t *testing.T) {
+	got, err := VersionLineOf("1.4.3-testing.2")
+	if err != nil {
+		t.Fatalf("VersionLineOf() returned error: %v", err)
+	}
+	if !reflect.DeepEqual(got, &VersionLine{Major: 1, Minor: 4}) {
+		t.Fatalf("VersionLineOf() = %+v, want 1.4", got)
+	}
+	if _, err := VersionLineOf("latest"); err == nil {
+		t.Fatal("VersionLineOf(latest) returned nil error, want invalid semver error")
+	}
+}
+
+func TestVersionLineContains(t *testing.T) {
+	line := VersionLine{Major: 1, Minor: 4}
+
+	testCases := []struct {
+		version string
+		want    bool
+	}{
+		{version: "1.4.0", want: true},
+		{version: "1.4.3-testing.2", want: true},
+		{version: "1.5.0", want: false},
+		{version: "11.4.0", want: false},
+		{version: "1.40.0", want: false},
+		{version: "latest", want: false},
+	}
+
+	for _, testCase := range testCases {
+		if got := line.Contains(testCase.version); got != testCase.want {
+			t.Errorf("Contains(%q) = %v, want %v", testCase.version, got, testCase.want)
+		}
+	}
+}
+
+func TestListLineReleasesFiltersAndLimits(t *testing.T) {
+	client := fakeReleaseClient{
+		releases: []ghgithub.Release{
+			{TagName: "1.5.1-testing.1", IsPrerelease: true},
+			{TagName: "1.4.3-testing.1", IsPrerelease: true},
+			{TagName: "1.5.0"},
+			{TagName: "1.4.2"},
+			{TagName: "1.4.1"},
+		},
+	}
+
+	got, err := ListLineReleases(client, "NethServer/ns8-mail", &VersionLine{Major: 1, Minor: 4}, 2, false)
+	if err != nil {
+		t.Fatalf("ListLineReleases() returned error: %v", err)
+	}
+
+	want := []ghgithub.Release{
+		{TagName: "1.4.3-testing.1", IsPrerelease: true},
+		{TagName: "1.4.2"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("ListLineReleases() = %+v, want %+v", got, want)
+	}
+}
+
+func TestListLineReleasesWithoutLineListsEverything(t *testing.T) {
+	client := fakeReleaseClient{
+		releases: []ghgithub.Release{{TagName: "1.5.0"}, {TagName: "1.4.2"}},
+	}
+
+	got, err := ListLineReleases(client, "NethServer/ns8-mail", nil, 1, false)
+	if err != nil {
+		t.Fatalf("ListLineReleases() returned error: %v", err)
+	}
+	if len(got) != 1 || got[0].TagName != "1.5.0" {
+		t.Fatalf("ListLineReleases() = %+v, want the latest release", got)
+	}
+}
@@ -0,0 +1,423 @@
+package module_release
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"text/template"
+
+	"github.com/NethServer/gh-ns8/internal/github"
+)
+
+// DefaultNotesTemplate is the built-in template of the native release notes
+const DefaultNotesTemplate = `{{define "pullRequest"}}{{.Title}} by @{{.Author}} in {{.URL}}{{if .Release}} ({{.Release}}){{end}}{{end}}
+{{- if .Issues}}
+## Features and fixes
+{{range .Issues}}
+- [{{.Ref}}]({{.URL}}) {{.Title}}
+{{- range .PullRequests}}
+  - {{template "pullRequest" .}}
+{{- end}}
+{{- range .Children}}
+  - [{{.Ref}}]({{.URL}}) {{.Title}}
+{{- range .PullRequests}}
+    - {{template "pullRequest" .}}
+{{- end}}
+{{- end}}
+{{- end}}
+{{end}}
+{{- range .Categories}}
+## {{.Emoji}} {{.Title}}
+{{range .PullRequests}}
+- {{template "pullRequest" .}}
+{{- end}}
+{{end}}
+{{- if .Other}}
+## Other changes
+{{range .Other}}
+- {{template "pullRequest" .}}
+{{- end}}
+{{end}}
+{{- if .Contributors}}
+## Contributors
+{{range .Contributors}}
+- @{{.}}
+{{- end}}
+{{end}}
+{{- if .PreReleases}}
+## Pre-releases
+
+This release includes the changes of {{range $i, $tag := .PreReleases}}{{if $i}}, {{end}}{{$tag}}{{end}}.
+{{range .PreReleaseNotes}}
+### {{.Tag}}
+
+{{.Notes}}
+{{end}}
+{{- end}}`
+
+// ReleaseNotes is the content of the native release notes of a range of
+// commits, passed to the notes template
+type ReleaseNotes struct {
+	Repo       string
+	IssuesRepo string
+	// Issues are the top-level linked issues, with their children
+	Issues []NotesIssue
+	// Categories are the configured PR categories with PRs not linked to
+	// issues, in configuration order
+	Categories []NotesCategory
+	// Other are the PRs neither linked to issues nor in a configured category
+	Other []NotesPullRequest
+	// Contributors are the PR author logins, bots excluded
+	Contributors []string
+	// PreReleases are the pre-releases aggregated by cumulative notes,
+	// oldest first
+	PreReleases []string
+	// PreReleaseNotes are the notes written by hand in the aggregated
+	// pre-releases that have some, oldest first
+	PreReleaseNotes []NotesPreRelease
+}
+
+// NotesPreRelease is the text written by hand in the notes of a pre-release
+type NotesPreRelease struct {
+	Tag   string
+	Notes string
+}
+
+// NotesIssue is an issue of the release notes
+type NotesIssue struct {
+	Number int
+	// Ref is the short reference, e.g. NethServer/dev#123
+	Ref          string
+	Title        string
+	URL          string
+	Labels       []string
+	PullRequests []NotesPullRequest
+	Children     []NotesIssue
+}
+
+// NotesCategory is a PR category of the release notes
+type NotesCategory struct {
+	Name         string
+	Title        string
+	Emoji        string
+	PullRequests []NotesPullRequest
+}
+
+// NotesPullRequest is a PR of the release notes
+type NotesPullRequest struct {
+	Number int
+	Title  string
+	URL    string
+	Author string
+	Labels []string
+	// Release is the pre-release that first shipped the PR, set by
+	// cumulative notes only
+	Release string
+}
+
+type releaseNotesClient interface {
+	GetPullRequest(repo string, number int) (*github.PullRequest, error)
+	issueProvider
+}
+
+type notesIssue struct {
+	NotesIssue
+	parent   int
+	children []int
+}
+
+// BuildReleaseNotes collects the release notes of the merged PRs of a range.
+// PRs are grouped under their linked issues, which are nested under their
+// parent issues, and the others by the classifier categories. PRs and
+// issues that cannot be read are skipped.
+func BuildReleaseNotes(client releaseNotesClient, classifier *PRClassifier, repo, issuesRepo string, prNumbers []int) *ReleaseNotes {
+	notes := &ReleaseNotes{Repo: repo, IssuesRepo: issuesRepo}
+	issues := make(map[int]*notesIssue)
+	categories := make(map[PRCategory][]NotesPullRequest)
+	contributors := make(map[string]bool)
+
+	loadIssue := func(number int) *notesIssue {
+		if info, exists := issues[number]; exists {
+			return info
+		}
+		issue, err := client.GetIssue(issuesRepo, number)
+		if err != nil {
+			return nil
+		}
+		info := &notesIssue{NotesIssue: NotesIssue{
+			Number: number,
+			Ref:    fmt.Sprintf("%s#%d", issuesRepo, number),
+			Title:  issue.Title,
+			URL:    github.WebURL(fmt.Sprintf("%s/issues/%d", issuesRepo, number)),
+		}}
+		for _, label := range issue.Labels {
+			info.Labels = append(info.Labels, label.Name)
+		}
+		issues[number] = info
+		return info
+	}
+
+	sortedNumbers := append([]int(nil), prNumbers...)
+	sort.Ints(sortedNumbers)
+	for _, prNum := range sortedNumbers {
+		pr, err := client.GetPullRequest(repo, prNum)
+		if err != nil || !pr.Merged {
+			continue
+		}
+
+		notesPR := NotesPullRequest{
+			Number: pr.Number,
+			Title:  pr.Title,
+			URL:    pullRequestURL(repo, pr),
+			Author: pr.User.Login,
+		}
+		for _, label := range pr.Labels {
+			notesPR.Labels = append(notesPR.Labels, label.Name)
+		}
+		if pr.User.Login != "" && !strings.HasSuffix(pr.User.Login, "[bot]") {
+			contributors[pr.User.Login] = true
+		}
+
+		linked := false
+		for _, issueNum := range GetLinkedIssues(pr.Body, issuesRepo) {
+			info := loadIssue(issueNum)
+			if info == nil {
+				continue
+			}
+			linked = true
+			if !containsNotesPullRequest(info.PullRequests, pr.Number) {
+				info.PullRequests = append(info.PullRequests, notesPR)
+			}
+		}
+		if !linked {
+			category := classifier.Categorize(pr)
+			categories[category] = append(categories[category], notesPR)
+		}
+	}
+
+	// Nest the linked issues under their parents
+	linkedNumbers := notesIssueNumbers(issues)
+	for _, number := range linkedNumbers {
+		parentNum, err := client.GetParentIssueNumber(issuesRepo, number)
+		if err != nil || parentNum <= 0 {
+			continue
+		}
+		if parent := loadIssue(parentNum); parent != nil {
+			issues[number].parent = parentNum
+			parent.children = append(parent.children, number)
+		}
+	}
+	var issueTree func(number int) NotesIssue
+	issueTree = func(number int) NotesIssue {
+		info := issues[number]
+		tree := info.NotesIssue
+		sort.Ints(info.children)
+		for _, child := range info.children {
+			tree.Children = append(tree.Children, issueTree(child))
+		}
+		return tree
+	}
+	for _, number := range notesIssueNumbers(issues) {
+		if issues[number].parent == 0 {
+			notes.Issues = append(notes.Issues, issueTree(number))
+		}
+	}
+
+	for _, category := range classifier.Categories() {
+		prs := categories[category.Name]
+		switch {
+		case len(prs) == 0:
+		case category.Name == PRCategoryGeneric || category.Name == PRCategoryMerged:
+			notes.Other = append(notes.Other, prs...)
+		default:
+			notes.Categories = append(notes.Categories, NotesCategory{
+				Name:         string(category.Name),
+				Title:        category.Title,
+				Emoji:        category.Emoji,
+				PullRequests: prs,
+			})
+		}
+	}
+
+	for login := range contributors {
+		notes.Contributors = append(notes.Contributors, login)
+	}
+	sort.Slice(notes.Contributors, func(i, j int) bool {
+		return strings.ToLower(notes.Contributors[i]) < strings.ToLower(notes.Contributors[j])
+	})
+
+	return notes
+}
+
+type cumulativeNotesClient interface {
+	releaseNotesClient
+	compareClient
+	GetReleaseNotes(repo, tag string) (string, error)
+}
+
+// BuildCumulativeReleaseNotes collects the release notes of a stable release
+// aggregating its pre-releases, oldest first: the notes of prNumbers, the
+// PRs of the previousRelease...head range, each marked with the first
+// pre-release that shipped it, and the notes written by hand in the
+// pre-releases, so the pre-releases can be deleted without losing their
+// notes.
+func BuildCumulativeReleaseNotes(client cumulativeNotesClient, classifier *PRClassifier, repo, issuesRepo, previousRelease string, preReleases []string, prNumbers []int) (*ReleaseNotes, error) {
+	releases := make(map[int]string)
+	var preReleaseNotes []NotesPreRelease
+	base := previousRelease
+	for _, tag := range preReleases {
+		body, err := client.GetReleaseNotes(repo, tag)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read the notes of %s: %w", tag, err)
+		}
+		if text := HandWrittenNotes(body); text != "" {
+			preReleaseNotes = append(preReleaseNotes, NotesPreRelease{Tag: tag, Notes: text})
+		}
+
+		// Pre-releases without PRs of their own, e.g. rebuilds of the same
+		// commit, fail the scan and have nothing to mark
+		stepPRs, err := ScanForPRs(client, repo, base, tag)
+		base = tag
+		if err != nil {
+			continue
+		}
+		for _, prNum := range stepPRs {
+			if _, exists := releases[prNum]; !exists {
+				releases[prNum] = tag
+			}
+		}
+	}
+
+	notes := BuildReleaseNotes(client, classifier, repo, issuesRepo, prNumbers)
+	notes.PreReleases = preReleases
+	notes.PreReleaseNotes = preReleaseNotes
+	notes.markReleases(releases)
+	return notes, nil
+}
+
+// markReleases sets the Release of the PRs of the notes
+func (n *ReleaseNotes) markReleases(releases map[int]string) {
+	mark := func(prs []NotesPullRequest) {
+		for i := range prs {
+			prs[i].Release = releases[prs[i].Number]
+		}
+	}
+	var markIssues func(issues []NotesIssue)
+	markIssues = func(issues []NotesIssue) {
+		for i := range issues {
+			mark(issues[i].PullRequests)
+			markIssues(issues[i].Children)
+		}
+	}
+
+	markIssues(n.Issues)
+	for i := range n.Categories {
+		mark(n.Categories[i].PullRequests)
+	}
+	mark(n.Other)
+}
+
+func containsNotesPullRequest(prs []NotesPullRequest, number int) bool {
+	for _, pr := range prs {
+		if pr.Number == number {
+			return true
+		}
+	}
+	return false
+}
+
+func notesIssueNumbers(issues map[int]*notesIssue) []int {
+	numbers := make([]int, 0, len(issues))
+	for number := range issues {
+		numbers = append(numbers, number)
+	}
+	sort.Ints(numbers)
+	return numbers
+}
+
+// Render renders the notes with a text/template source, DefaultNotesTemplate
+// when text is empty. Leading blank lines are removed, so that each section of
+// a template can start with one.
+func (n *ReleaseNotes) Render(text string) (string, error) {
+	if text == "" {
+		text = DefaultNotesTemplate
+	}
+
+	tmpl, err := template.New("notes").Parse(text)
+	if err != nil {
+		return "", fmt.Errorf("invalid notes template: %w", err)
+	}
+
+	var notes strings.Builder
+	if err := tmpl.Execute(&notes, n); err != nil {
+		return "", fmt.Errorf("failed to render the release notes: %w", err)
+	}
+	return strings.TrimLeft(notes.String(), "\n"), nil
+}
+
+// Markers delimiting the notes managed by the extension in a release body,
+// so that they can be regenerated keeping the text written by hand
+const (
+	NotesStartMarker = "<!-- gh-ns8 notes start -->"
+	NotesEndMarker   = "<!-- gh-ns8 notes end -->"
+)
+
+// CumulativeNotesMarker starts the managed notes of a stable release that
+// aggregate its pre-releases, so that they are regenerated the same way
+const CumulativeNotesMarker = "<!-- gh-ns8 cumulative notes -->"
+
+// IsCumulativeNotes tells if the managed section of a release body holds
+// cumulative notes
+func IsCumulativeNotes(body string) bool {
+	start := strings.Index(body, NotesStartMarker)
+	end := strings.Index(body, NotesEndMarker)
+	if start == -1 || end < start {
+		return false
+	}
+	return strings.Contains(body[start:end], CumulativeNotesMarker)
+}
+
+// ManagedNotes wraps notes in the managed section markers, empty notes stay
+// empty
+func ManagedNotes(notes string) string {
+	notes = strings.Trim(notes, "\n")
+	if notes == "" {
+		return ""
+	}
+	return NotesStartMarker + "\n" + notes + "\n" + NotesEndMarker
+}
+
+// generatedNotesRegex matches the notes generated by GitHub in a release
+// body, up to their full changelog link
+var generatedNotesRegex = regexp.MustCompile(`(?s)(<!-- Release notes generated[^\n]*-->\s*)?(## What's Changed\n.*?)?\*\*Full Changelog\*\*: \S+`)
+
+// HandWrittenNotes returns the text of a release body written by hand,
+// without the managed section and the notes generated by GitHub
+func HandWrittenNotes(body string) string {
+	body, _ = ReplaceManagedNotes(body, "")
+	return strings.TrimSpace(generatedNotesRegex.ReplaceAllString(body, ""))
+}
+
+// ReplaceManagedNotes replaces the managed section of a release body with
+// notes and reports whether the body had one. Without a managed section the
+// notes are prepended, as in a new release; empty notes remove the section.
+func ReplaceManagedNotes(body, notes string) (string, bool) {
+	managed := ManagedNotes(notes)
+
+	start := strings.Index(body, NotesStartMarker)
+	end := strings.Index(body, NotesEndMarker)
+	if start == -1 || end < start {
+		if managed == "" || body == "" {
+			return managed + body, false
+		}
+		return managed + "\n\n" + body, false
+	}
+
+	before := body[:start]
+	after := body[end+len(NotesEndMarker):]
+	if managed == "" {
+		return strings.TrimLeft(strings.TrimRight(before, "\n")+after, "\n"), true
+	}
+	return before + managed + after, true
+}
@@ -0,0 +1,338 @@
+package module_release
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/NethServer/gh-ns8/internal/config"
+	ghgithub "github.com/NethServer/gh-ns8/internal/github"
+)
+
+type fakeReleaseNotesClient struct {
+	stubIssueProvider
+	prs map[int]*ghgithub.PullRequest
+}
+
+func (f fakeReleaseNotesClient) GetPullRequest(_ string, number int) (*ghgithub.PullRequest, error) {
+	if pr, ok := f.prs[number]; ok {
+		return pr, nil
+	}
+	return nil, errors.New("missing PR")
+}
+
+func makeNotesPullRequest(number int, title, author, body string) *ghgithub.PullRequest {
+	pr := makeDisplayPullRequest(number, "closed", true, nil, "", false)
+	pr.Title = title
+	pr.Body = body
+	pr.User.Login = author
+	return pr
+}
+
+type fakeCumulativeNotesClient struct {
+	fakeReleaseNotesClient
+	comparisons map[string]*ghgithub.CompareResult
+	commitPRs   map[string][]int
+	bodies      map[string]string
+}
+
+func (f fakeCumulativeNotesClient) GetReleaseNotes(_ string, tag string) (string, error) {
+	body, ok := f.bodies[tag]
+	if !ok {
+		return "", ghgithub.ErrNotFound
+	}
+	return body, nil
+}
+
+func (f fakeCumulativeNotesClient) CompareCommits(_, base, head string) (*ghgithub.CompareResult, error) {
+	if comparison, ok := f.comparisons[base+"..."+head]; ok {
+		return comparison, nil
+	}
+	return nil, errors.New("missing comparison")
+}
+
+func (f fakeCumulativeNotesClient) GetPullRequestsForCommit(_ string, sha string) ([]int, error) {
+	return f.commitPRs[sha], nil
+}
+
+func newNotesTestClient() fakeReleaseNotesClient {
+	return fakeReleaseNotesClient{
+		stubIssueProvider: stubIssueProvider{
+			issues: map[int]*ghgithub.Issue{
+				10:  {Title: "Fix login loop"},
+				11:  {Title: "Add phone model"},
+				100: {Title: "Phone support"},
+			},
+			parents: map[int]int{11: 100},
+		},
+		prs: map[int]*ghgithub.PullRequest{
+			1: makeNotesPullRequest(1, "Fix redirect", "alice", "Refs NethServer/dev#10"),
+			2: makeNotesPullRequest(2, "Phone template", "bob", "Refs NethServer/dev#11 and NethServer/dev#10"),
+			3: makeNotesPullRequest(3, "Update dependency foo", "renovate[bot]", ""),
+			4: makeNotesPullRequest(4, "Translations update", "weblate", ""),
+			5: makeNotesPullRequest(5, "Clean up CI", "Carol", ""),
+			6: makeDisplayPullRequest(6, "closed", false, nil, "", false),
+		},
+	}
+}
+
+func TestBuildReleaseNotesGroupsPullRequests(t *testing.T) {
+	classifier, err := NewPRClassifier(config.Default().Categories)
+	if err != nil {
+		t.Fatalf("NewPRClassifier() returned error: %v", err)
+	}
+
+	notes := BuildReleaseNotes(newNotesTestClient(), classifier, "NethServer/ns8-test", "NethServer/dev", []int{5, 4, 3, 2, 1, 6, 7})
+
+	var issues []string
+	for _, issue := range notes.Issues {
+		issues = append(issues, issue.Ref)
+		for _, child := range issue.Children {
+			issues = append(issues, "  "+child.Ref)
+		}
+	}
+	if want := []string{"NethServer/dev#10", "NethServer/dev#100", "  NethServer/dev#11"}; !reflect.DeepEqual(issues, want) {
+		t.Fatalf("Issues = %v, want %v", issues, want)
+	}
+	if prs := notes.Issues[0].PullRequests; len(prs) != 2 || prs[0].Number != 1 || prs[1].Number != 2 {
+		t.Fatalf("Issues[0].PullRequests = %v, want PRs 1 and 2", prs)
+	}
+
+	var categories []string
+	for _, category := range notes.Categories {
+		categories = append(categories, category.Name)
+	}
+	if want := []string{"renovate", "translation"}; !reflect.DeepEqual(categories, want) {
+		t.Fatalf("Categories = %v, want %v", categories, want)
+	}
+	if len(notes.Other) != 1 || notes.Other[0].Number != 5 {
+		t.Fatalf("Other = %v, want PR 5", notes.Other)
+	}
+	if want := []string{"alice", "bob", "Carol", "weblate"}; !reflect.DeepEqual(notes.Contributors, want) {
+		t.Fatalf("Contributors = %v, want %v", notes.Contributors, want)
+	}
+}
+
+func TestReleaseNotesRenderDefaultTemplate(t *testing.T) {
+	classifier, err := NewPRClassifier(config.Default().Categories)
+	if err != nil {
+		t.Fatalf("NewPRClassifier() returned error: %v", err)
+	}
+
+	notes := BuildReleaseNotes(newNotesTestClient(), classifier, "NethServer/ns8-test", "NethServer/dev", []int{1, 2, 3, 4, 5})
+	got, err := notes.Render("")
+	if err != nil {
+		t.Fatalf("Render() returned error: %v", err)
+	}
+
+	want := `## Features and fixes
+
+- [NethServer/dev#10](https://github.com/NethServer/dev/issues/10) Fix login loop
+  - Fix redirect by @alice in https://github.com/NethServer/ns8-test/pull/1
+  - Phone template by @bob in https://github.com/NethServer/ns8-test/pull/2
+- [NethServer/dev#100](https://github.com/NethServer/dev/issues/100) Phone support
+  - [NethServer/dev#11](https://github.com/NethServer/dev/issues/11) Add phone model
+    - Phone template by @bob in https://github.com/NethServer/ns8-test/pull/2
+
+## 🤖 Renovate
+
+- Update dependency foo by @renovate[bot] in https://github.com/NethServer/ns8-test/pull/3
+
+## 🌐 Translation
+
+- Translations update by @weblate in https://github.com/NethServer/ns8-test/pull/4
+
+## Other changes
+
+- Clean up CI by @Carol in https://github.com/NethServer/ns8-test/pull/5
+
+## Contributors
+
+- @alice
+- @bob
+- @Carol
+- @weblate
+`
+	if got != want {
+		t.Fatalf("Render() =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestReleaseNotesRenderCustomTemplate(t *testing.T) {
+	notes := &ReleaseNotes{Repo: "NethServer/ns8-test", Contributors: []string{"alice", "bob"}}
+
+	got, err := notes.Render(`{{.Repo}}: {{len .Contributors}} contributors`)
+	if err != nil {
+		t.Fatalf("Render() returned error: %v", err)
+	}
+	if got != "NethServer/ns8-test: 2 contributors" {
+		t.Fatalf("Render() = %q, want the custom template output", got)
+	}
+
+	if _, err := notes.Render(`{{.Missing}}`); err == nil || !strings.Contains(err.Error(), "release notes") {
+		t.Fatalf("Render() error = %v, want a rendering error", err)
+	}
+}
+
+func TestBuildCumulativeReleaseNotesMarksPreReleases(t *testing.T) {
+	classifier, err := NewPRClassifier(config.Default().Categories)
+	if err != nil {
+		t.Fatalf("NewPRClassifier() returned error: %v", err)
+	}
+
+	client := fakeCumulativeNotesClient{
+		fakeReleaseNotesClient: newNotesTestClient(),
+		comparisons: map[string]*ghgithub.CompareResult{
+			"1.1.0...1.2.0-testing.1":           makeCompareResult("a"),
+			"1.2.0-testing.1...1.2.0-testing.2": makeCompareResult("b", "c"),
+			"1.2.0-testing.2...1.2.0-testing.3": makeCompareResult(),
+		},
+		commitPRs: map[string][]int{
+			"a": {1},
+			"b": {2, 1},
+			"c": {5},
+			"d": {4},
+		},
+		bodies: map[string]string{
+			"1.2.0-testing.1": NotesStartMarker + "\n## Linked Issues\n" + NotesEndMarker,
+			"1.2.0-testing.2": "Run the migration before updating.\n\n" + NotesStartMarker + "\n## Linked Issues\n" + NotesEndMarker,
+			"1.2.0-testing.3": "",
+		},
+	}
+
+	notes, err := BuildCumulativeReleaseNotes(client, classifier, "NethServer/ns8-test", "NethServer/dev", "1.1.0", []string{"1.2.0-testing.1", "1.2.0-testing.2", "1.2.0-testing.3"}, []int{1, 2, 4, 5})
+	if err != nil {
+		t.Fatalf("BuildCumulativeReleaseNotes() returned error: %v", err)
+	}
+
+	releases := map[int]string{}
+	for _, pr := range notes.Issues[0].PullRequests {
+		releases[pr.Number] = pr.Release
+	}
+	for _, pr := range notes.Issues[1].Children[0].PullRequests {
+		releases[pr.Number] = pr.Release
+	}
+	for _, category := range notes.Categories {
+		for _, pr := range category.PullRequests {
+			releases[pr.Number] = pr.Release
+		}
+	}
+	for _, pr := range notes.Other {
+		releases[pr.Number] = pr.Release
+	}
+	want := map[int]string{1: "1.2.0-testing.1", 2: "1.2.0-testing.2", 4: "", 5: "1.2.0-testing.2"}
+	if !reflect.DeepEqual(releases, want) {
+		t.Fatalf("PR releases = %v, want %v", releases, want)
+	}
+
+	got, err := notes.Render("")
+	if err != nil {
+		t.Fatalf("Render() returned error: %v", err)
+	}
+	for _, line := range []string{
+		"  - Fix redirect by @alice in https://github.com/NethServer/ns8-test/pull/1 (1.2.0-testing.1)\n",
+		"- Translations update by @weblate in https://github.com/NethServer/ns8-test/pull/4\n",
+		"This release includes the changes of 1.2.0-testing.1, 1.2.0-testing.2, 1.2.0-testing.3.\n\n### 1.2.0-testing.2\n\nRun the migration before updating.\n",
+	} {
+		if !strings.Contains(got, line) {
+			t.Fatalf("Render() =\n%s\nwant it to contain %q", got, line)
+		}
+	}
+}
+
+func TestBuildCumulativeReleaseNotesReturnsNotesReadErrors(t *testing.T) {
+	classifier, err := NewPRClassifier(config.Default().Categories)
+	if err != nil {
+		t.Fatalf("NewPRClassifier() returned error: %v", err)
+	}
+	client := fakeCumulativeNotesClient{fakeReleaseNotesClient: newNotesTestClient()}
+
+	_, err = BuildCumulativeReleaseNotes(client, classifier, "NethServer/ns8-test", "NethServer/dev", "1.1.0", []string{"1.2.0-testing.1"}, []int{1})
+	if !errors.Is(err, ghgithub.ErrNotFound) {
+		t.Fatalf("BuildCumulativeReleaseNotes() error = %v, want ErrNotFound", err)
+	}
+}
+
+func TestHandWrittenNotes(t *testing.T) {
+	generated := "<!-- Release notes generated using configuration in .github/release.yml at main -->\n\n" +
+		"## What's Changed\n* Fix redirect by @alice in https://github.com/NethServer/ns8-test/pull/1\n\n" +
+		"**Full Changelog**: https://github.com/NethServer/ns8-test/compare/1.1.0...1.2.0-testing.1"
+	managed := NotesStartMarker + "\n## Linked Issues\n- old\n" + NotesEndMarker
+
+	tests := []struct {
+		name string
+		body string
+		want string
+	}{
+		{name: "managed and generated notes only", body: managed + "\n\n" + generated},
+		{name: "text around the managed section", body: "Intro\n\n" + managed + "\n\nOutro\n", want: "Intro\n\nOutro"},
+		{name: "text before the generated notes", body: managed + "\n\nKnown issue: slow login\n\n" + generated, want: "Known issue: slow login"},
+		{name: "full changelog link only", body: "**Full Changelog**: https://github.com/NethServer/ns8-test/commits/1.2.0-testing.1"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := HandWrittenNotes(tt.body); got != tt.want {
+				t.Fatalf("HandWrittenNotes() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestIsCumulativeNotes(t *testing.T) {
+	cumulative := ManagedNotes(CumulativeNotesMarker + "\n## Pre-releases\n")
+	if !IsCumulativeNotes("Intro\n\n" + cumulative) {
+		t.Fatal("IsCumulativeNotes() = false, want true for a cumulative managed section")
+	}
+	if IsCumulativeNotes(ManagedNotes("## Linked Issues\n") + "\n\n" + CumulativeNotesMarker) {
+		t.Fatal("IsCumulativeNotes() = true, want false for a marker outside the managed section")
+	}
+}
+
+func TestReplaceManagedNotes(t *testing.T) {
+	managed := NotesStartMarker + "\n## Linked Issues\n- old\n" + NotesEndMarker
+
+	tests := []struct {
+		name        string
+		body        string
+		notes       string
+		want        string
+		wantManaged bool
+	}{
+		{
+			name:        "replaces the managed section",
+			body:        "Hand-written intro\n\n" + managed + "\n\n## What's Changed\n",
+			notes:       "## Linked Issues\n- new\n",
+			want:        "Hand-written intro\n\n" + NotesStartMarker + "\n## Linked Issues\n- new\n" + NotesEndMarker + "\n\n## What's Changed\n",
+			wantManaged: true,
+		},
+		{
+			name:  "prepends to a body without markers",
+			body:  "## What's Changed\n",
+			notes: "## Linked Issues\n- new\n",
+			want:  NotesStartMarker + "\n## Linked Issues\n- new\n" + NotesEndMarker + "\n\n## What's Changed\n",
+		},
+		{
+			name:        "removes the section of empty notes",
+			body:        managed + "\n\n## What's Changed\n",
+			want:        "## What's Changed\n",
+			wantManaged: true,
+		},
+		{
+			name:  "keeps an empty body empty",
+			body:  "",
+			notes: "",
+			want:  "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, hadManaged := ReplaceManagedNotes(tt.body, tt.notes)
+			if got != tt.want || hadManaged != tt.wantManaged {
+				t.Fatalf("ReplaceManagedNotes() = %q, %v, want %q, %v", got, hadManaged, tt.want, tt.wantManaged)
+			}
+		})
+	}
+}
@@ -0,0 +1,48 @@
+package module_release
+
+import (
+	"strings"
+
+	"github.com/NethServer/gh-ns8/internal/config"
+)
+
+// ProgressSource gives the progress emoji of an issue, replacing the one of
+// its testing and verified labels
+type ProgressSource interface {
+	IssueProgress(repo string, issueNumber int) (string, error)
+}
+
+type projectFieldClient interface {
+	GetIssueProjectFieldValue(repo string, issueNumber int, owner string, number int, field string) (string, error)
+}
+
+// ProjectProgress reads the issue progress from the status field of a
+// Projects v2 board
+type ProjectProgress struct {
+	client  projectFieldClient
+	project config.Project
+}
+
+// NewProjectProgress creates the progress source of project
+func NewProjectProgress(client projectFieldClient, project config.Project) *ProjectProgress {
+	return &ProjectProgress{client: client, project: project}
+}
+
+// IssueProgress maps the status of the issue to a progress emoji: issues
+// outside the project, or with another status, are in progress
+func (p *ProjectProgress) IssueProgress(repo string, issueNumber int) (string, error) {
+	status := p.project.Status
+	value, err := p.client.GetIssueProjectFieldValue(repo, issueNumber, p.project.Owner, p.project.Number, status.Field)
+	if err != nil {
+		return "", err
+	}
+
+	switch {
+	case strings.EqualFold(value, status.Verified):
+		return EmojiVerified, nil
+	case strings.EqualFold(value, status.Testing):
+		return EmojiTesting, nil
+	default:
+		return EmojiInProgress, nil
+	}
+}
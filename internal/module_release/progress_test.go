@@ -0,0 +1,79 @@
+package module_release
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/NethServer/gh-ns8/internal/config"
+	ghgithub "github.com/NethServer/gh-ns8/internal/github"
+)
+
+type fakeProjectFieldClient struct {
+	values map[int]string
+	err    error
+}
+
+func (f fakeProjectFieldClient) GetIssueProjectFieldValue(_ string, issueNumber int, owner string, number int, field string) (string, error) {
+	if owner != "NethServer" || number != 12 || field != "Status" {
+		return "", errors.New("unexpected project field")
+	}
+	return f.values[issueNumber], f.err
+}
+
+func testProject() config.Project {
+	project := config.Default().Project
+	project.Number = 12
+	return project
+}
+
+func TestProjectProgressMapsStatus(t *testing.T) {
+	progress := NewProjectProgress(fakeProjectFieldClient{values: map[int]string{
+		1: "Verified",
+		2: "testing",
+		3: "In progress",
+	}}, testProject())
+
+	tests := []struct {
+		issue int
+		want  string
+	}{
+		{issue: 1, want: EmojiVerified},
+		{issue: 2, want: EmojiTesting},
+		{issue: 3, want: EmojiInProgress},
+		{issue: 4, want: EmojiInProgress},
+	}
+
+	for _, tt := range tests {
+		got, err := progress.IssueProgress("NethServer/dev", tt.issue)
+		if err != nil {
+			t.Fatalf("IssueProgress(%d) returned error: %v", tt.issue, err)
+		}
+		if got != tt.want {
+			t.Errorf("IssueProgress(%d) = %q, want %q", tt.issue, got, tt.want)
+		}
+	}
+}
+
+func TestProcessIssueUsesProgressSource(t *testing.T) {
+	issue := &ghgithub.Issue{Title: "Tracked on the board", State: "OPEN"}
+	issue.Labels = append(issue.Labels, struct {
+		Name string `json:"name"`
+	}{Name: "testing"})
+	provider := stubIssueProvider{issues: map[int]*ghgithub.Issue{10: issue, 11: issue}}
+
+	summary := NewCheckSummary("NethServer/dev")
+	summary.ProgressSource = NewProjectProgress(fakeProjectFieldClient{values: map[int]string{10: "Verified"}}, testProject())
+	if err := summary.ProcessIssue(provider, 10); err != nil {
+		t.Fatalf("ProcessIssue() returned error: %v", err)
+	}
+	if got := summary.Issues[10].Progress; got != EmojiVerified {
+		t.Fatalf("Progress = %q, want the project status %q", got, EmojiVerified)
+	}
+
+	summary.ProgressSource = NewProjectProgress(fakeProjectFieldClient{err: errors.New("missing read:project scope")}, testProject())
+	err := summary.ProcessIssue(provider, 11)
+	if err == nil || !strings.Contains(err.Error(), "missing read:project scope") {
+		t.Fatalf("ProcessIssue() error = %v, want the project error", err)
+	}
+}
@@ -0,0 +1,101 @@
+package module_release
+
+import (
+	"fmt"
+
+	"github.com/NethServer/gh-ns8/internal/config"
+	"github.com/NethServer/gh-ns8/internal/github"
+)
+
+type projectItemClient interface {
+	GetProject(owner string, number int) (*github.Project, error)
+	GetIssueProjectItem(repo string, issueNumber int, projectID string) (*github.ProjectItem, error)
+	UpdateProjectItemField(projectID, itemID string, field github.ProjectField, value string) error
+}
+
+// ProjectFieldValue is a value to set in a project field
+type ProjectFieldValue struct {
+	Field string
+	Value string
+}
+
+// ProjectFieldChange is a project field set by the ProjectUpdater
+type ProjectFieldChange struct {
+	Field string
+	From  string
+	To    string
+}
+
+// ReleaseProjectValues returns the project field values of a release, as
+// configured in the project updates. date is formatted as YYYY-MM-DD.
+func ReleaseProjectValues(project config.Project, version, date string, prerelease bool) []ProjectFieldValue {
+	var values []ProjectFieldValue
+	status := project.Updates.ReleaseStatus
+	if prerelease {
+		status = project.Updates.PrereleaseStatus
+	}
+	if status != "" {
+		values = append(values, ProjectFieldValue{Field: project.Status.Field, Value: status})
+	}
+	if project.Updates.VersionField != "" {
+		values = append(values, ProjectFieldValue{Field: project.Updates.VersionField, Value: version})
+	}
+	if project.Updates.DateField != "" {
+		values = append(values, ProjectFieldValue{Field: project.Updates.DateField, Value: date})
+	}
+	return values
+}
+
+// ProjectUpdater sets field values on the project items of issues
+type ProjectUpdater struct {
+	client  projectItemClient
+	project *github.Project
+	fields  []github.ProjectField
+	values  []ProjectFieldValue
+}
+
+// NewProjectUpdater loads the configured project and checks it has the
+// fields of values
+func NewProjectUpdater(client projectItemClient, project config.Project, values []ProjectFieldValue) (*ProjectUpdater, error) {
+	board, err := client.GetProject(project.Owner, project.Number)
+	if err != nil {
+		return nil, err
+	}
+
+	updater := &ProjectUpdater{client: client, project: board, values: values}
+	for _, value := range values {
+		field, ok := board.Field(value.Field)
+		if !ok {
+			return nil, fmt.Errorf("project %s/%d has no field %q", project.Owner, project.Number, value.Field)
+		}
+		updater.fields = append(updater.fields, field)
+	}
+	return updater, nil
+}
+
+// UpdateIssue sets the values on the project item of an issue and returns
+// the changed fields; fields already set to their value are left alone. With
+// dryRun the changes are only computed. It returns github.ErrNotFound when
+// the issue is not in the project.
+func (u *ProjectUpdater) UpdateIssue(repo string, issueNumber int, dryRun bool) ([]ProjectFieldChange, error) {
+	item, err := u.client.GetIssueProjectItem(repo, issueNumber, u.project.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	var changes []ProjectFieldChange
+	for i, value := range u.values {
+		field := u.fields[i]
+		current := item.Values[field.Name]
+		if current == value.Value {
+			continue
+		}
+		if !dryRun {
+			if err := u.client.UpdateProjectItemField(u.project.ID, item.ID, field, value.Value); err != nil {
+				return changes, err
+			}
+		}
+		changes = append(changes, ProjectFieldChange{Field: field.Name, From: current, To: value.Value})
+	}
+	return changes, nil
+}
@@ -0,0 +1,118 @@
+package module_release
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/NethServer/gh-ns8/internal/config"
+	ghgithub "github.com/NethServer/gh-ns8/internal/github"
+)
+
+type fakeProjectItemClient struct {
+	project *ghgithub.Project
+	items   map[int]*ghgithub.ProjectItem
+	updated []string
+}
+
+func (f *fakeProjectItemClient) GetProject(_ string, _ int) (*ghgithub.Project, error) {
+	return f.project, nil
+}
+
+func (f *fakeProjectItemClient) GetIssueProjectItem(_ string, issueNumber int, _ string) (*ghgithub.ProjectItem, error) {
+	if item, ok := f.items[issueNumber]; ok {
+		return item, nil
+	}
+	return nil, ghgithub.ErrNotFound
+}
+
+func (f *fakeProjectItemClient) UpdateProjectItemField(_, itemID string, field ghgithub.ProjectField, value string) error {
+	f.updated = append(f.updated, itemID+" "+field.Name+"="+value)
+	return nil
+}
+
+func newFakeProjectItemClient() *fakeProjectItemClient {
+	return &fakeProjectItemClient{
+		project: &ghgithub.Project{ID: "PROJECT_1", Fields: []ghgithub.ProjectField{
+			{ID: "FIELD_status", Name: "Status", DataType: ghgithub.ProjectFieldSingleSelect},
+			{ID: "FIELD_version", Name: "Released in", DataType: ghgithub.ProjectFieldText},
+			{ID: "FIELD_date", Name: "Release date", DataType: ghgithub.ProjectFieldDate},
+		}},
+		items: map[int]*ghgithub.ProjectItem{
+			10: {ID: "ITEM_10", Values: map[string]string{"Status": "In progress"}},
+			11: {ID: "ITEM_11", Values: map[string]string{"Status": "Testing", "Released in": "1.2.0-testing.1"}},
+		},
+	}
+}
+
+func testProjectUpdates() config.Project {
+	project := testProject()
+	project.Updates = config.ProjectUpdates{
+		PrereleaseStatus: "Testing",
+		ReleaseStatus:    "Done",
+		VersionField:     "Released in",
+		DateField:        "Release date",
+	}
+	return project
+}
+
+func TestReleaseProjectValues(t *testing.T) {
+	project := testProjectUpdates()
+
+	got := ReleaseProjectValues(project, "1.2.0", "2026-10-18", false)
+	want := []ProjectFieldValue{
+		{Field: "Status", Value: "Done"},
+		{Field: "Released in", Value: "1.2.0"},
+		{Field: "Release date", Value: "2026-10-18"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("ReleaseProjectValues() = %v, want %v", got, want)
+	}
+
+	got = ReleaseProjectValues(config.Default().Project, "1.2.0-testing.1", "2026-10-18", true)
+	if want := []ProjectFieldValue{{Field: "Status", Value: "Testing"}}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("ReleaseProjectValues() = %v, want only the default prerelease status %v", got, want)
+	}
+	if got := ReleaseProjectValues(config.Default().Project, "1.2.0", "2026-10-18", false); len(got) != 0 {
+		t.Fatalf("ReleaseProjectValues() = %v, want none for a release by default", got)
+	}
+}
+
+func TestProjectUpdaterSetsChangedFields(t *testing.T) {
+	client := newFakeProjectItemClient()
+	values := ReleaseProjectValues(testProjectUpdates(), "1.2.0-testing.1", "2026-10-18", true)
+	updater, err := NewProjectUpdater(client, testProjectUpdates(), values)
+	if err != nil {
+		t.Fatalf("NewProjectUpdater() returned error: %v", err)
+	}
+
+	changes, err := updater.UpdateIssue("NethServer/dev", 11, false)
+	if err != nil {
+		t.Fatalf("UpdateIssue() returned error: %v", err)
+	}
+	if want := []ProjectFieldChange{{Field: "Release date", To: "2026-10-18"}}; !reflect.DeepEqual(changes, want) {
+		t.Fatalf("UpdateIssue() = %v, want only the missing date %v", changes, want)
+	}
+
+	changes, err = updater.UpdateIssue("NethServer/dev", 10, true)
+	if err != nil {
+		t.Fatalf("UpdateIssue() returned error: %v", err)
+	}
+	if len(changes) != 3 || changes[0] != (ProjectFieldChange{Field: "Status", From: "In progress", To: "Testing"}) {
+		t.Fatalf("UpdateIssue() = %v, want the three fields", changes)
+	}
+	if want := []string{"ITEM_11 Release date=2026-10-18"}; !reflect.DeepEqual(client.updated, want) {
+		t.Fatalf("updated fields = %v, want %v and nothing in the dry run", client.updated, want)
+	}
+
+	if _, err := updater.UpdateIssue("NethServer/dev", 12, false); !errors.Is(err, ghgithub.ErrNotFound) {
+		t.Fatalf("UpdateIssue() error = %v, want ErrNotFound for an issue outside the project", err)
+	}
+}
+
+func TestNewProjectUpdaterRejectsUnknownFields(t *testing.T) {
+	values := []ProjectFieldValue{{Field: "Shipped in", Value: "1.2.0"}}
+	if _, err := NewProjectUpdater(newFakeProjectItemClient(), testProjectUpdates(), values); err == nil {
+		t.Fatal("NewProjectUpdater() returned nil error, want an unknown field error")
+	}
+}
@@ -0,0 +1,221 @@
+package module_release
+
+import (
+	"crypto/ed25519"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"path"
+	"time"
+
+	"github.com/NethServer/gh-ns8/internal/github"
+)
+
+// in-toto and SLSA identifiers of the provenance statement
+const (
+	InTotoPayloadType     = "application/vnd.in-toto+json"
+	InTotoStatementType   = "https://in-toto.io/Statement/v1"
+	SLSAProvenanceType    = "https://slsa.dev/provenance/v1"
+	ProvenanceBuildType   = "https://github.com/NethServer/gh-ns8/module-release/v1"
+	ProvenanceBuilderID   = "https://github.com/NethServer/gh-ns8"
+	provenanceAssetSuffix = ".intoto.jsonl"
+)
+
+// Provenance records how a release was created: its commit, the previous
+// release and the changes found in between, as for the release notes
+type Provenance struct {
+	Repo            string
+	Tag             string
+	Commit          string
+	PreviousRelease string
+	PullRequests    []int
+	// LinkedIssues are the issues of IssuesRepo linked to the pull requests
+	LinkedIssues []int
+	IssuesRepo   string
+	Created      time.Time
+}
+
+// ProvenanceStatement is an in-toto statement with an SLSA provenance
+// predicate
+type ProvenanceStatement struct {
+	Type          string              `json:"_type"`
+	Subject       []ProvenanceSubject `json:"subject"`
+	PredicateType string              `json:"predicateType"`
+	Predicate     struct {
+		BuildDefinition struct {
+			BuildType          string `json:"buildType"`
+			ExternalParameters struct {
+				Repository      string `json:"repository"`
+				Tag             string `json:"tag"`
+				PreviousRelease string `json:"previousRelease,omitempty"`
+			} `json:"externalParameters"`
+			InternalParameters struct {
+				PullRequests []int  `json:"pullRequests"`
+				IssuesRepo   string `json:"issuesRepository"`
+				LinkedIssues []int  `json:"linkedIssues"`
+			} `json:"internalParameters"`
+			ResolvedDependencies []ProvenanceResource `json:"resolvedDependencies"`
+		} `json:"buildDefinition"`
+		RunDetails struct {
+			Builder struct {
+				ID string `json:"id"`
+			} `json:"builder"`
+			Metadata struct {
+				StartedOn string `json:"startedOn"`
+			} `json:"metadata"`
+		} `json:"runDetails"`
+	} `json:"predicate"`
+}
+
+// ProvenanceSubject is an artifact the statement is about
+type ProvenanceSubject struct {
+	Name   string            `json:"name"`
+	Digest map[string]string `json:"digest"`
+}
+
+// ProvenanceResource is an input of the release
+type ProvenanceResource struct {
+	URI    string            `json:"uri"`
+	Digest map[string]string `json:"digest"`
+}
+
+// Envelope is a DSSE envelope signing a payload
+type Envelope struct {
+	PayloadType string              `json:"payloadType"`
+	Payload     string              `json:"payload"`
+	Signatures  []EnvelopeSignature `json:"signatures"`
+}
+
+// EnvelopeSignature is a signature of an envelope, keyid being the
+// fingerprint of the signing key
+type EnvelopeSignature struct {
+	KeyID string `json:"keyid"`
+	Sig   string `json:"sig"`
+}
+
+// ProvenanceSubjectName returns the subject name of the release tag of repo
+func ProvenanceSubjectName(repo, tag string) string {
+	return fmt.Sprintf("%s@%s", repo, tag)
+}
+
+// ProvenanceAssetName returns the name of the provenance asset of a release,
+// e.g. ns8-mail-1.2.0.intoto.jsonl
+func ProvenanceAssetName(repo, tag string) string {
+	return fmt.Sprintf("%s-%s%s", path.Base(repo), tag, provenanceAssetSuffix)
+}
+
+// Statement returns the in-toto statement of the provenance
+func (p *Provenance) Statement() *ProvenanceStatement {
+	statement := &ProvenanceStatement{
+		Type: InTotoStatementType,
+		Subject: []ProvenanceSubject{{
+			Name:   ProvenanceSubjectName(p.Repo, p.Tag),
+			Digest: map[string]string{"gitCommit": p.Commit},
+		}},
+		PredicateType: SLSAProvenanceType,
+	}
+
+	build := &statement.Predicate.BuildDefinition
+	build.BuildType = ProvenanceBuildType
+	build.ExternalParameters.Repository = p.Repo
+	build.ExternalParameters.Tag = p.Tag
+	build.ExternalParameters.PreviousRelease = p.PreviousRelease
+	build.InternalParameters.PullRequests = nonNilInts(p.PullRequests)
+	build.InternalParameters.IssuesRepo = p.IssuesRepo
+	build.InternalParameters.LinkedIssues = nonNilInts(p.LinkedIssues)
+	build.ResolvedDependencies = []ProvenanceResource{{
+		URI:    "git+" + github.WebURL(p.Repo),
+		Digest: map[string]string{"gitCommit": p.Commit},
+	}}
+
+	statement.Predicate.RunDetails.Builder.ID = ProvenanceBuilderID
+	statement.Predicate.RunDetails.Metadata.StartedOn = p.Created.UTC().Format(time.RFC3339)
+	return statement
+}
+
+// nonNilInts renders empty lists as [] rather than null
+func nonNilInts(values []int) []int {
+	if values == nil {
+		return []int{}
+	}
+	return values
+}
+
+// Sign returns the statement in a DSSE envelope signed with key, as a JSON
+// line
+func (p *Provenance) Sign(key ed25519.PrivateKey) ([]byte, error) {
+	payload, err := json.Marshal(p.Statement())
+	if err != nil {
+		return nil, err
+	}
+
+	envelope := Envelope{
+		PayloadType: InTotoPayloadType,
+		Payload:     base64.StdEncoding.EncodeToString(payload),
+		Signatures: []EnvelopeSignature{{
+			KeyID: KeyFingerprint(key.Public().(ed25519.PublicKey)),
+			Sig:   base64.StdEncoding.EncodeToString(ed25519.Sign(key, preAuthEncoding(InTotoPayloadType, payload))),
+		}},
+	}
+	content, err := json.Marshal(envelope)
+	if err != nil {
+		return nil, err
+	}
+	return append(content, '\n'), nil
+}
+
+// VerifyProvenance checks that an envelope is signed by key and returns its
+// statement
+func VerifyProvenance(content []byte, key ed25519.PublicKey) (*ProvenanceStatement, error) {
+	var envelope Envelope
+	if err := json.Unmarshal(content, &envelope); err != nil {
+		return nil, fmt.Errorf("invalid provenance envelope: %w", err)
+	}
+	if envelope.PayloadType != InTotoPayloadType {
+		return nil, fmt.Errorf("unsupported provenance payload type %q", envelope.PayloadType)
+	}
+	payload, err := base64.StdEncoding.DecodeString(envelope.Payload)
+	if err != nil {
+		return nil, fmt.Errorf("invalid provenance payload: %w", err)
+	}
+
+	verified := false
+	message := preAuthEncoding(envelope.PayloadType, payload)
+	for _, signature := range envelope.Signatures {
+		sig, err := base64.StdEncoding.DecodeString(signature.Sig)
+		if err == nil && ed25519.Verify(key, message, sig) {
+			verified = true
+			break
+		}
+	}
+	if !verified {
+		return nil, fmt.Errorf("the provenance is not signed by key %s", KeyFingerprint(key))
+	}
+
+	var statement ProvenanceStatement
+	if err := json.Unmarshal(payload, &statement); err != nil {
+		return nil, fmt.Errorf("invalid provenance statement: %w", err)
+	}
+	if statement.Type != InTotoStatementType || statement.PredicateType != SLSAProvenanceType {
+		return nil, fmt.Errorf("unsupported provenance statement %s with predicate %s", statement.Type, statement.PredicateType)
+	}
+	return &statement, nil
+}
+
+// SubjectCommit returns the commit of the subject name of the statement
+func (s *ProvenanceStatement) SubjectCommit(name string) (string, error) {
+	for _, subject := range s.Subject {
+		if subject.Name == name {
+			if commit := subject.Digest["gitCommit"]; commit != "" {
+				return commit, nil
+			}
+		}
+	}
+	return "", fmt.Errorf("the provenance has no commit for %s", name)
+}
+
+// preAuthEncoding is the DSSE pre-authentication encoding signed by the
+// envelope signatures
+func preAuthEncoding(payloadType string, payload []byte) []byte {
+	return []byte(fmt.Sprintf("DSSEv1 %d %s %d %s", len(payloadType), payloadType, len(payload), payload))
+}
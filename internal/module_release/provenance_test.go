@@ -0,0 +1,112 @@
+package module_release
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"encoding/base64"
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func newTestProvenance() *Provenance {
+	return &Provenance{
+		Repo:            "NethServer/ns8-mail",
+		Tag:             "1.2.0",
+		Commit:          "abc123",
+		PreviousRelease: "1.1.0",
+		PullRequests:    []int{4, 7},
+		LinkedIssues:    []int{1200},
+		IssuesRepo:      "NethServer/dev",
+		Created:         time.Date(2026, 3, 1, 10, 0, 0, 0, time.UTC),
+	}
+}
+
+func TestProvenanceSignAndVerify(t *testing.T) {
+	private, err := ParsePrivateKey([]byte(testOpenSSHPrivateKey))
+	if err != nil {
+		t.Fatalf("ParsePrivateKey() returned error: %v", err)
+	}
+	public := private.Public().(ed25519.PublicKey)
+
+	content, err := newTestProvenance().Sign(private)
+	if err != nil {
+		t.Fatalf("Sign() returned error: %v", err)
+	}
+	if bytes.Count(content, []byte("\n")) != 1 || !bytes.HasSuffix(content, []byte("\n")) {
+		t.Fatalf("Sign() = %q, want a single JSON line", content)
+	}
+
+	var envelope Envelope
+	if err := json.Unmarshal(content, &envelope); err != nil {
+		t.Fatalf("Sign() is not JSON: %v", err)
+	}
+	if envelope.PayloadType != InTotoPayloadType || len(envelope.Signatures) != 1 || envelope.Signatures[0].KeyID != testOpenSSHFingerprint {
+		t.Fatalf("Sign() envelope = %+v, want one signature of %s", envelope, testOpenSSHFingerprint)
+	}
+
+	statement, err := VerifyProvenance(content, public)
+	if err != nil {
+		t.Fatalf("VerifyProvenance() returned error: %v", err)
+	}
+	commit, err := statement.SubjectCommit("NethServer/ns8-mail@1.2.0")
+	if err != nil || commit != "abc123" {
+		t.Fatalf("SubjectCommit() = %q, %v, want abc123", commit, err)
+	}
+	build := statement.Predicate.BuildDefinition
+	if build.ExternalParameters.PreviousRelease != "1.1.0" || !reflect.DeepEqual(build.InternalParameters.PullRequests, []int{4, 7}) || !reflect.DeepEqual(build.InternalParameters.LinkedIssues, []int{1200}) {
+		t.Fatalf("VerifyProvenance() build definition = %+v, want the release changes", build)
+	}
+	if statement.Predicate.RunDetails.Metadata.StartedOn != "2026-03-01T10:00:00Z" {
+		t.Fatalf("VerifyProvenance() startedOn = %q, want the creation time", statement.Predicate.RunDetails.Metadata.StartedOn)
+	}
+}
+
+func TestVerifyProvenanceRejectsTampering(t *testing.T) {
+	private, err := ParsePrivateKey([]byte(testOpenSSHPrivateKey))
+	if err != nil {
+		t.Fatalf("ParsePrivateKey() returned error: %v", err)
+	}
+	content, err := newTestProvenance().Sign(private)
+	if err != nil {
+		t.Fatalf("Sign() returned error: %v", err)
+	}
+
+	t.Run("other key", func(t *testing.T) {
+		other, _, _ := ed25519.GenerateKey(nil)
+		if _, err := VerifyProvenance(content, other); err == nil || !strings.Contains(err.Error(), "is not signed by key") {
+			t.Fatalf("VerifyProvenance() error = %v, want signature error", err)
+		}
+	})
+
+	t.Run("changed payload", func(t *testing.T) {
+		var envelope Envelope
+		json.Unmarshal(content, &envelope)
+		payload, _ := base64.StdEncoding.DecodeString(envelope.Payload)
+		envelope.Payload = base64.StdEncoding.EncodeToString(bytes.Replace(payload, []byte("abc123"), []byte("def456"), -1))
+		tampered, _ := json.Marshal(envelope)
+
+		if _, err := VerifyProvenance(tampered, private.Public().(ed25519.PublicKey)); err == nil || !strings.Contains(err.Error(), "is not signed by key") {
+			t.Fatalf("VerifyProvenance() error = %v, want signature error", err)
+		}
+	})
+}
+
+func TestProvenanceStatementListsNoChangesAsEmpty(t *testing.T) {
+	provenance := newTestProvenance()
+	provenance.PullRequests = nil
+	provenance.LinkedIssues = nil
+
+	payload, err := json.Marshal(provenance.Statement())
+	if err != nil {
+		t.Fatalf("json.Marshal() returned error: %v", err)
+	}
+	if !bytes.Contains(payload, []byte(`"pullRequests":[]`)) || !bytes.Contains(payload, []byte(`"linkedIssues":[]`)) {
+		t.Fatalf("Statement() = %s, want empty change lists", payload)
+	}
+	if got := ProvenanceAssetName(provenance.Repo, provenance.Tag); got != "ns8-mail-1.2.0.intoto.jsonl" {
+		t.Fatalf("ProvenanceAssetName() = %q, want %q", got, "ns8-mail-1.2.0.intoto.jsonl")
+	}
+}
@@ -0,0 +1,84 @@
+package module_release
+
+import (
+	"slices"
+
+	"github.com/NethServer/gh-ns8/internal/config"
+	"github.com/NethServer/gh-ns8/internal/github"
+)
+
+// progressValues maps the progress emojis to the configured progress values
+var progressValues = map[string]string{
+	EmojiInProgress: config.ProgressInProgress,
+	EmojiTesting:    config.ProgressTesting,
+	EmojiVerified:   config.ProgressVerified,
+}
+
+type readinessRule struct {
+	labels   []string
+	progress string
+	state    string
+	approved bool
+}
+
+// matches tells if info satisfies all the rule conditions; prs are the PRs
+// whose reviews are checked
+func (r readinessRule) matches(info *IssueInfo, prs []PRInfo) bool {
+	if len(r.labels) > 0 && !slices.ContainsFunc(info.labelNames, func(label string) bool {
+		return slices.Contains(r.labels, label)
+	}) {
+		return false
+	}
+	if r.progress != "" && r.progress != progressValues[info.Progress] {
+		return false
+	}
+	if r.state != "" && r.state != info.State {
+		return false
+	}
+	if r.approved {
+		if len(prs) == 0 {
+			return false
+		}
+		for _, pr := range prs {
+			if pr.ReviewDecision != github.ReviewApproved {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// ReadinessPolicy decides which issues are accepted for release
+type ReadinessPolicy struct {
+	rules       []readinessRule
+	parentsSelf bool
+}
+
+// NewReadinessPolicy builds the policy of the readiness configuration, which
+// is expected to be valid
+func NewReadinessPolicy(readiness config.Readiness) *ReadinessPolicy {
+	policy := &ReadinessPolicy{parentsSelf: readiness.Parents == config.ParentsSelf}
+	for _, rule := range readiness.Accept {
+		policy.rules = append(policy.rules, readinessRule{
+			labels:   rule.Labels,
+			progress: rule.Progress,
+			state:    rule.State,
+			approved: rule.Review == config.ReviewApproved,
+		})
+	}
+	return policy
+}
+
+// NeedsReviews tells if the policy checks the review decision of PRs
+func (p *ReadinessPolicy) NeedsReviews() bool {
+	return slices.ContainsFunc(p.rules, func(rule readinessRule) bool {
+		return rule.approved
+	})
+}
+
+// accepts tells if any rule matches info
+func (p *ReadinessPolicy) accepts(info *IssueInfo, prs []PRInfo) bool {
+	return slices.ContainsFunc(p.rules, func(rule readinessRule) bool {
+		return rule.matches(info, prs)
+	})
+}
@@ -0,0 +1,141 @@
+package module_release
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/NethServer/gh-ns8/internal/config"
+	ghgithub "github.com/NethServer/gh-ns8/internal/github"
+)
+
+func TestReadinessPolicyAccepts(t *testing.T) {
+	policy := NewReadinessPolicy(config.Readiness{
+		Accept: []config.ReadinessRule{
+			{Progress: config.ProgressVerified},
+			{Labels: []string{"no-qa", "docs"}, State: config.StateClosed},
+			{State: config.StateClosed, Review: config.ReviewApproved},
+		},
+		Parents: config.ParentsChildren,
+	})
+	approved := []PRInfo{{Number: 1, ReviewDecision: ghgithub.ReviewApproved}}
+	pending := []PRInfo{{Number: 1, ReviewDecision: ghgithub.ReviewApproved}, {Number: 2, ReviewDecision: ghgithub.ReviewRequired}}
+
+	tests := []struct {
+		name string
+		info *IssueInfo
+		prs  []PRInfo
+		want bool
+	}{
+		{name: "verified", info: &IssueInfo{Progress: EmojiVerified, State: config.StateOpen}, want: true},
+		{name: "testing", info: &IssueInfo{Progress: EmojiTesting, State: config.StateOpen}, want: false},
+		{name: "closed with label", info: &IssueInfo{Progress: EmojiInProgress, State: config.StateClosed, labelNames: []string{"docs"}}, want: true},
+		{name: "open with label", info: &IssueInfo{Progress: EmojiInProgress, State: config.StateOpen, labelNames: []string{"docs"}}, want: false},
+		{name: "closed and approved", info: &IssueInfo{Progress: EmojiTesting, State: config.StateClosed}, prs: approved, want: true},
+		{name: "closed with pending review", info: &IssueInfo{Progress: EmojiTesting, State: config.StateClosed}, prs: pending, want: false},
+		{name: "closed without PRs", info: &IssueInfo{Progress: EmojiTesting, State: config.StateClosed}, want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := policy.accepts(tt.info, tt.prs); got != tt.want {
+				t.Fatalf("accepts() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	if !policy.NeedsReviews() {
+		t.Fatal("NeedsReviews() = false, want true with a review rule")
+	}
+	if NewReadinessPolicy(config.Default().Readiness).NeedsReviews() {
+		t.Fatal("NeedsReviews() = true, want false for the default policy")
+	}
+}
+
+func TestProcessIssueRecordsPolicyFacts(t *testing.T) {
+	issue := &ghgithub.Issue{Title: "Closed issue", State: "CLOSED"}
+	issue.Labels = append(issue.Labels, struct {
+		Name string `json:"name"`
+	}{Name: "verified"}, struct {
+		Name string `json:"name"`
+	}{Name: "docs"})
+
+	summary := NewCheckSummary("NethServer/dev")
+	if err := summary.ProcessIssue(stubIssueProvider{issues: map[int]*ghgithub.Issue{7: issue}}, 7); err != nil {
+		t.Fatalf("ProcessIssue() returned error: %v", err)
+	}
+
+	info := summary.Issues[7]
+	if info.State != config.StateClosed {
+		t.Fatalf("State = %q, want %q", info.State, config.StateClosed)
+	}
+	if strings.Join(info.labelNames, ",") != "verified,docs" {
+		t.Fatalf("labelNames = %v, want all the issue labels", info.labelNames)
+	}
+}
+
+func TestDisplayGroupsClosedIssuesWithCustomPolicy(t *testing.T) {
+	summary := NewCheckSummary("NethServer/dev")
+	summary.Policy = NewReadinessPolicy(config.Readiness{
+		Accept:  []config.ReadinessRule{{State: config.StateClosed}},
+		Parents: config.ParentsChildren,
+	})
+	summary.Issues[1] = &IssueInfo{
+		Number:   1,
+		Title:    "Closed issue",
+		Status:   EmojiClosedIssue,
+		State:    config.StateClosed,
+		Progress: EmojiInProgress,
+		LinkedPRs: []PRInfo{
+			{Number: 11, Status: EmojiMergedPR, URL: "https://github.com/NethServer/ns8-test/pull/11"},
+		},
+	}
+	summary.issueOrder = []int{1}
+
+	output := captureStdout(t, summary.Display)
+	if !strings.Contains(output, "Ready to release:") || strings.Contains(output, "Release blockers:") {
+		t.Fatalf("closed issue should be ready to release:\n%s", output)
+	}
+	if !strings.Contains(output, "All checks passed! Ready to release.") {
+		t.Fatalf("missing release readiness in output:\n%s", output)
+	}
+}
+
+func TestDisplayGroupsParentsBySelfPolicy(t *testing.T) {
+	summary := NewCheckSummary("NethServer/dev")
+	summary.Policy = NewReadinessPolicy(config.Readiness{
+		Accept:  []config.ReadinessRule{{Progress: config.ProgressVerified}},
+		Parents: config.ParentsSelf,
+	})
+	summary.Issues[100] = &IssueInfo{
+		Number:   100,
+		Title:    "Unverified parent",
+		Status:   EmojiOpenIssue,
+		State:    config.StateOpen,
+		Progress: EmojiInProgress,
+		Children: []int{101},
+	}
+	summary.Issues[101] = &IssueInfo{
+		Number:       101,
+		Title:        "Verified child",
+		Status:       EmojiOpenIssue,
+		State:        config.StateOpen,
+		Progress:     EmojiVerified,
+		ParentNumber: 100,
+		LinkedPRs: []PRInfo{
+			{Number: 20, Status: EmojiMergedPR, URL: "https://github.com/NethServer/ns8-test/pull/20"},
+		},
+	}
+	summary.issueOrder = []int{100}
+
+	output := captureStdout(t, summary.Display)
+	blockerIndex := strings.Index(output, "Release blockers:")
+	parentIndex := strings.Index(output, titleLink(100, "Unverified parent", "https://github.com/NethServer/dev/issues/100"))
+	childIndex := strings.Index(output, titleLink(101, "Verified child", "https://github.com/NethServer/dev/issues/101"))
+
+	if strings.Contains(output, "Ready to release:") {
+		t.Fatalf("the unverified parent should not be ready to release:\n%s", output)
+	}
+	if blockerIndex == -1 || !(blockerIndex < parentIndex && parentIndex < childIndex) {
+		t.Fatalf("parent and child are not shown in the blocker group:\n%s", output)
+	}
+}
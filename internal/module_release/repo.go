@@ -1,6 +1,7 @@
 package module_release
 
 import (
+	"errors"
 	"fmt"
 	"regexp"
 	"sort"
@@ -9,6 +10,9 @@ import (
 	"github.com/NethServer/gh-ns8/internal/github"
 )
 
+// ErrNoReleases is returned when a repository has no matching release
+var ErrNoReleases = errors.New("no releases found")
+
 var ns8ModulePattern = regexp.MustCompile(`^[^/]+/ns8-`)
 
 var getCurrentRepository = github.GetCurrentRepository
@@ -17,16 +21,31 @@ type repositoryClient interface {
 	GetRepository(repo string) (*github.Repository, error)
 }
 
+// DefaultBranch is the branch releases are made from unless --branch is set
+const DefaultBranch = "main"
+
+// branchReleaseScanLimit bounds how many releases are inspected when looking
+// for the latest release of a branch
+const branchReleaseScanLimit = 100
+
+type ancestryClient interface {
+	GetCompareStatus(repo, base, head string) (string, error)
+}
+
 type commitClient interface {
-	GetLatestCommit(repo string) (string, error)
-	GetRepository(repo string) (*github.Repository, error)
-	GetMergeBase(repo, base, head string) (string, error)
+	GetLatestCommit(repo, branch string) (string, error)
+	ancestryClient
 }
 
 type releaseClient interface {
 	ListReleases(repo string, limit int, excludePreReleases bool) ([]github.Release, error)
 }
 
+type branchReleaseClient interface {
+	releaseClient
+	ancestryClient
+}
+
 type refClient interface {
 	GetCommitSHA(repo, ref string) (string, error)
 }
@@ -58,7 +77,7 @@ func GetOrValidateRepo(client repositoryClient, repo string) (string, error) {
 	if repo == "" {
 		currentRepo, err := getCurrentRepository()
 		if err != nil {
-			return "", fmt.Errorf("could not determine the repo. Please provide the repo name using the --repo flag")
+			return "", fmt.Errorf("could not determine the repo, please provide it with the --repo flag: %w", err)
 		}
 		repo = currentRepo
 	}
@@ -74,37 +93,34 @@ func GetOrValidateRepo(client repositoryClient, repo string) (string, error) {
 // CommitInfo holds commit SHA and target flag
 type CommitInfo struct {
 	SHA    string
-	Target string // Target flag for release (e.g., "commit-sha" or empty)
+	Target string // Target commit of the release, always SHA
 }
 
-// GetOrValidateCommit returns the latest commit or validates the provided one
-func GetOrValidateCommit(client commitClient, repo, commitSHA string) (*CommitInfo, error) {
+// GetOrValidateCommit returns the latest commit of the branch or validates
+// that the provided one is on it
+func GetOrValidateCommit(client commitClient, repo, branch, commitSHA string) (*CommitInfo, error) {
 	info := &CommitInfo{}
 
 	// If no commit SHA provided, get the latest
 	if commitSHA == "" {
-		sha, err := client.GetLatestCommit(repo)
+		sha, err := client.GetLatestCommit(repo, branch)
 		if err != nil {
-			return nil, fmt.Errorf("could not determine the latest commit sha. Please provide the commit sha using the --release-refs flag")
+			return nil, fmt.Errorf("could not determine the latest commit of branch %s, please provide it with the --release-refs flag: %w", branch, err)
 		}
+		// The tag is created on the resolved commit, not on whatever the
+		// branch head is by then
 		info.SHA = sha
+		info.Target = sha
 		return info, nil
 	}
 
-	// Validate that commit is on the default branch
-	repoInfo, err := client.GetRepository(repo)
-	if err != nil {
-		return nil, fmt.Errorf("failed to get repository info: %w", err)
-	}
-
-	defaultBranch := repoInfo.DefaultBranchRef.Name
-	mergeBase, err := client.GetMergeBase(repo, defaultBranch, commitSHA)
+	onBranch, err := IsOnBranch(client, repo, commitSHA, branch)
 	if err != nil {
-		return nil, fmt.Errorf("failed to check if commit is on default branch: %w", err)
+		return nil, fmt.Errorf("failed to check if commit is on branch %s: %w", branch, err)
 	}
 
-	if mergeBase != commitSHA {
-		return nil, fmt.Errorf("the commit sha is not on the default branch: %s", defaultBranch)
+	if !onBranch {
+		return nil, fmt.Errorf("the commit sha is not on the branch: %s", branch)
 	}
 
 	info.SHA = commitSHA
@@ -113,6 +129,54 @@ func GetOrValidateCommit(client commitClient, repo, commitSHA string) (*CommitIn
 	return info, nil
 }
 
+// IsOnBranch reports whether ref, a tag or commit, is reachable from branch
+func IsOnBranch(client ancestryClient, repo, ref, branch string) (bool, error) {
+	status, err := client.GetCompareStatus(repo, branch, ref)
+	if err != nil {
+		return false, err
+	}
+	return status == "behind" || status == "identical", nil
+}
+
+// GetLatestBranchRelease gets the latest release reachable from branch, so
+// releases of other maintenance branches are ignored
+func GetLatestBranchRelease(client branchReleaseClient, repo, branch string, excludePreReleases bool) (*github.Release, error) {
+	releases, err := ListBranchReleases(client, repo, branch, 1, excludePreReleases)
+	if err != nil {
+		return nil, err
+	}
+	if len(releases) == 0 {
+		return nil, ErrNoReleases
+	}
+	return &releases[0], nil
+}
+
+// ListBranchReleases lists up to limit releases reachable from branch, newest
+// first, among the latest releases of the repository. A limit of 0 lists
+// them all.
+func ListBranchReleases(client branchReleaseClient, repo, branch string, limit int, excludePreReleases bool) ([]github.Release, error) {
+	releases, err := client.ListReleases(repo, branchReleaseScanLimit, excludePreReleases)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list releases: %w", err)
+	}
+
+	var branchReleases []github.Release
+	for _, release := range releases {
+		onBranch, err := IsOnBranch(client, repo, release.TagName, branch)
+		if err != nil {
+			return nil, fmt.Errorf("failed to check if release %s is on branch %s: %w", release.TagName, branch, err)
+		}
+		if !onBranch {
+			continue
+		}
+		branchReleases = append(branchReleases, release)
+		if limit > 0 && len(branchReleases) == limit {
+			break
+		}
+	}
+	return branchReleases, nil
+}
+
 // GetLatestRelease gets the latest release (optionally excluding pre-releases)
 func GetLatestRelease(client releaseClient, repo string, excludePreReleases bool) (*github.Release, error) {
 	releases, err := client.ListReleases(repo, 1, excludePreReleases)
@@ -121,7 +185,7 @@ func GetLatestRelease(client releaseClient, repo string, excludePreReleases bool
 	}
 
 	if len(releases) == 0 {
-		return nil, fmt.Errorf("no releases found")
+		return nil, ErrNoReleases
 	}
 
 	return &releases[0], nil
@@ -136,16 +200,17 @@ func GetReleaseCommitSHA(client refClient, repo, tag string) (string, error) {
 	return sha, nil
 }
 
-// GetMainBranchSHA gets the current SHA of the main branch
-func GetMainBranchSHA(client refClient, repo string) (string, error) {
-	sha, err := client.GetCommitSHA(repo, "heads/main")
+// GetBranchSHA gets the current SHA of a branch
+func GetBranchSHA(client refClient, repo, branch string) (string, error) {
+	sha, err := client.GetCommitSHA(repo, "heads/"+branch)
 	if err != nil {
-		return "", fmt.Errorf("failed to get main branch SHA: %w", err)
+		return "", fmt.Errorf("failed to get %s branch SHA: %w", branch, err)
 	}
 	return sha, nil
 }
 
-// ScanForPRs scans commits between two refs and returns unique PR numbers
+// ScanForPRs scans commits between two refs and returns unique PR numbers.
+// Commits whose PRs cannot be listed are skipped.
 func ScanForPRs(client compareClient, repo, startRef, endRef string) ([]int, error) {
 	// Compare commits
 	comparison, err := client.CompareCommits(repo, startRef, endRef)
@@ -157,11 +222,35 @@ func ScanForPRs(client compareClient, repo, startRef, endRef string) ([]int, err
 		return nil, fmt.Errorf("no commits found in the specified range")
 	}
 
-	// Collect unique PR numbers
+	prNumbers, _ := commitPRs(client, repo, comparison, false)
+	if len(prNumbers) == 0 {
+		return nil, fmt.Errorf("no pull requests found for the commits in the specified range")
+	}
+
+	return prNumbers, nil
+}
+
+// ScanForAllPRs is ScanForPRs for callers that must not miss a PR, such as
+// a signed provenance: a commit whose PRs cannot be listed fails the scan,
+// while a range without commits or PRs has no PRs.
+func ScanForAllPRs(client compareClient, repo, startRef, endRef string) ([]int, error) {
+	comparison, err := client.CompareCommits(repo, startRef, endRef)
+	if err != nil {
+		return nil, fmt.Errorf("failed to compare commits: %w", err)
+	}
+	return commitPRs(client, repo, comparison, true)
+}
+
+// commitPRs returns the sorted unique PRs of the commits of a comparison.
+// Unless strict, commits whose PRs cannot be listed are skipped.
+func commitPRs(client compareClient, repo string, comparison *github.CompareResult, strict bool) ([]int, error) {
 	prMap := make(map[int]bool)
 	for _, commit := range comparison.Commits {
 		prs, err := client.GetPullRequestsForCommit(repo, commit.SHA)
 		if err != nil {
+			if strict {
+				return nil, fmt.Errorf("failed to get the pull requests of commit %s: %w", commit.SHA, err)
+			}
 			continue // Skip commits that fail
 		}
 		for _, prNum := range prs {
@@ -169,10 +258,6 @@ func ScanForPRs(client compareClient, repo, startRef, endRef string) ([]int, err
 		}
 	}
 
-	if len(prMap) == 0 {
-		return nil, fmt.Errorf("no pull requests found for the commits in the specified range")
-	}
-
 	// Convert to slice
 	prNumbers := make([]int, 0, len(prMap))
 	for prNum := range prMap {
@@ -192,10 +277,11 @@ func GetLinkedIssues(prBody, issuesRepo string) []int {
 	owner, repo := parts[0], parts[1]
 
 	pattern := fmt.Sprintf(
-		`(?i)(?:%s/issues/(\d+))|(?:%s/%s#(\d+))|(?:https://github\.com/%s/%s/issues/(\d+))`,
+		`(?i)(?:%s/issues/(\d+))|(?:%s/%s#(\d+))|(?:https://%s/%s/%s/issues/(\d+))`,
 		regexp.QuoteMeta(owner),
 		regexp.QuoteMeta(owner),
 		regexp.QuoteMeta(repo),
+		regexp.QuoteMeta(github.Host()),
 		regexp.QuoteMeta(owner),
 		regexp.QuoteMeta(repo),
 	)
@@ -2,6 +2,7 @@ package module_release
 
 import (
 	"errors"
+	"fmt"
 	"reflect"
 	"strings"
 	"testing"
@@ -36,13 +37,27 @@ func TestGetLinkedIssuesIgnoresRepositoryCase(t *testing.T) {
 	}
 }
 
+func TestGetLinkedIssuesMatchesEnterpriseHostURLs(t *testing.T) {
+	t.Setenv("GH_HOST", "git.example.com")
+
+	got := GetLinkedIssues(
+		"Fixes https://git.example.com/NethServer/dev/issues/12, not https://github.com/NethServer/dev/issues/13",
+		"NethServer/dev",
+	)
+	want := []int{12}
+
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("GetLinkedIssues() = %v, want %v", got, want)
+	}
+}
+
 type fakeRepoClient struct {
 	repositories    map[string]*ghgithub.Repository
 	repositoryErrs  map[string]error
 	latestCommit    string
 	latestCommitErr error
-	mergeBases      map[string]string
-	mergeBaseErrs   map[string]error
+	latestBranches  *[]string
+	compareStatuses map[string]string
 	releases        []ghgithub.Release
 	listReleasesErr error
 	refs            map[string]string
@@ -63,7 +78,10 @@ func (f fakeRepoClient) GetRepository(repo string) (*ghgithub.Repository, error)
 	return nil, errors.New("repository not found")
 }
 
-func (f fakeRepoClient) GetLatestCommit(repo string) (string, error) {
+func (f fakeRepoClient) GetLatestCommit(repo, branch string) (string, error) {
+	if f.latestBranches != nil {
+		*f.latestBranches = append(*f.latestBranches, branch)
+	}
 	if f.latestCommitErr != nil {
 		return "", f.latestCommitErr
 	}
@@ -73,15 +91,12 @@ func (f fakeRepoClient) GetLatestCommit(repo string) (string, error) {
 	return "", errors.New("latest commit not found")
 }
 
-func (f fakeRepoClient) GetMergeBase(repo, base, head string) (string, error) {
-	key := repo + "|" + base + "|" + head
-	if err, ok := f.mergeBaseErrs[key]; ok {
-		return "", err
-	}
-	if mergeBase, ok := f.mergeBases[key]; ok {
-		return mergeBase, nil
+// GetCompareStatus reports every ref as reachable unless a status is set
+func (f fakeRepoClient) GetCompareStatus(repo, base, head string) (string, error) {
+	if status, ok := f.compareStatuses[repo+"|"+base+"|"+head]; ok {
+		return status, nil
 	}
-	return "", errors.New("merge base not found")
+	return "behind", nil
 }
 
 func (f fakeRepoClient) ListReleases(_ string, limit int, excludePreReleases bool) ([]ghgithub.Release, error) {
@@ -135,7 +150,7 @@ func (f fakeRepoClient) GetPullRequestsForCommit(repo, sha string) ([]int, error
 
 func makeRepository(defaultBranch string) *ghgithub.Repository {
 	repository := &ghgithub.Repository{}
-	repository.DefaultBranchRef.Name = defaultBranch
+	repository.DefaultBranch = defaultBranch
 	return repository
 }
 
@@ -207,36 +222,118 @@ func TestGetOrValidateRepoReturnsFriendlyErrorWhenCurrentRepoFails(t *testing.T)
 	}()
 
 	_, err := GetOrValidateRepo(fakeRepoClient{}, "")
-	if err == nil || !strings.Contains(err.Error(), "could not determine the repo") {
-		t.Fatalf("GetOrValidateRepo() error = %v, want repo detection error", err)
+	if err == nil || !strings.Contains(err.Error(), "could not determine the repo") || !strings.Contains(err.Error(), "boom") {
+		t.Fatalf("GetOrValidateRepo() error = %v, want repo detection error with its cause", err)
+	}
+}
+
+func TestGetOrValidateCommitUsesLatestBranchCommitWhenReleaseRefMissing(t *testing.T) {
+	var branches []string
+	client := fakeRepoClient{latestCommit: "abc123", latestBranches: &branches}
+
+	info, err := GetOrValidateCommit(client, "NethServer/ns8-mail", "stable-1.4", "")
+	if err != nil {
+		t.Fatalf("GetOrValidateCommit() returned error: %v", err)
+	}
+	if info.SHA != "abc123" || info.Target != "abc123" {
+		t.Fatalf("GetOrValidateCommit() = %+v, want SHA and target abc123", info)
+	}
+	if !reflect.DeepEqual(branches, []string{"stable-1.4"}) {
+		t.Fatalf("GetLatestCommit() branches = %v, want [stable-1.4]", branches)
 	}
 }
 
-func TestGetOrValidateCommitUsesLatestCommitWhenReleaseRefMissing(t *testing.T) {
-	client := fakeRepoClient{latestCommit: "abc123"}
+func TestGetOrValidateCommitKeepsLatestCommitError(t *testing.T) {
+	client := fakeRepoClient{latestCommitErr: fmt.Errorf("failed to get branch: %w", ghgithub.ErrForbidden)}
 
-	info, err := GetOrValidateCommit(client, "NethServer/ns8-mail", "")
+	_, err := GetOrValidateCommit(client, "NethServer/ns8-mail", "main", "")
+	if !errors.Is(err, ghgithub.ErrForbidden) || !strings.Contains(err.Error(), "--release-refs") {
+		t.Fatalf("GetOrValidateCommit() error = %v, want ErrForbidden with the --release-refs hint", err)
+	}
+}
+
+func TestGetOrValidateCommitAcceptsCommitOnBranch(t *testing.T) {
+	info, err := GetOrValidateCommit(fakeRepoClient{}, "NethServer/ns8-mail", "main", "deadbeef")
 	if err != nil {
 		t.Fatalf("GetOrValidateCommit() returned error: %v", err)
 	}
-	if info.SHA != "abc123" || info.Target != "" {
-		t.Fatalf("GetOrValidateCommit() = %+v, want SHA abc123 and empty target", info)
+	if info.SHA != "deadbeef" || info.Target != "deadbeef" {
+		t.Fatalf("GetOrValidateCommit() = %+v, want SHA and target deadbeef", info)
 	}
 }
 
-func TestGetOrValidateCommitRejectsCommitOutsideDefaultBranch(t *testing.T) {
+func TestGetOrValidateCommitRejectsCommitOutsideBranch(t *testing.T) {
 	client := fakeRepoClient{
-		repositories: map[string]*ghgithub.Repository{
-			"NethServer/ns8-mail": makeRepository("main"),
+		compareStatuses: map[string]string{
+			"NethServer/ns8-mail|stable-1.4|deadbeef": "diverged",
+		},
+	}
+
+	_, err := GetOrValidateCommit(client, "NethServer/ns8-mail", "stable-1.4", "deadbeef")
+	if err == nil || !strings.Contains(err.Error(), "the commit sha is not on the branch: stable-1.4") {
+		t.Fatalf("GetOrValidateCommit() error = %v, want branch error", err)
+	}
+}
+
+func TestGetLatestBranchReleaseSkipsReleasesOfOtherBranches(t *testing.T) {
+	client := fakeRepoClient{
+		releases: []ghgithub.Release{
+			{TagName: "1.5.0"},
+			{TagName: "1.4.2"},
+			{TagName: "1.4.1"},
+		},
+		compareStatuses: map[string]string{
+			"NethServer/ns8-mail|stable-1.4|1.5.0": "diverged",
 		},
-		mergeBases: map[string]string{
-			"NethServer/ns8-mail|main|deadbeef": "cafebabe",
+	}
+
+	release, err := GetLatestBranchRelease(client, "NethServer/ns8-mail", "stable-1.4", true)
+	if err != nil {
+		t.Fatalf("GetLatestBranchRelease() returned error: %v", err)
+	}
+	if release.TagName != "1.4.2" {
+		t.Fatalf("GetLatestBranchRelease() tag = %q, want %q", release.TagName, "1.4.2")
+	}
+}
+
+func TestListBranchReleasesSkipsReleasesOfOtherBranches(t *testing.T) {
+	client := fakeRepoClient{
+		releases: []ghgithub.Release{
+			{TagName: "1.5.1"},
+			{TagName: "1.4.2"},
+			{TagName: "1.5.0"},
+			{TagName: "1.4.1"},
+		},
+		compareStatuses: map[string]string{
+			"NethServer/ns8-mail|stable-1.4|1.5.1": "diverged",
+			"NethServer/ns8-mail|stable-1.4|1.5.0": "diverged",
+		},
+	}
+
+	releases, err := ListBranchReleases(client, "NethServer/ns8-mail", "stable-1.4", 0, true)
+	if err != nil {
+		t.Fatalf("ListBranchReleases() returned error: %v", err)
+	}
+	var tags []string
+	for _, release := range releases {
+		tags = append(tags, release.TagName)
+	}
+	if want := []string{"1.4.2", "1.4.1"}; !reflect.DeepEqual(tags, want) {
+		t.Fatalf("ListBranchReleases() = %v, want %v", tags, want)
+	}
+}
+
+func TestGetLatestBranchReleaseReturnsErrNoReleases(t *testing.T) {
+	client := fakeRepoClient{
+		releases: []ghgithub.Release{{TagName: "1.5.0"}},
+		compareStatuses: map[string]string{
+			"NethServer/ns8-mail|stable-1.4|1.5.0": "ahead",
 		},
 	}
 
-	_, err := GetOrValidateCommit(client, "NethServer/ns8-mail", "deadbeef")
-	if err == nil || !strings.Contains(err.Error(), "the commit sha is not on the default branch: main") {
-		t.Fatalf("GetOrValidateCommit() error = %v, want default branch error", err)
+	_, err := GetLatestBranchRelease(client, "NethServer/ns8-mail", "stable-1.4", false)
+	if !errors.Is(err, ErrNoReleases) {
+		t.Fatalf("GetLatestBranchRelease() error = %v, want ErrNoReleases", err)
 	}
 }
 
@@ -280,19 +377,19 @@ func TestGetReleaseCommitSHAFormatsTagRef(t *testing.T) {
 	}
 }
 
-func TestGetMainBranchSHAUsesHeadsMainRef(t *testing.T) {
+func TestGetBranchSHAUsesHeadsRef(t *testing.T) {
 	client := fakeRepoClient{
 		refs: map[string]string{
-			"NethServer/ns8-mail|heads/main": "mainsha",
+			"NethServer/ns8-mail|heads/stable-1.4": "stablesha",
 		},
 	}
 
-	sha, err := GetMainBranchSHA(client, "NethServer/ns8-mail")
+	sha, err := GetBranchSHA(client, "NethServer/ns8-mail", "stable-1.4")
 	if err != nil {
-		t.Fatalf("GetMainBranchSHA() returned error: %v", err)
+		t.Fatalf("GetBranchSHA() returned error: %v", err)
 	}
-	if sha != "mainsha" {
-		t.Fatalf("GetMainBranchSHA() = %q, want %q", sha, "mainsha")
+	if sha != "stablesha" {
+		t.Fatalf("GetBranchSHA() = %q, want %q", sha, "stablesha")
 	}
 }
 
@@ -321,6 +418,32 @@ func TestScanForPRsDeduplicatesSortsAndSkipsCommitLookupFailures(t *testing.T) {
 	}
 }
 
+func TestScanForAllPRsFailsOnCommitLookupFailures(t *testing.T) {
+	client := fakeRepoClient{
+		comparisons: map[string]*ghgithub.CompareResult{
+			"NethServer/ns8-mail|v1.2.0|main": makeCompareResult("a", "b", "c"),
+			"NethServer/ns8-mail|v1.2.0|a":    makeCompareResult("a"),
+		},
+		commitPRs: map[string][]int{
+			"NethServer/ns8-mail|b": {3, 7},
+		},
+		pullRequestErrs: map[string]error{
+			"NethServer/ns8-mail|c": ghgithub.ErrRateLimited,
+		},
+	}
+
+	_, err := ScanForAllPRs(client, "NethServer/ns8-mail", "v1.2.0", "main")
+	if !errors.Is(err, ghgithub.ErrRateLimited) || !strings.Contains(err.Error(), "commit c") {
+		t.Fatalf("ScanForAllPRs() error = %v, want the lookup error of commit c", err)
+	}
+
+	// A range without PRs is not an error
+	got, err := ScanForAllPRs(client, "NethServer/ns8-mail", "v1.2.0", "a")
+	if err != nil || len(got) != 0 {
+		t.Fatalf("ScanForAllPRs() = %v, %v, want no PRs", got, err)
+	}
+}
+
 func TestScanForPRsReturnsErrorWhenCommitRangeIsEmpty(t *testing.T) {
 	client := fakeRepoClient{
 		comparisons: map[string]*ghgithub.CompareResult{
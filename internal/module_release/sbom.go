@@ -0,0 +1,406 @@
+package module_release
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/NethServer/gh-ns8/internal/github"
+)
+
+// SBOM document formats
+const (
+	SBOMSPDX      = "spdx"
+	SBOMCycloneDX = "cyclonedx"
+)
+
+// Types of SBOM components
+const (
+	ComponentLibrary   = "library"
+	ComponentContainer = "container"
+)
+
+// SBOMComponent is a dependency of a module found in its repository
+type SBOMComponent struct {
+	Type    string
+	Name    string
+	Version string
+	// PURL is the package URL identifying the component
+	PURL string
+	// Source is the file declaring the component
+	Source string
+}
+
+// SBOM lists the components of a module release
+type SBOM struct {
+	Repo    string
+	Version string
+	Commit  string
+	Created time.Time
+	// Components are sorted by type, name and version
+	Components []SBOMComponent
+}
+
+type sbomClient interface {
+	ListFiles(repo, ref string) ([]string, error)
+	GetFile(repo, path, ref string) (*github.File, error)
+}
+
+var (
+	// imageLabelRegex matches the images label of a module, e.g.
+	// --label="org.nethserver.images=docker.io/library/mariadb:10.11.5 ..."
+	imageLabelRegex = regexp.MustCompile(`org\.nethserver\.images=["']?([^"'\n]*)`)
+	fromRegex       = regexp.MustCompile(`(?i)^\s*FROM\s+(?:--\S+\s+)*(\S+)(?:\s+AS\s+(\S+))?`)
+)
+
+// ValidateSBOMFormat checks that format is spdx or cyclonedx
+func ValidateSBOMFormat(format string) error {
+	switch format {
+	case SBOMSPDX, SBOMCycloneDX:
+		return nil
+	}
+	return fmt.Errorf("invalid SBOM format %q: must be %s or %s", format, SBOMSPDX, SBOMCycloneDX)
+}
+
+// NewSBOM collects the components of the repository tree at commit: the Go
+// modules required by go.mod files, the npm packages of package.json files
+// and the container images of Containerfiles, Dockerfiles and of the
+// org.nethserver.images label of build scripts. Vendored and installed
+// dependencies are ignored.
+func NewSBOM(client sbomClient, repo, version, commit string, created time.Time) (*SBOM, error) {
+	paths, err := client.ListFiles(repo, commit)
+	if err != nil {
+		return nil, err
+	}
+
+	seen := map[string]bool{}
+	var components []SBOMComponent
+	for _, filePath := range paths {
+		parse := sbomParser(filePath)
+		if parse == nil {
+			continue
+		}
+
+		file, err := client.GetFile(repo, filePath, commit)
+		if err != nil {
+			return nil, err
+		}
+		found, err := parse(filePath, file.Content)
+		if err != nil {
+			return nil, err
+		}
+		for _, component := range found {
+			key := component.Type + " " + component.Name + " " + component.Version
+			if !seen[key] {
+				seen[key] = true
+				components = append(components, component)
+			}
+		}
+	}
+
+	sort.Slice(components, func(i, j int) bool {
+		a, b := components[i], components[j]
+		if a.Type != b.Type {
+			return a.Type < b.Type
+		}
+		if a.Name != b.Name {
+			return a.Name < b.Name
+		}
+		return a.Version < b.Version
+	})
+
+	return &SBOM{Repo: repo, Version: version, Commit: commit, Created: created.UTC(), Components: components}, nil
+}
+
+// sbomParser returns the parser of the components declared by a file, nil
+// for the other files
+func sbomParser(filePath string) func(string, []byte) ([]SBOMComponent, error) {
+	for _, dir := range strings.Split(path.Dir(filePath), "/") {
+		if dir == "node_modules" || dir == "vendor" {
+			return nil
+		}
+	}
+
+	name := path.Base(filePath)
+	switch {
+	case name == "go.mod":
+		return parseGoMod
+	case name == "package.json":
+		return parsePackageJSON
+	case strings.HasPrefix(name, "Containerfile"), strings.HasPrefix(name, "Dockerfile"),
+		strings.HasSuffix(name, ".Containerfile"), strings.HasSuffix(name, ".Dockerfile"):
+		return parseContainerfile
+	case strings.HasSuffix(name, ".sh"):
+		return parseImageLabels
+	}
+	return nil
+}
+
+// parseGoMod returns the modules required by a go.mod file
+func parseGoMod(source string, content []byte) ([]SBOMComponent, error) {
+	var components []SBOMComponent
+	add := func(fields []string) {
+		if len(fields) < 2 {
+			return
+		}
+		components = append(components, SBOMComponent{
+			Type:    ComponentLibrary,
+			Name:    fields[0],
+			Version: fields[1],
+			PURL:    fmt.Sprintf("pkg:golang/%s@%s", fields[0], fields[1]),
+			Source:  source,
+		})
+	}
+
+	inRequire := false
+	for _, line := range strings.Split(string(content), "\n") {
+		line, _, _ = strings.Cut(line, "//")
+		fields := strings.Fields(line)
+		switch {
+		case len(fields) == 0:
+		case inRequire && fields[0] == ")":
+			inRequire = false
+		case inRequire:
+			add(fields)
+		case fields[0] == "require" && len(fields) > 1 && fields[1] == "(":
+			inRequire = true
+		case fields[0] == "require":
+			add(fields[1:])
+		}
+	}
+	return components, nil
+}
+
+// parsePackageJSON returns the dependencies and development dependencies of
+// a package.json file
+func parsePackageJSON(source string, content []byte) ([]SBOMComponent, error) {
+	var manifest struct {
+		Dependencies    map[string]string `json:"dependencies"`
+		DevDependencies map[string]string `json:"devDependencies"`
+	}
+	if err := json.Unmarshal(content, &manifest); err != nil {
+		return nil, fmt.Errorf("invalid %s: %w", source, err)
+	}
+
+	var components []SBOMComponent
+	for _, dependencies := range []map[string]string{manifest.Dependencies, manifest.DevDependencies} {
+		for name, version := range dependencies {
+			// Version ranges do not identify a package version
+			purl := "pkg:npm/" + strings.Replace(name, "@", "%40", 1)
+			if isExactVersion(version) {
+				purl += "@" + version
+			}
+			components = append(components, SBOMComponent{
+				Type:    ComponentLibrary,
+				Name:    name,
+				Version: version,
+				PURL:    purl,
+				Source:  source,
+			})
+		}
+	}
+	return components, nil
+}
+
+func isExactVersion(version string) bool {
+	return version != "" && version[0] >= '0' && version[0] <= '9' && !strings.ContainsAny(version, " <>|*xX")
+}
+
+// parseContainerfile returns the base images of a Containerfile, skipping
+// build stages and images selected by build arguments
+func parseContainerfile(source string, content []byte) ([]SBOMComponent, error) {
+	stages := map[string]bool{"scratch": true}
+	var components []SBOMComponent
+	for _, line := range strings.Split(string(content), "\n") {
+		matches := fromRegex.FindStringSubmatch(line)
+		if matches == nil {
+			continue
+		}
+		if matches[2] != "" {
+			stages[strings.ToLower(matches[2])] = true
+		}
+		if component, ok := imageComponent(source, matches[1], stages); ok {
+			components = append(components, component)
+		}
+	}
+	return components, nil
+}
+
+// parseImageLabels returns the images of the org.nethserver.images labels of
+// a build script
+func parseImageLabels(source string, content []byte) ([]SBOMComponent, error) {
+	var components []SBOMComponent
+	for _, matches := range imageLabelRegex.FindAllStringSubmatch(string(content), -1) {
+		for _, ref := range strings.Fields(matches[1]) {
+			if component, ok := imageComponent(source, ref, nil); ok {
+				components = append(components, component)
+			}
+		}
+	}
+	return components, nil
+}
+
+// imageComponent parses an image reference such as
+// ghcr.io/nethserver/mail-dovecot:1.2.0, docker.io/library/mariadb:10.11.5
+// or an image pinned by digest
+func imageComponent(source, ref string, stages map[string]bool) (SBOMComponent, bool) {
+	if ref == "" || strings.Contains(ref, "$") || stages[strings.ToLower(ref)] {
+		return SBOMComponent{}, false
+	}
+
+	name, version := ref, "latest"
+	if before, digest, found := strings.Cut(ref, "@"); found {
+		name, version = before, digest
+	}
+	if i := strings.LastIndex(name, ":"); i > strings.LastIndex(name, "/") {
+		if version == "latest" {
+			version = name[i+1:]
+		}
+		name = name[:i]
+	}
+
+	registry, repository := "docker.io", name
+	if first, rest, found := strings.Cut(name, "/"); found && (strings.ContainsAny(first, ".:") || first == "localhost") {
+		registry, repository = first, rest
+	}
+	if registry == "docker.io" && !strings.Contains(repository, "/") {
+		repository = "library/" + repository
+	}
+
+	purl := fmt.Sprintf("pkg:docker/%s@%s", repository, url.PathEscape(version))
+	if registry != "docker.io" {
+		purl += "?repository_url=" + url.QueryEscape(registry)
+	}
+	return SBOMComponent{
+		Type:    ComponentContainer,
+		Name:    registry + "/" + repository,
+		Version: version,
+		PURL:    purl,
+		Source:  source,
+	}, true
+}
+
+// AssetName returns the name of the SBOM asset in format, e.g.
+// ns8-mail-1.2.0.spdx.json
+func (s *SBOM) AssetName(format string) string {
+	extension := "spdx"
+	if format == SBOMCycloneDX {
+		extension = "cdx"
+	}
+	return fmt.Sprintf("%s-%s.%s.json", path.Base(s.Repo), s.Version, extension)
+}
+
+// Document renders the SBOM as an SPDX 2.3 or CycloneDX 1.5 JSON document
+func (s *SBOM) Document(format string) ([]byte, error) {
+	var document interface{}
+	switch format {
+	case SBOMSPDX:
+		document = s.spdx()
+	case SBOMCycloneDX:
+		document = s.cycloneDX()
+	default:
+		return nil, ValidateSBOMFormat(format)
+	}
+
+	content, err := json.MarshalIndent(document, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append(content, '\n'), nil
+}
+
+func (s *SBOM) spdx() map[string]interface{} {
+	const modulePackage = "SPDXRef-Package-module"
+	packages := []map[string]interface{}{{
+		"SPDXID":           modulePackage,
+		"name":             path.Base(s.Repo),
+		"versionInfo":      s.Version,
+		"downloadLocation": fmt.Sprintf("git+%s@%s", github.WebURL(s.Repo), s.Commit),
+		"filesAnalyzed":    false,
+	}}
+	relationships := []map[string]interface{}{{
+		"spdxElementId":      "SPDXRef-DOCUMENT",
+		"relationshipType":   "DESCRIBES",
+		"relatedSpdxElement": modulePackage,
+	}}
+
+	for i, component := range s.Components {
+		id := fmt.Sprintf("SPDXRef-Package-%d", i+1)
+		packages = append(packages, map[string]interface{}{
+			"SPDXID":           id,
+			"name":             component.Name,
+			"versionInfo":      component.Version,
+			"downloadLocation": "NOASSERTION",
+			"filesAnalyzed":    false,
+			"comment":          "Declared in " + component.Source,
+			"externalRefs": []map[string]string{{
+				"referenceCategory": "PACKAGE-MANAGER",
+				"referenceType":     "purl",
+				"referenceLocator":  component.PURL,
+			}},
+		})
+		relationships = append(relationships, map[string]interface{}{
+			"spdxElementId":      modulePackage,
+			"relationshipType":   "DEPENDS_ON",
+			"relatedSpdxElement": id,
+		})
+	}
+
+	return map[string]interface{}{
+		"spdxVersion":       "SPDX-2.3",
+		"dataLicense":       "CC0-1.0",
+		"SPDXID":            "SPDXRef-DOCUMENT",
+		"name":              fmt.Sprintf("%s-%s", path.Base(s.Repo), s.Version),
+		"documentNamespace": fmt.Sprintf("%s/%s", github.WebURL(fmt.Sprintf("%s/releases/tag/%s", s.Repo, s.Version)), s.Commit),
+		"creationInfo": map[string]interface{}{
+			"created":  s.Created.Format(time.RFC3339),
+			"creators": []string{"Tool: gh-ns8"},
+		},
+		"packages":      packages,
+		"relationships": relationships,
+	}
+}
+
+func (s *SBOM) cycloneDX() map[string]interface{} {
+	components := make([]map[string]interface{}, 0, len(s.Components))
+	for _, component := range s.Components {
+		components = append(components, map[string]interface{}{
+			"type":    component.Type,
+			"bom-ref": fmt.Sprintf("%s:%s@%s", component.Type, component.Name, component.Version),
+			"name":    component.Name,
+			"version": component.Version,
+			"purl":    component.PURL,
+			"properties": []map[string]string{{
+				"name":  "gh-ns8:source",
+				"value": component.Source,
+			}},
+		})
+	}
+
+	return map[string]interface{}{
+		"bomFormat":   "CycloneDX",
+		"specVersion": "1.5",
+		"version":     1,
+		"metadata": map[string]interface{}{
+			"timestamp": s.Created.Format(time.RFC3339),
+			"tools": map[string]interface{}{
+				"components": []map[string]string{{"type": "application", "name": "gh-ns8"}},
+			},
+			"component": map[string]interface{}{
+				"type":    "application",
+				"name":    path.Base(s.Repo),
+				"version": s.Version,
+				"externalReferences": []map[string]string{{
+					"type": "vcs",
+					"url":  fmt.Sprintf("%s@%s", github.WebURL(s.Repo), s.Commit),
+				}},
+			},
+		},
+		"components": components,
+	}
+}
@@ -0,0 +1,171 @@
+package module_release
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/NethServer/gh-ns8/internal/github"
+)
+
+type fakeSBOMClient struct {
+	files map[string]string
+	reads []string
+}
+
+func (f *fakeSBOMClient) ListFiles(_, _ string) ([]string, error) {
+	paths := make([]string, 0, len(f.files))
+	for path := range f.files {
+		paths = append(paths, path)
+	}
+	return paths, nil
+}
+
+func (f *fakeSBOMClient) GetFile(_, path, ref string) (*github.File, error) {
+	f.reads = append(f.reads, path+"@"+ref)
+	return &github.File{Path: path, Content: []byte(f.files[path])}, nil
+}
+
+func newTestSBOMClient() *fakeSBOMClient {
+	return &fakeSBOMClient{files: map[string]string{
+		"README.md": "# ns8-mail",
+		"imageroot/go.mod": `module github.com/NethServer/ns8-mail
+
+go 1.22
+
+require github.com/spf13/cobra v1.8.0
+
+require (
+	github.com/spf13/pflag v1.0.5 // indirect
+)
+`,
+		"ui/package.json":                  `{"dependencies":{"@nethserver/ns8-ui-lib":"1.2.0"},"devDependencies":{"vue":"^2.7.0"}}`,
+		"ui/node_modules/vue/package.json": `{"dependencies":{"ignored":"1.0.0"}}`,
+		"build-images.sh": `buildah config \
+    --label="org.nethserver.images=docker.io/library/mariadb:10.11.5 ghcr.io/nethserver/mail-dovecot:${IMAGETAG:-latest}" \
+    "${container}"`,
+		"dovecot/Containerfile": `FROM docker.io/library/alpine:3.19 AS build
+RUN true
+FROM build
+FROM ghcr.io/nethserver/base@sha256:abc
+`,
+	}}
+}
+
+func TestNewSBOMCollectsComponents(t *testing.T) {
+	client := newTestSBOMClient()
+
+	sbom, err := NewSBOM(client, "NethServer/ns8-mail", "1.2.0", "abc123", time.Date(2026, 3, 1, 10, 0, 0, 0, time.UTC))
+	if err != nil {
+		t.Fatalf("NewSBOM() returned error: %v", err)
+	}
+
+	want := []SBOMComponent{
+		{Type: ComponentContainer, Name: "docker.io/library/alpine", Version: "3.19", PURL: "pkg:docker/library/alpine@3.19", Source: "dovecot/Containerfile"},
+		{Type: ComponentContainer, Name: "docker.io/library/mariadb", Version: "10.11.5", PURL: "pkg:docker/library/mariadb@10.11.5", Source: "build-images.sh"},
+		{Type: ComponentContainer, Name: "ghcr.io/nethserver/base", Version: "sha256:abc", PURL: "pkg:docker/nethserver/base@sha256:abc?repository_url=ghcr.io", Source: "dovecot/Containerfile"},
+		{Type: ComponentLibrary, Name: "@nethserver/ns8-ui-lib", Version: "1.2.0", PURL: "pkg:npm/%40nethserver/ns8-ui-lib@1.2.0", Source: "ui/package.json"},
+		{Type: ComponentLibrary, Name: "github.com/spf13/cobra", Version: "v1.8.0", PURL: "pkg:golang/github.com/spf13/cobra@v1.8.0", Source: "imageroot/go.mod"},
+		{Type: ComponentLibrary, Name: "github.com/spf13/pflag", Version: "v1.0.5", PURL: "pkg:golang/github.com/spf13/pflag@v1.0.5", Source: "imageroot/go.mod"},
+		{Type: ComponentLibrary, Name: "vue", Version: "^2.7.0", PURL: "pkg:npm/vue", Source: "ui/package.json"},
+	}
+	if !reflect.DeepEqual(sbom.Components, want) {
+		t.Fatalf("NewSBOM() components = %+v, want %+v", sbom.Components, want)
+	}
+	for _, read := range client.reads {
+		if strings.Contains(read, "node_modules") || strings.HasPrefix(read, "README.md") || !strings.HasSuffix(read, "@abc123") {
+			t.Fatalf("NewSBOM() read %s, want only manifests at abc123", read)
+		}
+	}
+}
+
+func TestSBOMDocuments(t *testing.T) {
+	sbom := &SBOM{
+		Repo:    "NethServer/ns8-mail",
+		Version: "1.2.0",
+		Commit:  "abc123",
+		Created: time.Date(2026, 3, 1, 10, 0, 0, 0, time.UTC),
+		Components: []SBOMComponent{
+			{Type: ComponentContainer, Name: "docker.io/library/mariadb", Version: "10.11.5", PURL: "pkg:docker/library/mariadb@10.11.5", Source: "build-images.sh"},
+		},
+	}
+
+	t.Run("spdx", func(t *testing.T) {
+		if got := sbom.AssetName(SBOMSPDX); got != "ns8-mail-1.2.0.spdx.json" {
+			t.Fatalf("AssetName() = %q, want %q", got, "ns8-mail-1.2.0.spdx.json")
+		}
+		content, err := sbom.Document(SBOMSPDX)
+		if err != nil {
+			t.Fatalf("Document() returned error: %v", err)
+		}
+		var document struct {
+			SPDXVersion  string `json:"spdxVersion"`
+			CreationInfo struct {
+				Created string `json:"created"`
+			} `json:"creationInfo"`
+			Packages []struct {
+				SPDXID       string `json:"SPDXID"`
+				Name         string `json:"name"`
+				ExternalRefs []struct {
+					ReferenceLocator string `json:"referenceLocator"`
+				} `json:"externalRefs"`
+			} `json:"packages"`
+			Relationships []struct {
+				RelationshipType string `json:"relationshipType"`
+			} `json:"relationships"`
+		}
+		if err := json.Unmarshal(content, &document); err != nil {
+			t.Fatalf("Document() is not JSON: %v", err)
+		}
+		if document.SPDXVersion != "SPDX-2.3" || document.CreationInfo.Created != "2026-03-01T10:00:00Z" {
+			t.Fatalf("Document() = %s, want an SPDX 2.3 document", content)
+		}
+		if len(document.Packages) != 2 || document.Packages[0].Name != "ns8-mail" || document.Packages[1].ExternalRefs[0].ReferenceLocator != "pkg:docker/library/mariadb@10.11.5" {
+			t.Fatalf("Document() packages = %+v, want the module and mariadb", document.Packages)
+		}
+		if len(document.Relationships) != 2 || document.Relationships[0].RelationshipType != "DESCRIBES" || document.Relationships[1].RelationshipType != "DEPENDS_ON" {
+			t.Fatalf("Document() relationships = %+v, want DESCRIBES and DEPENDS_ON", document.Relationships)
+		}
+	})
+
+	t.Run("cyclonedx", func(t *testing.T) {
+		if got := sbom.AssetName(SBOMCycloneDX); got != "ns8-mail-1.2.0.cdx.json" {
+			t.Fatalf("AssetName() = %q, want %q", got, "ns8-mail-1.2.0.cdx.json")
+		}
+		content, err := sbom.Document(SBOMCycloneDX)
+		if err != nil {
+			t.Fatalf("Document() returned error: %v", err)
+		}
+		var document struct {
+			BOMFormat   string `json:"bomFormat"`
+			SpecVersion string `json:"specVersion"`
+			Metadata    struct {
+				Component struct {
+					Name    string `json:"name"`
+					Version string `json:"version"`
+				} `json:"component"`
+			} `json:"metadata"`
+			Components []struct {
+				Type string `json:"type"`
+				PURL string `json:"purl"`
+			} `json:"components"`
+		}
+		if err := json.Unmarshal(content, &document); err != nil {
+			t.Fatalf("Document() is not JSON: %v", err)
+		}
+		if document.BOMFormat != "CycloneDX" || document.SpecVersion != "1.5" || document.Metadata.Component.Name != "ns8-mail" || document.Metadata.Component.Version != "1.2.0" {
+			t.Fatalf("Document() = %s, want a CycloneDX 1.5 document of ns8-mail 1.2.0", content)
+		}
+		if len(document.Components) != 1 || document.Components[0].Type != "container" || document.Components[0].PURL != "pkg:docker/library/mariadb@10.11.5" {
+			t.Fatalf("Document() components = %+v, want mariadb", document.Components)
+		}
+	})
+
+	t.Run("invalid format", func(t *testing.T) {
+		if _, err := sbom.Document("xml"); err == nil || err.Error() != `invalid SBOM format "xml": must be spdx or cyclonedx` {
+			t.Fatalf("Document() error = %v, want invalid format error", err)
+		}
+	})
+}
@@ -1,6 +1,7 @@
 package module_release
 
 import (
+	"errors"
 	"fmt"
 	"regexp"
 	"strconv"
@@ -17,8 +18,13 @@ type releaseViewer interface {
 }
 
 type releaseSequenceClient interface {
-	releaseClient
+	branchReleaseClient
 	refClient
+	draftReleaseClient
+}
+
+type draftReleaseClient interface {
+	ListDraftReleases(repo string) ([]github.Release, error)
 }
 
 type releaseHistoryClient interface {
@@ -31,70 +37,178 @@ func IsSemver(version string) bool {
 	return semverRegex.MatchString(version)
 }
 
-// NextTestingRelease generates the next testing release name
-func NextTestingRelease(client releaseSequenceClient, repo string) (string, error) {
-	// Get the latest release (including pre-releases)
-	releases, err := client.ListReleases(repo, 1, false)
-	if err != nil {
-		return "", fmt.Errorf("failed to get latest release: %w", err)
+// DefaultPrereleaseStages is the prerelease stage progression used unless
+// another one is configured
+var DefaultPrereleaseStages = PrereleaseStages{"testing"}
+
+var (
+	stageRegex      = regexp.MustCompile(`^[0-9A-Za-z-]*[A-Za-z-][0-9A-Za-z-]*$`)
+	prereleaseRegex = regexp.MustCompile(`^(\d+\.\d+\.\d+)-([0-9A-Za-z-]+)(?:\.(\d+))?$`)
+)
+
+// PrereleaseStages is the ordered progression of prerelease identifiers,
+// such as dev, testing, rc. The pre-releases of a version move forward
+// through the stages: 1.2.0-testing.4 is followed by 1.2.0-rc.1, never the
+// other way round.
+type PrereleaseStages []string
+
+// NewPrereleaseStages validates a list of stages, e.g. the
+// prerelease.stages configuration
+func NewPrereleaseStages(values []string) (PrereleaseStages, error) {
+	var stages PrereleaseStages
+	for _, stage := range values {
+		stage = strings.TrimSpace(stage)
+		if !stageRegex.MatchString(stage) {
+			return nil, fmt.Errorf("invalid prerelease stage: %q (must be an alphanumeric semver identifier)", stage)
+		}
+		if stages.index(stage) >= 0 {
+			return nil, fmt.Errorf("duplicate prerelease stage: %s", stage)
+		}
+		stages = append(stages, stage)
+	}
+	return stages, nil
+}
+
+// String implements fmt.Stringer
+func (s PrereleaseStages) String() string {
+	return strings.Join(s, " → ")
+}
+
+func (s PrereleaseStages) index(stage string) int {
+	for i, candidate := range s {
+		if candidate == stage {
+			return i
+		}
 	}
+	return -1
+}
 
-	if len(releases) == 0 {
-		return "", fmt.Errorf("no releases found")
+// NextPrerelease generates the next pre-release name of a stage from the
+// latest release of the branch. An empty stage selects the first one.
+func NextPrerelease(client releaseSequenceClient, repo, branch string, stages PrereleaseStages, stage string) (string, error) {
+	if len(stages) == 0 {
+		stages = DefaultPrereleaseStages
+	}
+	if stage == "" {
+		stage = stages[0]
+	}
+	if stages.index(stage) < 0 {
+		return "", fmt.Errorf("unknown prerelease stage: %s (configured stages: %s)", stage, stages)
 	}
 
-	latestRelease := releases[0]
+	// Get the latest release (including pre-releases)
+	latestRelease, err := GetLatestBranchRelease(client, repo, branch, false)
+	if errors.Is(err, ErrNoReleases) {
+		return "", err
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to get latest release: %w", err)
+	}
 
 	// Validate semver
 	if !IsSemver(latestRelease.TagName) {
 		return "", fmt.Errorf("invalid semver format for the latest release: %s", latestRelease.TagName)
 	}
 
-	// Check if the latest release is at HEAD of main
+	// Check if the latest release is at HEAD of the branch
 	latestSHA, err := GetReleaseCommitSHA(client, repo, latestRelease.TagName)
 	if err != nil {
 		return "", fmt.Errorf("failed to get release commit SHA: %w", err)
 	}
 
-	mainSHA, err := GetMainBranchSHA(client, repo)
+	branchSHA, err := GetBranchSHA(client, repo, branch)
 	if err != nil {
-		return "", fmt.Errorf("failed to get main branch SHA: %w", err)
+		return "", err
 	}
 
-	if latestSHA == mainSHA {
-		return "", fmt.Errorf("the latest release tag is the HEAD of the main branch")
+	if latestSHA == branchSHA {
+		return "", fmt.Errorf("the latest release tag is the HEAD of the %s branch", branch)
 	}
 
 	// Determine next version based on whether current is prerelease
+	var next string
 	if latestRelease.IsPrerelease {
-		// Increment testing number: 1.0.1-testing.1 -> 1.0.1-testing.2
-		return incrementTestingNumber(latestRelease.TagName)
+		// Same stage: 1.0.1-testing.1 -> 1.0.1-testing.2
+		// Later stage: 1.0.1-testing.4 -> 1.0.1-rc.1
+		next, err = nextStagePrerelease(latestRelease.TagName, stages, stage)
+	} else {
+		// Increment patch and start the stage: 1.0.0 -> 1.0.1-testing.1
+		next, err = incrementPatchWithPrerelease(latestRelease.TagName, stage)
+	}
+	if err != nil {
+		return "", err
 	}
 
-	// Increment patch and add -testing.1: 1.0.0 -> 1.0.1-testing.1
-	return incrementPatchAndAddTesting(latestRelease.TagName)
+	return skipDraftTags(client, repo, next)
 }
 
-// incrementTestingNumber increments the testing number in a prerelease version
-func incrementTestingNumber(version string) (string, error) {
-	// Match version-testing.N pattern
-	re := regexp.MustCompile(`^(.*-testing\.)(\d+)$`)
-	matches := re.FindStringSubmatch(version)
-	if len(matches) != 3 {
-		return "", fmt.Errorf("invalid testing version format: %s", version)
+// skipDraftTags returns the first pre-release from version whose tag is not
+// taken by a draft release. Drafts are not listed with the releases, but
+// their tag is created when they are published.
+func skipDraftTags(client draftReleaseClient, repo, version string) (string, error) {
+	drafts, err := client.ListDraftReleases(repo)
+	if err != nil {
+		return "", fmt.Errorf("failed to list draft releases: %w", err)
 	}
 
-	prefix := matches[1]
-	num, err := strconv.Atoi(matches[2])
-	if err != nil {
-		return "", fmt.Errorf("failed to parse testing number: %w", err)
+	tags := make(map[string]bool, len(drafts))
+	for _, draft := range drafts {
+		tags[draft.TagName] = true
+	}
+	for tags[version] {
+		if version, err = incrementPrereleaseNumber(version); err != nil {
+			return "", err
+		}
+	}
+	return version, nil
+}
+
+// nextStagePrerelease returns the pre-release of stage following version
+func nextStagePrerelease(version string, stages PrereleaseStages, stage string) (string, error) {
+	matches := prereleaseRegex.FindStringSubmatch(version)
+	if matches == nil {
+		return "", fmt.Errorf("invalid prerelease version format: %s", version)
+	}
+	base, identifier := matches[1], matches[2]
+
+	if identifier == stage {
+		return incrementPrereleaseNumber(version)
+	}
+
+	current := stages.index(identifier)
+	if current < 0 {
+		return "", fmt.Errorf("the latest release %s has prerelease identifier %q, which is not a configured stage (%s)", version, identifier, stages)
+	}
+	if stages.index(stage) < current {
+		return "", fmt.Errorf("cannot create a %s release after %s: stages follow %s", stage, version, stages)
+	}
+
+	return fmt.Sprintf("%s-%s.1", base, stage), nil
+}
+
+// incrementPrereleaseNumber increments the number of a prerelease version,
+// whatever its identifier: 1.2.0-rc.1 -> 1.2.0-rc.2. A prerelease without
+// number gets the number 1.
+func incrementPrereleaseNumber(version string) (string, error) {
+	matches := prereleaseRegex.FindStringSubmatch(version)
+	if matches == nil {
+		return "", fmt.Errorf("invalid prerelease version format: %s", version)
+	}
+
+	num := 0
+	if matches[3] != "" {
+		var err error
+		if num, err = strconv.Atoi(matches[3]); err != nil {
+			return "", fmt.Errorf("failed to parse prerelease number: %w", err)
+		}
 	}
 
-	return fmt.Sprintf("%s%d", prefix, num+1), nil
+	return fmt.Sprintf("%s-%s.%d", matches[1], matches[2], num+1), nil
 }
 
-// incrementPatchAndAddTesting increments the patch version and adds -testing.1
-func incrementPatchAndAddTesting(version string) (string, error) {
+// incrementPatchWithPrerelease increments the patch version and adds the
+// first pre-release of stage
+func incrementPatchWithPrerelease(version, stage string) (string, error) {
 	// Match X.Y.Z pattern
 	re := regexp.MustCompile(`^(\d+)\.(\d+)\.(\d+)`)
 	matches := re.FindStringSubmatch(version)
@@ -109,9 +223,13 @@ func incrementPatchAndAddTesting(version string) (string, error) {
 		return "", fmt.Errorf("failed to parse patch version: %w", err)
 	}
 
-	return fmt.Sprintf("%s.%s.%d-testing.1", major, minor, patch+1), nil
+	return fmt.Sprintf("%s.%s.%d-%s.1", major, minor, patch+1, stage), nil
 }
 
+// ErrNoPreviousRelease is returned when a release is the first one of the
+// listed releases, or the first stable one for a stable release
+var ErrNoPreviousRelease = errors.New("no previous release found")
+
 // FindPreviousRelease finds the previous release based on creation date
 func FindPreviousRelease(client releaseHistoryClient, repo, currentTag string) (string, error) {
 	// Check if current release is a pre-release
@@ -140,7 +258,7 @@ func FindPreviousRelease(client releaseHistoryClient, repo, currentTag string) (
 	}
 
 	if currentIndex == len(allReleases)-1 {
-		return "", fmt.Errorf("no previous release found")
+		return "", ErrNoPreviousRelease
 	}
 
 	// If current is prerelease, return previous release (any type)
@@ -155,10 +273,12 @@ func FindPreviousRelease(client releaseHistoryClient, repo, currentTag string) (
 		}
 	}
 
-	return "", fmt.Errorf("no previous stable release found")
+	return "", fmt.Errorf("%w: no stable release before %s", ErrNoPreviousRelease, currentTag)
 }
 
-// GetPreReleasesBetween gets pre-releases between two releases
+// GetPreReleasesBetween gets pre-releases between two releases, newest
+// first. An empty endTag selects all the pre-releases created after
+// startTag, e.g. those of a stable release not created yet.
 func GetPreReleasesBetween(client releaseClient, repo, startTag, endTag string) ([]string, error) {
 	allReleases, err := client.ListReleases(repo, 1000, false)
 	if err != nil {
@@ -175,13 +295,13 @@ func GetPreReleasesBetween(client releaseClient, repo, startTag, endTag string)
 		}
 	}
 
-	if startTime == "" || endTime == "" {
+	if startTime == "" || (endTag != "" && endTime == "") {
 		return nil, fmt.Errorf("could not find start or end release")
 	}
 
 	var preReleases []string
 	for _, r := range allReleases {
-		if r.IsPrerelease && r.CreatedAt > startTime && r.CreatedAt <= endTime {
+		if r.IsPrerelease && r.CreatedAt > startTime && (endTag == "" || r.CreatedAt <= endTime) {
 			preReleases = append(preReleases, r.TagName)
 		}
 	}
@@ -16,6 +16,13 @@ type fakeReleaseClient struct {
 	listErr      error
 	viewErr      error
 	refErrs      map[string]error
+	// offBranch lists the tags that are not reachable from the branch
+	offBranch map[string]bool
+	drafts    []ghgithub.Release
+}
+
+func (f fakeReleaseClient) ListDraftReleases(_ string) ([]ghgithub.Release, error) {
+	return f.drafts, nil
 }
 
 func (f fakeReleaseClient) ListReleases(_ string, limit int, excludePreReleases bool) ([]ghgithub.Release, error) {
@@ -65,6 +72,13 @@ func (f fakeReleaseClient) GetCommitSHA(repo, ref string) (string, error) {
 	return "", errors.New("ref not found")
 }
 
+func (f fakeReleaseClient) GetCompareStatus(_, _, head string) (string, error) {
+	if f.offBranch[head] {
+		return "diverged", nil
+	}
+	return "behind", nil
+}
+
 func TestIsSemver(t *testing.T) {
 	testCases := []struct {
 		name    string
@@ -87,7 +101,7 @@ func TestIsSemver(t *testing.T) {
 	}
 }
 
-func TestNextTestingReleaseFromStableRelease(t *testing.T) {
+func TestNextPrereleaseFromStableRelease(t *testing.T) {
 	client := fakeReleaseClient{
 		releases: []ghgithub.Release{
 			{TagName: "1.2.3", IsPrerelease: false},
@@ -98,16 +112,16 @@ func TestNextTestingReleaseFromStableRelease(t *testing.T) {
 		},
 	}
 
-	got, err := NextTestingRelease(client, "NethServer/ns8-mail")
+	got, err := NextPrerelease(client, "NethServer/ns8-mail", "main", nil, "")
 	if err != nil {
-		t.Fatalf("NextTestingRelease() returned error: %v", err)
+		t.Fatalf("NextPrerelease() returned error: %v", err)
 	}
 	if got != "1.2.4-testing.1" {
-		t.Fatalf("NextTestingRelease() = %q, want %q", got, "1.2.4-testing.1")
+		t.Fatalf("NextPrerelease() = %q, want %q", got, "1.2.4-testing.1")
 	}
 }
 
-func TestNextTestingReleaseFromPrerelease(t *testing.T) {
+func TestNextPrereleaseFromPrerelease(t *testing.T) {
 	client := fakeReleaseClient{
 		releases: []ghgithub.Release{
 			{TagName: "1.2.4-testing.2", IsPrerelease: true},
@@ -118,16 +132,40 @@ func TestNextTestingReleaseFromPrerelease(t *testing.T) {
 		},
 	}
 
-	got, err := NextTestingRelease(client, "NethServer/ns8-mail")
+	got, err := NextPrerelease(client, "NethServer/ns8-mail", "main", nil, "")
 	if err != nil {
-		t.Fatalf("NextTestingRelease() returned error: %v", err)
+		t.Fatalf("NextPrerelease() returned error: %v", err)
 	}
 	if got != "1.2.4-testing.3" {
-		t.Fatalf("NextTestingRelease() = %q, want %q", got, "1.2.4-testing.3")
+		t.Fatalf("NextPrerelease() = %q, want %q", got, "1.2.4-testing.3")
+	}
+}
+
+func TestNextPrereleaseSkipsDraftTags(t *testing.T) {
+	client := fakeReleaseClient{
+		releases: []ghgithub.Release{
+			{TagName: "1.2.4-testing.2", IsPrerelease: true},
+		},
+		drafts: []ghgithub.Release{
+			{TagName: "1.2.4-testing.3", IsPrerelease: true, IsDraft: true},
+			{TagName: "1.2.4-testing.4", IsPrerelease: true, IsDraft: true},
+		},
+		refs: map[string]string{
+			"NethServer/ns8-mail|tags/1.2.4-testing.2": "release-sha",
+			"NethServer/ns8-mail|heads/main":           "main-sha",
+		},
+	}
+
+	got, err := NextPrerelease(client, "NethServer/ns8-mail", "main", nil, "")
+	if err != nil {
+		t.Fatalf("NextPrerelease() returned error: %v", err)
+	}
+	if got != "1.2.4-testing.5" {
+		t.Fatalf("NextPrerelease() = %q, want %q", got, "1.2.4-testing.5")
 	}
 }
 
-func TestNextTestingReleaseRejectsHeadRelease(t *testing.T) {
+func TestNextPrereleaseRejectsHeadRelease(t *testing.T) {
 	client := fakeReleaseClient{
 		releases: []ghgithub.Release{
 			{TagName: "1.2.3", IsPrerelease: false},
@@ -138,52 +176,140 @@ func TestNextTestingReleaseRejectsHeadRelease(t *testing.T) {
 		},
 	}
 
-	_, err := NextTestingRelease(client, "NethServer/ns8-mail")
+	_, err := NextPrerelease(client, "NethServer/ns8-mail", "main", nil, "")
 	if err == nil || !strings.Contains(err.Error(), "the latest release tag is the HEAD of the main branch") {
-		t.Fatalf("NextTestingRelease() error = %v, want head release error", err)
+		t.Fatalf("NextPrerelease() error = %v, want head release error", err)
 	}
 }
 
-func TestNextTestingReleaseRejectsInvalidSemver(t *testing.T) {
+func TestNextPrereleaseUsesLatestReleaseOfBranch(t *testing.T) {
+	client := fakeReleaseClient{
+		releases: []ghgithub.Release{
+			{TagName: "1.5.0", IsPrerelease: false},
+			{TagName: "1.4.2", IsPrerelease: false},
+		},
+		refs: map[string]string{
+			"NethServer/ns8-mail|tags/1.4.2":       "release-sha",
+			"NethServer/ns8-mail|heads/stable-1.4": "stable-sha",
+		},
+		offBranch: map[string]bool{"1.5.0": true},
+	}
+
+	got, err := NextPrerelease(client, "NethServer/ns8-mail", "stable-1.4", nil, "")
+	if err != nil {
+		t.Fatalf("NextPrerelease() returned error: %v", err)
+	}
+	if got != "1.4.3-testing.1" {
+		t.Fatalf("NextPrerelease() = %q, want %q", got, "1.4.3-testing.1")
+	}
+}
+
+func TestNextPrereleaseRejectsInvalidSemver(t *testing.T) {
 	client := fakeReleaseClient{
 		releases: []ghgithub.Release{
 			{TagName: "latest", IsPrerelease: false},
 		},
 	}
 
-	_, err := NextTestingRelease(client, "NethServer/ns8-mail")
+	_, err := NextPrerelease(client, "NethServer/ns8-mail", "main", nil, "")
 	if err == nil || !strings.Contains(err.Error(), "invalid semver format for the latest release") {
-		t.Fatalf("NextTestingRelease() error = %v, want invalid semver error", err)
+		t.Fatalf("NextPrerelease() error = %v, want invalid semver error", err)
 	}
 }
 
-func TestIncrementTestingNumber(t *testing.T) {
-	got, err := incrementTestingNumber("1.2.3-testing.9")
+func TestNextPrereleaseMovesToLaterStage(t *testing.T) {
+	client := fakeReleaseClient{
+		releases: []ghgithub.Release{
+			{TagName: "1.2.0-testing.4", IsPrerelease: true},
+		},
+		refs: map[string]string{
+			"NethServer/ns8-mail|tags/1.2.0-testing.4": "release-sha",
+			"NethServer/ns8-mail|heads/main":           "main-sha",
+		},
+	}
+
+	got, err := NextPrerelease(client, "NethServer/ns8-mail", "main", PrereleaseStages{"dev", "testing", "rc"}, "rc")
 	if err != nil {
-		t.Fatalf("incrementTestingNumber() returned error: %v", err)
+		t.Fatalf("NextPrerelease() returned error: %v", err)
 	}
-	if got != "1.2.3-testing.10" {
-		t.Fatalf("incrementTestingNumber() = %q, want %q", got, "1.2.3-testing.10")
+	if got != "1.2.0-rc.1" {
+		t.Fatalf("NextPrerelease() = %q, want %q", got, "1.2.0-rc.1")
 	}
+}
 
-	_, err = incrementTestingNumber("1.2.3")
-	if err == nil || !strings.Contains(err.Error(), "invalid testing version format") {
-		t.Fatalf("incrementTestingNumber() error = %v, want invalid format error", err)
+func TestNextPrereleaseRejectsUnknownStage(t *testing.T) {
+	_, err := NextPrerelease(fakeReleaseClient{}, "NethServer/ns8-mail", "main", nil, "rc")
+	if err == nil || !strings.Contains(err.Error(), "unknown prerelease stage: rc") {
+		t.Fatalf("NextPrerelease() error = %v, want unknown stage error", err)
 	}
 }
 
-func TestIncrementPatchAndAddTesting(t *testing.T) {
-	got, err := incrementPatchAndAddTesting("1.2.3")
+func TestNextStagePrerelease(t *testing.T) {
+	stages := PrereleaseStages{"dev", "testing", "rc"}
+
+	testCases := []struct {
+		name    string
+		version string
+		stage   string
+		want    string
+		wantErr string
+	}{
+		{name: "same stage", version: "1.2.3-testing.9", stage: "testing", want: "1.2.3-testing.10"},
+		{name: "later stage", version: "1.2.3-dev.5", stage: "rc", want: "1.2.3-rc.1"},
+		{name: "unconfigured identifier", version: "1.2.3-beta.2", stage: "beta", want: "1.2.3-beta.3"},
+		{name: "identifier without number", version: "1.2.3-rc", stage: "rc", want: "1.2.3-rc.1"},
+		{name: "earlier stage", version: "1.2.3-rc.1", stage: "testing", wantErr: "cannot create a testing release after 1.2.3-rc.1"},
+		{name: "unknown current stage", version: "1.2.3-beta.2", stage: "rc", wantErr: `prerelease identifier "beta", which is not a configured stage`},
+		{name: "stable version", version: "1.2.3", stage: "rc", wantErr: "invalid prerelease version format"},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			got, err := nextStagePrerelease(testCase.version, stages, testCase.stage)
+			if testCase.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), testCase.wantErr) {
+					t.Fatalf("nextStagePrerelease() error = %v, want %q", err, testCase.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("nextStagePrerelease() returned error: %v", err)
+			}
+			if got != testCase.want {
+				t.Fatalf("nextStagePrerelease() = %q, want %q", got, testCase.want)
+			}
+		})
+	}
+}
+
+func TestIncrementPatchWithPrerelease(t *testing.T) {
+	got, err := incrementPatchWithPrerelease("1.2.3", "rc")
 	if err != nil {
-		t.Fatalf("incrementPatchAndAddTesting() returned error: %v", err)
+		t.Fatalf("incrementPatchWithPrerelease() returned error: %v", err)
 	}
-	if got != "1.2.4-testing.1" {
-		t.Fatalf("incrementPatchAndAddTesting() = %q, want %q", got, "1.2.4-testing.1")
+	if got != "1.2.4-rc.1" {
+		t.Fatalf("incrementPatchWithPrerelease() = %q, want %q", got, "1.2.4-rc.1")
 	}
 
-	_, err = incrementPatchAndAddTesting("release")
+	_, err = incrementPatchWithPrerelease("release", "testing")
 	if err == nil || !strings.Contains(err.Error(), "invalid semver format") {
-		t.Fatalf("incrementPatchAndAddTesting() error = %v, want invalid semver error", err)
+		t.Fatalf("incrementPatchWithPrerelease() error = %v, want invalid semver error", err)
+	}
+}
+
+func TestNewPrereleaseStages(t *testing.T) {
+	got, err := NewPrereleaseStages([]string{"dev", " testing", "rc"})
+	if err != nil {
+		t.Fatalf("NewPrereleaseStages() returned error: %v", err)
+	}
+	if !reflect.DeepEqual(got, PrereleaseStages{"dev", "testing", "rc"}) {
+		t.Fatalf("NewPrereleaseStages() = %v, want [dev testing rc]", got)
+	}
+
+	for _, values := range [][]string{{""}, {"dev", "", "rc"}, {"testing", "testing"}, {"1"}, {"rc.1"}} {
+		if _, err := NewPrereleaseStages(values); err == nil {
+			t.Errorf("NewPrereleaseStages(%q) returned no error", values)
+		}
 	}
 }
 
@@ -268,6 +394,27 @@ func TestGetPreReleasesBetweenFiltersReleaseWindow(t *testing.T) {
 	}
 }
 
+func TestGetPreReleasesBetweenWithoutEndSelectsLaterPreReleases(t *testing.T) {
+	client := fakeReleaseClient{
+		releases: []ghgithub.Release{
+			{TagName: "1.2.2-testing.2", IsPrerelease: true, CreatedAt: "2024-01-08T00:00:00Z"},
+			{TagName: "1.2.2-testing.1", IsPrerelease: true, CreatedAt: "2024-01-05T00:00:00Z"},
+			{TagName: "1.2.1", IsPrerelease: false, CreatedAt: "2024-01-01T00:00:00Z"},
+			{TagName: "1.2.1-testing.1", IsPrerelease: true, CreatedAt: "2023-12-31T23:59:59Z"},
+		},
+	}
+
+	got, err := GetPreReleasesBetween(client, "NethServer/ns8-mail", "1.2.1", "")
+	if err != nil {
+		t.Fatalf("GetPreReleasesBetween() returned error: %v", err)
+	}
+
+	want := []string{"1.2.2-testing.2", "1.2.2-testing.1"}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("GetPreReleasesBetween() = %v, want %v", got, want)
+	}
+}
+
 func TestGetPreReleasesBetweenErrorsWhenBoundsAreMissing(t *testing.T) {
 	client := fakeReleaseClient{
 		releases: []ghgithub.Release{
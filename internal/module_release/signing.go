@@ -0,0 +1,177 @@
+package module_release
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// sshEd25519 is the SSH key type of ed25519 keys
+const sshEd25519 = "ssh-ed25519"
+
+// openSSHMagic starts the OpenSSH private key format
+const openSSHMagic = "openssh-key-v1\x00"
+
+// ParsePrivateKey parses an ed25519 private key, in the OpenSSH format of
+// ssh-keygen -t ed25519 or PEM encoded PKCS #8. Keys protected by a
+// passphrase are not supported.
+func ParsePrivateKey(data []byte) (ed25519.PrivateKey, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("invalid private key: no PEM block found")
+	}
+
+	switch block.Type {
+	case "OPENSSH PRIVATE KEY":
+		return parseOpenSSHPrivateKey(block.Bytes)
+	case "PRIVATE KEY":
+		key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+		if err != nil {
+			return nil, fmt.Errorf("invalid private key: %w", err)
+		}
+		private, ok := key.(ed25519.PrivateKey)
+		if !ok {
+			return nil, fmt.Errorf("unsupported private key type %T: only ed25519 keys are supported", key)
+		}
+		return private, nil
+	}
+	return nil, fmt.Errorf("unsupported private key type %q", block.Type)
+}
+
+func parseOpenSSHPrivateKey(data []byte) (ed25519.PrivateKey, error) {
+	if !bytes.HasPrefix(data, []byte(openSSHMagic)) {
+		return nil, fmt.Errorf("invalid OpenSSH private key")
+	}
+	reader := &sshReader{data: data[len(openSSHMagic):]}
+
+	cipher := reader.string()
+	kdf := reader.string()
+	reader.string() // KDF options
+	count := reader.uint32()
+	reader.string() // public key
+	private := &sshReader{data: reader.bytes()}
+	if reader.err != nil {
+		return nil, fmt.Errorf("invalid OpenSSH private key: %w", reader.err)
+	}
+	if cipher != "none" || kdf != "none" {
+		return nil, fmt.Errorf("encrypted private keys are not supported: remove the passphrase of a copy of the key")
+	}
+	if count != 1 {
+		return nil, fmt.Errorf("invalid OpenSSH private key: %d keys found, want 1", count)
+	}
+
+	if private.uint32() != private.uint32() {
+		return nil, fmt.Errorf("invalid OpenSSH private key: check mismatch")
+	}
+	keyType := private.string()
+	private.bytes() // public key
+	key := private.bytes()
+	if private.err != nil {
+		return nil, fmt.Errorf("invalid OpenSSH private key: %w", private.err)
+	}
+	if keyType != sshEd25519 {
+		return nil, fmt.Errorf("unsupported private key type %q: only ed25519 keys are supported", keyType)
+	}
+	if len(key) != ed25519.PrivateKeySize {
+		return nil, fmt.Errorf("invalid OpenSSH private key: ed25519 key of %d bytes", len(key))
+	}
+	return ed25519.PrivateKey(key), nil
+}
+
+// ParsePublicKey parses an ed25519 public key, in the authorized_keys format
+// of an OpenSSH .pub file or PEM encoded PKIX
+func ParsePublicKey(data []byte) (ed25519.PublicKey, error) {
+	if block, _ := pem.Decode(data); block != nil {
+		if block.Type != "PUBLIC KEY" {
+			return nil, fmt.Errorf("unsupported public key type %q", block.Type)
+		}
+		key, err := x509.ParsePKIXPublicKey(block.Bytes)
+		if err != nil {
+			return nil, fmt.Errorf("invalid public key: %w", err)
+		}
+		public, ok := key.(ed25519.PublicKey)
+		if !ok {
+			return nil, fmt.Errorf("unsupported public key type %T: only ed25519 keys are supported", key)
+		}
+		return public, nil
+	}
+
+	fields := strings.Fields(string(data))
+	if len(fields) < 2 {
+		return nil, fmt.Errorf("invalid public key: want an OpenSSH public key or a PEM block")
+	}
+	if fields[0] != sshEd25519 {
+		return nil, fmt.Errorf("unsupported public key type %q: only ed25519 keys are supported", fields[0])
+	}
+	wire, err := base64.StdEncoding.DecodeString(fields[1])
+	if err != nil {
+		return nil, fmt.Errorf("invalid public key: %w", err)
+	}
+
+	reader := &sshReader{data: wire}
+	keyType := reader.string()
+	key := reader.bytes()
+	if reader.err != nil || keyType != sshEd25519 || len(key) != ed25519.PublicKeySize {
+		return nil, fmt.Errorf("invalid public key: malformed %s key", sshEd25519)
+	}
+	return ed25519.PublicKey(key), nil
+}
+
+// KeyFingerprint returns the SHA-256 fingerprint of a public key, as printed
+// by ssh-keygen -l, e.g. SHA256:uNiVztksCsDhcc0u9e8BujQXVUpKZIDTMczCvj3tD2s
+func KeyFingerprint(key ed25519.PublicKey) string {
+	var wire bytes.Buffer
+	writeSSHString(&wire, []byte(sshEd25519))
+	writeSSHString(&wire, key)
+	sum := sha256.Sum256(wire.Bytes())
+	return "SHA256:" + base64.RawStdEncoding.EncodeToString(sum[:])
+}
+
+func writeSSHString(buffer *bytes.Buffer, value []byte) {
+	binary.Write(buffer, binary.BigEndian, uint32(len(value)))
+	buffer.Write(value)
+}
+
+// sshReader reads the fields of the SSH wire format, keeping the first error
+type sshReader struct {
+	data []byte
+	err  error
+}
+
+func (r *sshReader) uint32() uint32 {
+	if r.err != nil {
+		return 0
+	}
+	if len(r.data) < 4 {
+		r.err = errors.New("unexpected end of data")
+		return 0
+	}
+	value := binary.BigEndian.Uint32(r.data)
+	r.data = r.data[4:]
+	return value
+}
+
+func (r *sshReader) bytes() []byte {
+	length := r.uint32()
+	if r.err != nil {
+		return nil
+	}
+	if uint32(len(r.data)) < length {
+		r.err = errors.New("unexpected end of data")
+		return nil
+	}
+	value := r.data[:length]
+	r.data = r.data[length:]
+	return value
+}
+
+func (r *sshReader) string() string {
+	return string(r.bytes())
+}
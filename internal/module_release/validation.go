@@ -0,0 +1,213 @@
+package module_release
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/NethServer/gh-ns8/internal/config"
+	"github.com/NethServer/gh-ns8/internal/github"
+	"gopkg.in/yaml.v3"
+)
+
+type metadataClient interface {
+	GetFile(repo, path, ref string) (*github.File, error)
+}
+
+// MetadataFile is a metadata file read at the release commit
+type MetadataFile struct {
+	config.MetadataFile
+	Content []byte
+	// Found is false for a file missing at the release commit
+	Found bool
+}
+
+// MetadataRelease is the release the metadata is checked against
+type MetadataRelease struct {
+	Name       string
+	Prerelease bool
+	Stages     PrereleaseStages
+}
+
+// MetadataCheck returns the problems a check finds in the metadata files,
+// none when they are consistent with the release
+type MetadataCheck func(release MetadataRelease, files []MetadataFile) []string
+
+// metadataChecks are the checks by the name configured in validation.checks
+var metadataChecks = map[string]MetadataCheck{
+	config.CheckRequiredFiles: checkRequiredFiles,
+	config.CheckVersionMatch:  checkVersionMatch,
+	config.CheckNoPrerelease:  checkNoPrerelease,
+}
+
+// MetadataCheckResult is the outcome of a metadata check
+type MetadataCheckResult struct {
+	Check    string
+	Problems []string
+}
+
+// Passed tells if the check found no problems
+func (r MetadataCheckResult) Passed() bool {
+	return len(r.Problems) == 0
+}
+
+// ValidateMetadata reads the files of validation at commit and runs its
+// checks against release, in the configured order
+func ValidateMetadata(client metadataClient, repo, commit string, validation config.Validation, release MetadataRelease) ([]MetadataCheckResult, error) {
+	if len(validation.Checks) == 0 {
+		return nil, nil
+	}
+
+	files := make([]MetadataFile, 0, len(validation.Files))
+	for _, file := range validation.Files {
+		metadata := MetadataFile{MetadataFile: file}
+		content, err := client.GetFile(repo, file.Path, commit)
+		switch {
+		case errors.Is(err, github.ErrNotFound):
+		case err != nil:
+			return nil, err
+		default:
+			metadata.Content = content.Content
+			metadata.Found = true
+		}
+		files = append(files, metadata)
+	}
+
+	results := make([]MetadataCheckResult, 0, len(validation.Checks))
+	for _, name := range validation.Checks {
+		check, ok := metadataChecks[name]
+		if !ok {
+			return nil, fmt.Errorf("unknown metadata check %q", name)
+		}
+		results = append(results, MetadataCheckResult{Check: name, Problems: check(release, files)})
+	}
+	return results, nil
+}
+
+// checkRequiredFiles reports the missing files not marked optional
+func checkRequiredFiles(release MetadataRelease, files []MetadataFile) []string {
+	var problems []string
+	for _, file := range files {
+		if !file.Found && !file.Optional {
+			problems = append(problems, fmt.Sprintf("%s: not found", file.Path))
+		}
+	}
+	return problems
+}
+
+// checkVersionMatch reports the versions that are not the release name. The
+// metadata of a pre-release may carry the upcoming stable version instead,
+// e.g. 1.2.0 for 1.2.0-testing.1.
+func checkVersionMatch(release MetadataRelease, files []MetadataFile) []string {
+	accepted := []string{release.Name}
+	if release.Prerelease {
+		accepted = append(accepted, stableVersion(release.Name))
+	}
+
+	var problems []string
+	for _, file := range files {
+		if !file.Found || (file.VersionField == "" && file.VersionPattern == "") {
+			continue
+		}
+		versions, err := file.Versions()
+		if err != nil {
+			problems = append(problems, fmt.Sprintf("%s: %v", file.Path, err))
+			continue
+		}
+		if len(versions) == 0 {
+			problems = append(problems, fmt.Sprintf("%s: no version found", file.Path))
+			continue
+		}
+		for _, version := range versions {
+			if !slices.Contains(accepted, strings.TrimPrefix(version, "v")) {
+				problems = append(problems, fmt.Sprintf("%s: version %s does not match %s", file.Path, version, release.Name))
+			}
+		}
+	}
+	return problems
+}
+
+// checkNoPrerelease reports the pre-release versions of the configured
+// stages found in the files of a stable release
+func checkNoPrerelease(release MetadataRelease, files []MetadataFile) []string {
+	if release.Prerelease || len(release.Stages) == 0 {
+		return nil
+	}
+
+	stages := make([]string, 0, len(release.Stages))
+	for _, stage := range release.Stages {
+		stages = append(stages, regexp.QuoteMeta(stage))
+	}
+	prerelease := regexp.MustCompile(`\bv?\d+\.\d+\.\d+-(?:` + strings.Join(stages, "|") + `)\b[0-9A-Za-z.-]*`)
+
+	var problems []string
+	for _, file := range files {
+		if !file.Found {
+			continue
+		}
+		for _, version := range uniqueStrings(prerelease.FindAllString(string(file.Content), -1)) {
+			problems = append(problems, fmt.Sprintf("%s: pre-release version %s", file.Path, version))
+		}
+	}
+	return problems
+}
+
+// Versions returns the distinct versions of the file, read from its version
+// field or matched by its version pattern
+func (f MetadataFile) Versions() ([]string, error) {
+	if f.VersionPattern != "" {
+		pattern, err := regexp.Compile(f.VersionPattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid version pattern: %w", err)
+		}
+		var versions []string
+		for _, match := range pattern.FindAllStringSubmatch(string(f.Content), -1) {
+			versions = append(versions, match[1])
+		}
+		return uniqueStrings(versions), nil
+	}
+	if f.VersionField == "" {
+		return nil, nil
+	}
+
+	// JSON documents are YAML documents too
+	var document interface{}
+	if err := yaml.Unmarshal(f.Content, &document); err != nil {
+		return nil, fmt.Errorf("invalid document: %w", err)
+	}
+	value := document
+	for _, key := range strings.Split(f.VersionField, ".") {
+		fields, ok := value.(map[string]interface{})
+		if !ok {
+			return nil, nil
+		}
+		if value, ok = fields[key]; !ok {
+			return nil, nil
+		}
+	}
+	switch value.(type) {
+	case map[string]interface{}, []interface{}, nil:
+		return nil, fmt.Errorf("%s is not a version", f.VersionField)
+	}
+	return []string{fmt.Sprint(value)}, nil
+}
+
+// stableVersion returns the version without its pre-release and build
+// metadata, e.g. 1.2.0 for 1.2.0-testing.1
+func stableVersion(version string) string {
+	version, _, _ = strings.Cut(version, "+")
+	version, _, _ = strings.Cut(version, "-")
+	return version
+}
+
+func uniqueStrings(values []string) []string {
+	var unique []string
+	for _, value := range values {
+		if !slices.Contains(unique, value) {
+			unique = append(unique, value)
+		}
+	}
+	return unique
+}
@@ -0,0 +1,154 @@
+package module_release
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/NethServer/gh-ns8/internal/config"
+	"github.com/NethServer/gh-ns8/internal/github"
+)
+
+type fakeMetadataClient struct {
+	files map[string]string
+	reads []string
+}
+
+func (f *fakeMetadataClient) GetFile(_, path, ref string) (*github.File, error) {
+	f.reads = append(f.reads, path+"@"+ref)
+	content, ok := f.files[path]
+	if !ok {
+		return nil, fmt.Errorf("failed to get %s: %w", path, github.ErrNotFound)
+	}
+	return &github.File{Path: path, Content: []byte(content)}, nil
+}
+
+func testValidation() config.Validation {
+	return config.Validation{
+		Checks: []string{config.CheckRequiredFiles, config.CheckVersionMatch, config.CheckNoPrerelease},
+		Files: []config.MetadataFile{
+			{Path: "ui/package.json", VersionField: "version"},
+			{Path: "imageroot/metadata.yml", VersionField: "module.version"},
+			{Path: "README.md", VersionPattern: `ns8-mail:(\S+)`, Optional: true},
+			{Path: "build-images.sh"},
+		},
+	}
+}
+
+func TestValidateMetadataPassesConsistentFiles(t *testing.T) {
+	client := &fakeMetadataClient{files: map[string]string{
+		"ui/package.json":        `{"name": "ns8-mail", "version": "1.2.0"}`,
+		"imageroot/metadata.yml": "module:\n  version: v1.2.0\n",
+		"README.md":              "Install ghcr.io/nethserver/ns8-mail:1.2.0",
+		"build-images.sh":        "images=ghcr.io/nethserver/mail-dovecot:1.2.0",
+	}}
+
+	results, err := ValidateMetadata(client, "NethServer/ns8-mail", "abc123", testValidation(), MetadataRelease{Name: "1.2.0", Stages: PrereleaseStages{"testing"}})
+	if err != nil {
+		t.Fatalf("ValidateMetadata() returned error: %v", err)
+	}
+	want := []MetadataCheckResult{{Check: config.CheckRequiredFiles}, {Check: config.CheckVersionMatch}, {Check: config.CheckNoPrerelease}}
+	if !reflect.DeepEqual(results, want) {
+		t.Fatalf("ValidateMetadata() = %+v, want %+v", results, want)
+	}
+	if client.reads[0] != "ui/package.json@abc123" {
+		t.Fatalf("reads = %v, want the files of commit abc123", client.reads)
+	}
+}
+
+func TestValidateMetadataReportsProblems(t *testing.T) {
+	client := &fakeMetadataClient{files: map[string]string{
+		"ui/package.json": `{"name": "ns8-mail", "version": "1.1.0"}`,
+		"README.md":       "Install ghcr.io/nethserver/ns8-mail:1.2.0 or ghcr.io/nethserver/ns8-mail:1.2.0-testing.2",
+		"build-images.sh": "images=ghcr.io/nethserver/mail-dovecot:1.2.0-testing.2",
+	}}
+
+	results, err := ValidateMetadata(client, "NethServer/ns8-mail", "abc123", testValidation(), MetadataRelease{Name: "1.2.0", Stages: PrereleaseStages{"testing"}})
+	if err != nil {
+		t.Fatalf("ValidateMetadata() returned error: %v", err)
+	}
+	want := []MetadataCheckResult{
+		{Check: config.CheckRequiredFiles, Problems: []string{"imageroot/metadata.yml: not found"}},
+		{Check: config.CheckVersionMatch, Problems: []string{
+			"ui/package.json: version 1.1.0 does not match 1.2.0",
+			"README.md: version 1.2.0-testing.2 does not match 1.2.0",
+		}},
+		{Check: config.CheckNoPrerelease, Problems: []string{
+			"README.md: pre-release version 1.2.0-testing.2",
+			"build-images.sh: pre-release version 1.2.0-testing.2",
+		}},
+	}
+	if !reflect.DeepEqual(results, want) {
+		t.Fatalf("ValidateMetadata() = %+v, want %+v", results, want)
+	}
+}
+
+func TestValidateMetadataOfPrerelease(t *testing.T) {
+	client := &fakeMetadataClient{files: map[string]string{
+		"ui/package.json":        `{"version": "1.2.0"}`,
+		"imageroot/metadata.yml": "module:\n  version: 1.2.0-testing.1\n",
+		"build-images.sh":        "images=ghcr.io/nethserver/mail-dovecot:1.2.0-testing.1",
+	}}
+
+	results, err := ValidateMetadata(client, "NethServer/ns8-mail", "abc123", testValidation(), MetadataRelease{Name: "1.2.0-testing.1", Prerelease: true, Stages: PrereleaseStages{"testing"}})
+	if err != nil {
+		t.Fatalf("ValidateMetadata() returned error: %v", err)
+	}
+	for _, result := range results {
+		if !result.Passed() {
+			t.Fatalf("check %s failed: %v, want the stable or pre-release version accepted", result.Check, result.Problems)
+		}
+	}
+}
+
+func TestValidateMetadataReturnsReadErrors(t *testing.T) {
+	validation := config.Validation{Checks: []string{config.CheckRequiredFiles}, Files: []config.MetadataFile{{Path: "ui/package.json"}}}
+
+	_, err := ValidateMetadata(failingMetadataClient{}, "NethServer/ns8-mail", "abc123", validation, MetadataRelease{Name: "1.2.0"})
+	if !errors.Is(err, github.ErrForbidden) {
+		t.Fatalf("ValidateMetadata() error = %v, want ErrForbidden", err)
+	}
+}
+
+type failingMetadataClient struct{}
+
+func (failingMetadataClient) GetFile(_, path, _ string) (*github.File, error) {
+	return nil, fmt.Errorf("failed to get %s: %w", path, github.ErrForbidden)
+}
+
+func TestMetadataFileVersions(t *testing.T) {
+	tests := []struct {
+		name    string
+		file    MetadataFile
+		want    []string
+		wantErr bool
+	}{
+		{name: "json field", file: MetadataFile{MetadataFile: config.MetadataFile{VersionField: "version"}, Content: []byte(`{"version": "1.2.0"}`)}, want: []string{"1.2.0"}},
+		{name: "nested yaml field", file: MetadataFile{MetadataFile: config.MetadataFile{VersionField: "module.version"}, Content: []byte("module:\n  version: 1.2.0\n")}, want: []string{"1.2.0"}},
+		{name: "missing field", file: MetadataFile{MetadataFile: config.MetadataFile{VersionField: "version"}, Content: []byte(`{"name": "ns8-mail"}`)}},
+		{name: "object field", file: MetadataFile{MetadataFile: config.MetadataFile{VersionField: "module"}, Content: []byte("module:\n  version: 1.2.0\n")}, wantErr: true},
+		{name: "invalid document", file: MetadataFile{MetadataFile: config.MetadataFile{VersionField: "version"}, Content: []byte(`{"version": `)}, wantErr: true},
+		{name: "pattern", file: MetadataFile{MetadataFile: config.MetadataFile{VersionPattern: `mail:(\S+)`}, Content: []byte("mail:1.2.0 mail:1.2.0 mail:1.1.0")}, want: []string{"1.2.0", "1.1.0"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.file.Versions()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Versions() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("Versions() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMetadataChecksCoverConfiguredNames(t *testing.T) {
+	for _, name := range config.MetadataChecks {
+		if metadataChecks[name] == nil {
+			t.Fatalf("no metadata check named %s", name)
+		}
+	}
+}
@@ -2,6 +2,8 @@ package main
 
 import (
 	"github.com/NethServer/gh-ns8/cmd"
+	_ "github.com/NethServer/gh-ns8/cmd/cache"
+	_ "github.com/NethServer/gh-ns8/cmd/config"
 	_ "github.com/NethServer/gh-ns8/cmd/module_release"
 )
 